@@ -13,16 +13,10 @@ package main
 
 import (
 	"context"
-	"encoding/json"
 	"flag"
-	"fmt"
 	"os"
-	"strings"
-	"syscall"
 
 	"github.com/crashappsec/ocular-default-integrations/pkg/crawlers"
-	"github.com/crashappsec/ocular-default-integrations/pkg/input"
-	"github.com/crashappsec/ocular/api/v1beta1"
 	"sigs.k8s.io/controller-runtime/pkg/log"
 	"sigs.k8s.io/controller-runtime/pkg/log/zap"
 )
@@ -47,70 +41,8 @@ func main() {
 
 	logger.Info("starting crawler")
 
-	searchName := os.Getenv(v1beta1.EnvVarSearchName)
-	if searchName == "" {
-		logger.Error(
-			fmt.Errorf("%s environment variable not set", v1beta1.EnvVarSearchName),
-			"no search specified",
-		)
+	if err := crawlers.Run(ctx, crawlers.All); err != nil {
+		logger.Error(err, "error running crawler")
 		os.Exit(1)
 	}
-
-	crawlerName := strings.TrimPrefix(os.Getenv(v1beta1.EnvVarCrawlerName), "ocular-defaults-")
-	if crawlerName == "" {
-		logger.Error(
-			fmt.Errorf("%s environment variable not set", v1beta1.EnvVarCrawlerName),
-			"no crawler specified",
-		)
-		os.Exit(1)
-	}
-
-	if crawlerOverride := os.Getenv("OCULAR_CRAWLER_NAME_OVERRIDE"); crawlerOverride != "" {
-		crawlerName = crawlerOverride
-	}
-
-	crawler, found := crawlers.All[crawlerName]
-	if !found {
-		logger.Error(fmt.Errorf("unknown crawler %s", crawlerName), "no valid crawler specified")
-		os.Exit(1)
-	}
-
-	params, err := input.ParseParamsFromEnv(crawler.Parameters)
-	if err != nil {
-		logger.Error(err, "unable to parse parameters from environment")
-		os.Exit(1)
-	}
-
-	fifo, err := os.OpenFile(os.Getenv(v1beta1.EnvVarPipelineFIFO), syscall.O_WRONLY, os.ModeNamedPipe)
-	if err != nil {
-		logger.Error(err, "unable to open pipeline FIFO")
-		os.Exit(1)
-	}
-	logger = logger.WithValues("crawler", crawler.Name, "params", params)
-	logger.Info("executing crawler")
-
-	var queue = make(chan v1beta1.Target)
-
-	go func() {
-		defer close(queue)
-		if err := crawler.Crawl(ctx, params, queue); err != nil {
-			logger.Error(err, "error running crawler")
-		}
-	}()
-
-	logger.Info("awaiting target discovery")
-	encoder := json.NewEncoder(fifo)
-	for {
-		target, ok := <-queue
-		if !ok {
-			logger.Info("queue closed, exiting")
-			break
-		}
-		err := encoder.Encode(&target)
-		if err != nil {
-			logger.Error(err, "unable to encode target JSON", "target", target)
-		}
-	}
-
-	logger.Info("search finished successfully")
 }
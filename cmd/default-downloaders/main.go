@@ -13,13 +13,9 @@ package main
 import (
 	"context"
 	"flag"
-	"fmt"
 	"os"
-	"strings"
 
 	"github.com/crashappsec/ocular-default-integrations/pkg/downloaders"
-	"github.com/crashappsec/ocular-default-integrations/pkg/input"
-	"github.com/crashappsec/ocular/api/v1beta1"
 	"sigs.k8s.io/controller-runtime/pkg/log"
 	"sigs.k8s.io/controller-runtime/pkg/log/zap"
 )
@@ -42,58 +38,8 @@ func main() {
 	log.SetLogger(logger)
 	ctx = log.IntoContext(ctx, logger)
 
-	targetDir := os.Getenv(v1beta1.EnvVarTargetDir)
-	targetIdentifier := os.Getenv(v1beta1.EnvVarTargetIdentifier)
-	targetVersion := os.Getenv(v1beta1.EnvVarTargetVersion)
-
-	downloaderName := strings.TrimPrefix(os.Getenv(v1beta1.EnvVarDownloaderName), "ocular-defaults-")
-	if downloaderName == "" {
-		logger.Error(
-			fmt.Errorf("%s environment variable not set", v1beta1.EnvVarDownloaderName),
-			"no downloader specified",
-		)
+	if err := downloaders.Run(ctx, downloaders.All); err != nil {
+		logger.Error(err, "error running downloader")
 		os.Exit(1)
 	}
-
-	if downloaderOverride := os.Getenv("OCULAR_DOWNLOADER_NAME_OVERRIDE"); downloaderOverride != "" {
-		downloaderName = downloaderOverride
-	}
-
-	l := logger.WithValues(
-		"target_dir", targetDir,
-		"downloader", downloaderName,
-		"target_identifier", targetIdentifier,
-		"target_version", targetVersion,
-	)
-
-	logger.Info("starting downloader")
-
-	logger.Info("creating target directory")
-	if err := os.MkdirAll(targetDir, 0o750); err != nil {
-		logger.Error(err, "error creating target directory")
-	}
-
-	downloader, found := downloaders.All[downloaderName]
-	if !found {
-		logger.Error(
-			fmt.Errorf("unknown downloader %s", downloaderName),
-			"no valid downloader specified",
-		)
-		os.Exit(1)
-	}
-
-	params, err := input.ParseParamsFromEnv(downloader.Parameters)
-	if err != nil {
-		logger.Error(err, "unable to parse parameters from environment")
-	}
-
-	l.Info("downloading target")
-
-	err = downloader.Download(ctx, params, targetIdentifier, targetVersion, targetDir)
-	if err != nil {
-		l.Error(err, "error downloading target")
-		os.Exit(1)
-	}
-
-	l.Info("downloaded target successfully")
 }
@@ -13,13 +13,9 @@ package main
 import (
 	"context"
 	"flag"
-	"fmt"
 	"os"
-	"strings"
 
-	"github.com/crashappsec/ocular-default-integrations/pkg/input"
 	"github.com/crashappsec/ocular-default-integrations/pkg/uploaders"
-	"github.com/crashappsec/ocular/api/v1beta1"
 	"sigs.k8s.io/controller-runtime/pkg/log"
 	"sigs.k8s.io/controller-runtime/pkg/log/zap"
 )
@@ -41,74 +37,8 @@ func main() {
 	log.SetLogger(logger)
 	ctx = log.IntoContext(ctx, logger)
 
-	logger.Info("starting uploader")
-
-	var files []string
-	for i, arg := range os.Args {
-		// files are passed as positional arguments after '--'
-		if arg == "--" {
-			files = os.Args[i+1:]
-			break
-		}
-	}
-	resultsDir := os.Getenv(v1beta1.EnvVarResultsDir)
-
-	uploaderName := strings.TrimPrefix(os.Getenv(v1beta1.EnvVarUploaderName), "ocular-defaults-")
-	if uploaderName == "" {
-		logger.Error(
-			fmt.Errorf("%s environment variable not set", v1beta1.EnvVarUploaderName),
-			"no uploader specified",
-		)
+	if err := uploaders.Run(ctx, uploaders.All, uploaders.FilesFromArgs(os.Args)); err != nil {
+		logger.Error(err, "error running uploader")
 		os.Exit(1)
 	}
-
-	if uploaderOverride := os.Getenv("OCULAR_UPLOADER_NAME_OVERRIDE"); uploaderOverride != "" {
-		uploaderName = uploaderOverride
-	}
-
-	l := logger.WithValues(
-		"results_dir", resultsDir,
-		"uploader", uploaderName,
-		"file-args", files,
-	)
-
-	metadata, err := input.ParseMetadataFromEnv()
-	if err != nil {
-		l.Error(err, "failed to parse metadata from environment")
-		os.Exit(1)
-	}
-
-	uploader, found := uploaders.All[uploaderName]
-	if !found {
-		l.Error(fmt.Errorf("unknown uploader %s", uploaderName), "no valid uploader specified")
-		os.Exit(1)
-	}
-
-	l.WithValues("uploader", uploaderName).Info("begin upload process")
-
-	params, err := input.ParseParamsFromEnv(uploader.Parameters)
-	if err != nil {
-		logger.Error(err, "unable to parse parameters from environment")
-	}
-
-	var validatedFiles []string
-	for _, file := range files {
-		l.Info("validating file exists", "file", file)
-		if _, err := os.Stat(file); os.IsNotExist(err) {
-			l.Info("file does not exist, skipping", "file", file)
-		} else if err != nil {
-			l.Error(err, "unable to stat file, skipping", "file", file)
-		} else {
-			validatedFiles = append(validatedFiles, file)
-		}
-	}
-
-	l.Info("uploading files", "files", validatedFiles)
-	err = uploader.Upload(ctx, metadata, params, validatedFiles)
-	if err != nil {
-		l.Error(err, "failed to upload files", "files", validatedFiles)
-		os.Exit(1)
-	}
-
-	l.Info("uploaded artifacts successfully")
 }
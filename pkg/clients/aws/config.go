@@ -15,7 +15,7 @@ import (
 
 	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/config"
-	"github.com/crashappsec/ocular-default-integrations/internal/definitions"
+	"github.com/crashappsec/ocular-default-integrations/pkg/definitions"
 	"github.com/crashappsec/ocular/api/v1beta1"
 	"k8s.io/utils/ptr"
 	"sigs.k8s.io/controller-runtime/pkg/log"
@@ -31,13 +31,14 @@ const (
 var Parameters = []v1beta1.ParameterDefinition{
 	{
 		Name:        RegionParamName,
-		Description: "AWS region of the ECR repository. Defaults to the region configured in the AWS SDK if empty.",
+		Description: "AWS region used for API calls. Defaults to the region configured in the AWS SDK if empty.",
 		Default:     ptr.To(""),
 	},
 	{
-		Name:        ProfileParamName,
-		Description: "ARN of the role to assume for accessing the ECR repository. Optional.",
-		Default:     ptr.To(""),
+		Name: ProfileParamName,
+		Description: "AWS profile used for API calls, e.g. to assume a role, " +
+			"read from the 'aws-config' secret if set. Optional.",
+		Default: ptr.To(""),
 	},
 }
 
@@ -75,6 +75,7 @@ type Tag struct {
 	Status              string    `json:"status"`
 	TagLastPulled       time.Time `json:"tag_last_pulled"`
 	TagLastPushed       time.Time `json:"tag_last_pushed"`
+	Digest              string    `json:"digest"`
 }
 
 func (c *client) ListRepositoryTags(ctx context.Context, namespace, repository string) ([]Tag, error) {
@@ -0,0 +1,62 @@
+// Copyright (C) 2025-2026 Crash Override, Inc.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the FSF, either version 3 of the License, or (at your option) any later version.
+// See the LICENSE file in the root of this repository for full license text or
+// visit: <https://www.gnu.org/licenses/gpl-3.0.html>.
+
+package gcp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/crashappsec/ocular-default-integrations/pkg/definitions"
+	"google.golang.org/api/option"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+const (
+	CredentialsFileMountPath = "/ocular/gcp/credentials.json"
+)
+
+var FileSecrets = []definitions.FileSecret{
+	{
+		SecretKey: "gcp-credentials",
+		MountPath: CredentialsFileMountPath,
+	},
+}
+
+// ClientOptions returns the options used to authenticate Google Cloud clients.
+// If the 'gcp-credentials' secret is mounted, the credentials file is used,
+// otherwise the client falls back to Application Default Credentials.
+func ClientOptions(ctx context.Context) ([]option.ClientOption, error) {
+	l := log.FromContext(ctx)
+	f, err := os.Stat(CredentialsFileMountPath)
+	if err != nil || f.IsDir() {
+		l.Info("no GCP credentials file mounted, using application default credentials")
+		return nil, nil
+	}
+
+	content, err := os.ReadFile(CredentialsFileMountPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read GCP credentials file: %w", err)
+	}
+
+	var credentials struct {
+		Type string `json:"type"`
+	}
+	if err = json.Unmarshal(content, &credentials); err != nil {
+		return nil, fmt.Errorf("failed to parse GCP credentials file: %w", err)
+	}
+	if credentials.Type == "" {
+		return nil, fmt.Errorf("GCP credentials file is missing the 'type' field")
+	}
+
+	return []option.ClientOption{
+		option.WithAuthCredentialsJSON(option.CredentialsType(credentials.Type), content),
+	}, nil
+}
@@ -0,0 +1,90 @@
+// Copyright (C) 2025-2026 Crash Override, Inc.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the FSF, either version 3 of the License, or (at your option) any later version.
+// See the LICENSE file in the root of this repository for full license text or
+// visit: <https://www.gnu.org/licenses/gpl-3.0.html>.
+
+package npm
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+type Client interface {
+	SearchPackages(ctx context.Context, text string) ([]SearchPackage, error)
+	GetPackument(ctx context.Context, packageName string) (Packument, error)
+}
+
+type client struct {
+	registryURL string
+	authToken   string
+	httpClient  *http.Client
+}
+
+type Options struct {
+	// RegistryURL is the base URL of the registry to query.
+	// Defaults to [DefaultRegistryURL] if empty.
+	RegistryURL string
+	// AuthToken is sent as a bearer token on every request if set.
+	AuthToken string
+}
+
+const DefaultRegistryURL = "https://registry.npmjs.org"
+
+func NewClient(options Options) Client {
+	c := &client{
+		registryURL: DefaultRegistryURL,
+		httpClient:  http.DefaultClient,
+	}
+	if options.RegistryURL != "" {
+		c.registryURL = strings.TrimSuffix(options.RegistryURL, "/")
+	}
+	if options.AuthToken != "" {
+		c.authToken = options.AuthToken
+	}
+	return c
+}
+
+func (c *client) buildURL(path string, queryParams map[string]string) string {
+	u := c.registryURL + path
+	if len(queryParams) == 0 {
+		return u
+	}
+	q := url.Values{}
+	for k, v := range queryParams {
+		q.Set(k, v)
+	}
+	return u + "?" + q.Encode()
+}
+
+func makeGetRequest[Result any](ctx context.Context, c *client, u string) (Result, error) {
+	var result Result
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return result, fmt.Errorf("error creating request: %w", err)
+	}
+	req.Header.Set("Accept", "application/json")
+	if c.authToken != "" {
+		req.Header.Set("Authorization", "Bearer "+c.authToken)
+	}
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return result, fmt.Errorf("error making request: %w", err)
+	}
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return result, fmt.Errorf("received non-2xx response: %d", resp.StatusCode)
+	}
+
+	err = json.NewDecoder(resp.Body).Decode(&result)
+	return result, err
+}
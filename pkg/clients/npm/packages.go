@@ -0,0 +1,101 @@
+// Copyright (C) 2025-2026 Crash Override, Inc.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the FSF, either version 3 of the License, or (at your option) any later version.
+// See the LICENSE file in the root of this repository for full license text or
+// visit: <https://www.gnu.org/licenses/gpl-3.0.html>.
+
+package npm
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+type SearchPackage struct {
+	Name        string       `json:"name"`
+	Scope       string       `json:"scope"`
+	Version     string       `json:"version"`
+	Date        time.Time    `json:"date"`
+	Publisher   Maintainer   `json:"publisher"`
+	Maintainers []Maintainer `json:"maintainers"`
+}
+
+type Maintainer struct {
+	Username string `json:"username"`
+	Email    string `json:"email,omitempty"`
+}
+
+type searchResponse struct {
+	Objects []struct {
+		Package SearchPackage `json:"package"`
+	} `json:"objects"`
+	Total int `json:"total"`
+}
+
+// searchPageSize is the maximum page size allowed by the registry search API.
+const searchPageSize = 250
+
+// SearchPackages queries the registry search API (/-/v1/search) with the given
+// text, which can contain qualifiers such as "scope:acme" or "maintainer:someone",
+// and returns every page of results.
+func (c *client) SearchPackages(ctx context.Context, text string) ([]SearchPackage, error) {
+	var packages []SearchPackage
+	for from := 0; ; {
+		u := c.buildURL("/-/v1/search", map[string]string{
+			"text": text,
+			"size": strconv.Itoa(searchPageSize),
+			"from": strconv.Itoa(from),
+		})
+		result, err := makeGetRequest[searchResponse](ctx, c, u)
+		if err != nil {
+			return nil, fmt.Errorf("error making request to endpoint '%s': %w", u, err)
+		}
+		for _, obj := range result.Objects {
+			packages = append(packages, obj.Package)
+		}
+		from += len(result.Objects)
+		if len(result.Objects) == 0 || from >= result.Total {
+			break
+		}
+	}
+	return packages, nil
+}
+
+// Packument is the full package document returned by the registry
+// for a single package. Only the fields used by Ocular are decoded.
+type Packument struct {
+	Name     string            `json:"name"`
+	DistTags map[string]string `json:"dist-tags"`
+	// Time maps each version to its publish time. It also contains
+	// the special keys "created", "modified" and possibly "unpublished",
+	// which is why values are left undecoded. Use [Packument.PublishTime].
+	Time     map[string]json.RawMessage `json:"time"`
+	Versions map[string]struct {
+		Deprecated string `json:"deprecated,omitempty"`
+	} `json:"versions"`
+}
+
+// PublishTime returns the time the given version was published, if known.
+func (p Packument) PublishTime(version string) (time.Time, bool) {
+	raw, ok := p.Time[version]
+	if !ok {
+		return time.Time{}, false
+	}
+	var t time.Time
+	if err := json.Unmarshal(raw, &t); err != nil {
+		return time.Time{}, false
+	}
+	return t, true
+}
+
+func (c *client) GetPackument(ctx context.Context, packageName string) (Packument, error) {
+	// scoped packages must have the separating slash escaped, i.e. @scope%2Fname
+	u := c.buildURL("/"+url.PathEscape(packageName), nil)
+	return makeGetRequest[Packument](ctx, c, u)
+}
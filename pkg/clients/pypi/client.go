@@ -0,0 +1,98 @@
+// Copyright (C) 2025-2026 Crash Override, Inc.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the FSF, either version 3 of the License, or (at your option) any later version.
+// See the LICENSE file in the root of this repository for full license text or
+// visit: <https://www.gnu.org/licenses/gpl-3.0.html>.
+
+package pypi
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+type Client interface {
+	ListIndexProjects(ctx context.Context) ([]string, error)
+	ListProjectReleases(ctx context.Context, project string) ([]Release, error)
+	ListUserProjects(ctx context.Context, username string) ([]string, error)
+	ListOrganizationProjects(ctx context.Context, organization string) ([]string, error)
+}
+
+type client struct {
+	indexURL   string
+	username   string
+	password   string
+	httpClient *http.Client
+}
+
+type Options struct {
+	// IndexURL is the base URL of a package index implementing the
+	// PEP 691 JSON simple API. Defaults to [DefaultIndexURL] if empty.
+	IndexURL string
+	// Username and Password are sent as basic auth credentials to the index if set.
+	// For token authentication, the username is usually '__token__'.
+	Username string
+	Password string
+}
+
+const (
+	DefaultIndexURL = "https://pypi.org/simple"
+	// WebURL is the base URL of the PyPI website, used to look up
+	// the projects of users and organizations.
+	WebURL = "https://pypi.org"
+
+	simpleJSONContentType = "application/vnd.pypi.simple.v1+json"
+)
+
+func NewClient(options Options) Client {
+	c := &client{
+		indexURL:   DefaultIndexURL,
+		httpClient: http.DefaultClient,
+	}
+	if options.IndexURL != "" {
+		c.indexURL = strings.TrimSuffix(options.IndexURL, "/")
+	}
+	c.username = options.Username
+	c.password = options.Password
+	return c
+}
+
+func (c *client) get(ctx context.Context, u, accept string, withAuth bool) (io.ReadCloser, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return nil, fmt.Errorf("error creating request: %w", err)
+	}
+	req.Header.Set("Accept", accept)
+	if withAuth && (c.username != "" || c.password != "") {
+		req.SetBasicAuth(c.username, c.password)
+	}
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("error making request: %w", err)
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		_ = resp.Body.Close()
+		return nil, fmt.Errorf("received non-2xx response from '%s': %d", u, resp.StatusCode)
+	}
+	return resp.Body, nil
+}
+
+func makeIndexRequest[Result any](ctx context.Context, c *client, path string) (Result, error) {
+	var result Result
+	body, err := c.get(ctx, c.indexURL+path, simpleJSONContentType, true)
+	if err != nil {
+		return result, err
+	}
+	defer func() {
+		_ = body.Close()
+	}()
+
+	err = json.NewDecoder(body).Decode(&result)
+	return result, err
+}
@@ -0,0 +1,223 @@
+// Copyright (C) 2025-2026 Crash Override, Inc.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the FSF, either version 3 of the License, or (at your option) any later version.
+// See the LICENSE file in the root of this repository for full license text or
+// visit: <https://www.gnu.org/licenses/gpl-3.0.html>.
+
+package pypi
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"maps"
+	"net/url"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+)
+
+type indexResponse struct {
+	Projects []struct {
+		Name string `json:"name"`
+	} `json:"projects"`
+}
+
+// ListIndexProjects lists every project in the index. This should
+// not be used against PyPI itself, since it contains every public project.
+func (c *client) ListIndexProjects(ctx context.Context) ([]string, error) {
+	result, err := makeIndexRequest[indexResponse](ctx, c, "/")
+	if err != nil {
+		return nil, fmt.Errorf("error listing index projects: %w", err)
+	}
+	projects := make([]string, 0, len(result.Projects))
+	for _, p := range result.Projects {
+		projects = append(projects, p.Name)
+	}
+	return projects, nil
+}
+
+type File struct {
+	Filename   string          `json:"filename"`
+	URL        string          `json:"url"`
+	UploadTime time.Time       `json:"upload-time"`
+	Yanked     json.RawMessage `json:"yanked,omitempty"`
+}
+
+// IsYanked reports whether the file has been yanked. Per PEP 592
+// the value is either a boolean or a string with the reason.
+func (f File) IsYanked() bool {
+	var yanked any
+	if len(f.Yanked) == 0 || json.Unmarshal(f.Yanked, &yanked) != nil {
+		return false
+	}
+	switch v := yanked.(type) {
+	case bool:
+		return v
+	case string:
+		return true
+	default:
+		return false
+	}
+}
+
+type projectResponse struct {
+	Name     string   `json:"name"`
+	Files    []File   `json:"files"`
+	Versions []string `json:"versions"`
+}
+
+// Release is a single version of a project.
+type Release struct {
+	Version string
+	// UploadTime is the time of the earliest file upload for the release.
+	UploadTime time.Time
+	// Yanked is true if every file of the release has been yanked.
+	Yanked bool
+}
+
+// ListProjectReleases lists the releases of a project using the PEP 691 project page.
+// Releases are grouped from the files of the project, since older indexes
+// do not return the "versions" key (PEP 700).
+func (c *client) ListProjectReleases(ctx context.Context, project string) ([]Release, error) {
+	result, err := makeIndexRequest[projectResponse](ctx, c, "/"+NormalizeName(project)+"/")
+	if err != nil {
+		return nil, fmt.Errorf("error retrieving project %s: %w", project, err)
+	}
+
+	releases := make(map[string]*Release)
+	fileCounts := make(map[string]int)
+	yankedCounts := make(map[string]int)
+	for _, v := range result.Versions {
+		releases[v] = &Release{Version: v}
+	}
+
+	for _, f := range result.Files {
+		version := versionFromFilename(f.Filename, result.Versions)
+		if version == "" {
+			continue
+		}
+		r, ok := releases[version]
+		if !ok {
+			r = &Release{Version: version}
+			releases[version] = r
+		}
+		if r.UploadTime.IsZero() || (!f.UploadTime.IsZero() && f.UploadTime.Before(r.UploadTime)) {
+			r.UploadTime = f.UploadTime
+		}
+		fileCounts[version]++
+		if f.IsYanked() {
+			yankedCounts[version]++
+		}
+	}
+
+	for version, r := range releases {
+		// a release is only considered yanked when all of its files are
+		r.Yanked = fileCounts[version] > 0 && fileCounts[version] == yankedCounts[version]
+	}
+
+	// releases are listed in order of version, so that their order does not depend on the map
+	list := make([]Release, 0, len(releases))
+	for _, version := range slices.SortedFunc(maps.Keys(releases), CompareVersions) {
+		list = append(list, *releases[version])
+	}
+	return list, nil
+}
+
+var distributionExtensions = []string{".tar.gz", ".tar.bz2", ".tgz", ".zip", ".whl", ".egg"}
+
+// versionFromFilename extracts the version from a distribution filename.
+// If the known versions of the project are given, the longest version
+// matching the filename is used, since sdist names may contain dashes.
+func versionFromFilename(filename string, versions []string) string {
+	stem := filename
+	ext := ""
+	for _, e := range distributionExtensions {
+		if strings.HasSuffix(filename, e) {
+			stem = strings.TrimSuffix(filename, e)
+			ext = e
+			break
+		}
+	}
+	if ext == "" {
+		return ""
+	}
+
+	if ext == ".whl" || ext == ".egg" {
+		// {distribution}-{version}(-{build tag})?-{python tag}-{abi tag}-{platform tag}.whl
+		// {distribution}-{version}-{python tag}.egg
+		parts := strings.Split(stem, "-")
+		if len(parts) < 2 {
+			return ""
+		}
+		return parts[1]
+	}
+
+	var best string
+	for _, v := range versions {
+		if strings.HasSuffix(stem, "-"+v) && len(v) > len(best) {
+			best = v
+		}
+	}
+	if best != "" {
+		return best
+	}
+	if i := strings.LastIndex(stem, "-"); i >= 0 {
+		return stem[i+1:]
+	}
+	return ""
+}
+
+var nameNormalizer = regexp.MustCompile(`[-_.]+`)
+
+// NormalizeName normalizes a project name as described in PEP 503.
+func NormalizeName(name string) string {
+	return strings.ToLower(nameNormalizer.ReplaceAllString(name, "-"))
+}
+
+// projectLinkRegex matches links to project pages from the PyPI
+// user and organization profile pages.
+var projectLinkRegex = regexp.MustCompile(`href="/project/([^/"]+)/"`)
+
+// ListUserProjects lists the projects a PyPI user is a collaborator on.
+// PyPI does not offer an API for this, so the public profile page is used.
+func (c *client) ListUserProjects(ctx context.Context, username string) ([]string, error) {
+	return c.listProfileProjects(ctx, "/user/"+url.PathEscape(username)+"/")
+}
+
+// ListOrganizationProjects lists the projects owned by a PyPI organization.
+// PyPI does not offer an API for this, so the public profile page is used.
+func (c *client) ListOrganizationProjects(ctx context.Context, organization string) ([]string, error) {
+	return c.listProfileProjects(ctx, "/org/"+url.PathEscape(organization)+"/")
+}
+
+func (c *client) listProfileProjects(ctx context.Context, path string) ([]string, error) {
+	body, err := c.get(ctx, WebURL+path, "text/html", false)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = body.Close()
+	}()
+
+	page, err := io.ReadAll(body)
+	if err != nil {
+		return nil, fmt.Errorf("error reading profile page: %w", err)
+	}
+
+	var projects []string
+	for _, match := range projectLinkRegex.FindAllSubmatch(page, -1) {
+		project, err := url.PathUnescape(string(match[1]))
+		if err != nil {
+			continue
+		}
+		if !slices.Contains(projects, project) {
+			projects = append(projects, project)
+		}
+	}
+	return projects, nil
+}
@@ -0,0 +1,160 @@
+// Copyright (C) 2025-2026 Crash Override, Inc.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the FSF, either version 3 of the License, or (at your option) any later version.
+// See the LICENSE file in the root of this repository for full license text or
+// visit: <https://www.gnu.org/licenses/gpl-3.0.html>.
+
+package pypi
+
+import (
+	"cmp"
+	"math"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// versionRegex matches a PEP 440 version, including the non-normalized
+// forms that are accepted (https://packaging.python.org/en/latest/specifications/version-specifiers/).
+var versionRegex = regexp.MustCompile(`^v?` +
+	`(?:(?P<epoch>[0-9]+)!)?` +
+	`(?P<release>[0-9]+(?:\.[0-9]+)*)` +
+	`(?:[-_.]?(?P<pre_l>alpha|beta|preview|pre|a|b|c|rc)[-_.]?(?P<pre_n>[0-9]+)?)?` +
+	`(?:-(?P<post_n1>[0-9]+)|[-_.]?(?P<post_l>post|rev|r)[-_.]?(?P<post_n2>[0-9]+)?)?` +
+	`(?:[-_.]?(?P<dev_l>dev)[-_.]?(?P<dev_n>[0-9]+)?)?` +
+	`(?:\+(?P<local>[a-z0-9]+(?:[-_.][a-z0-9]+)*))?$`)
+
+// version is a parsed PEP 440 version, with the components used for ordering.
+type version struct {
+	epoch   int
+	release []int
+	// pre is the rank of the pre-release phase (a, b or rc) and its number.
+	// Releases without a pre-release have the maximum rank, and development
+	// releases of them the minimum rank, so that they sort before pre-releases.
+	pre  [2]int
+	post int
+	dev  int
+	// local is the local version label, compared segment by segment.
+	local []string
+}
+
+var preReleaseRanks = map[string]int{
+	"a": 0, "alpha": 0,
+	"b": 1, "beta": 1,
+	"c": 2, "rc": 2, "pre": 2, "preview": 2,
+}
+
+// parseVersion parses a PEP 440 version, reporting whether it is valid.
+func parseVersion(s string) (version, bool) {
+	match := versionRegex.FindStringSubmatch(strings.ToLower(strings.TrimSpace(s)))
+	if match == nil {
+		return version{}, false
+	}
+	group := func(name string) string {
+		return match[versionRegex.SubexpIndex(name)]
+	}
+	number := func(value string) (int, bool) {
+		if value == "" {
+			return 0, true
+		}
+		n, err := strconv.Atoi(value)
+		return n, err == nil
+	}
+
+	var (
+		v  version
+		ok bool
+	)
+	v.epoch, ok = number(group("epoch"))
+	for _, part := range strings.Split(group("release"), ".") {
+		n, valid := number(part)
+		ok = ok && valid
+		v.release = append(v.release, n)
+	}
+	// trailing zeros are not significant (e.g. '1.0' and '1.0.0' are equal)
+	for len(v.release) > 1 && v.release[len(v.release)-1] == 0 {
+		v.release = v.release[:len(v.release)-1]
+	}
+
+	hasPost := group("post_n1") != "" || group("post_l") != ""
+	hasDev := group("dev_l") != ""
+	switch {
+	case group("pre_l") != "":
+		n, valid := number(group("pre_n"))
+		ok = ok && valid
+		v.pre = [2]int{preReleaseRanks[group("pre_l")], n}
+	case hasDev && !hasPost:
+		v.pre = [2]int{math.MinInt, 0}
+	default:
+		v.pre = [2]int{math.MaxInt, 0}
+	}
+
+	v.post = math.MinInt
+	if hasPost {
+		n, valid := number(group("post_n1") + group("post_n2"))
+		ok = ok && valid
+		v.post = n
+	}
+	v.dev = math.MaxInt
+	if hasDev {
+		n, valid := number(group("dev_n"))
+		ok = ok && valid
+		v.dev = n
+	}
+	if local := group("local"); local != "" {
+		v.local = strings.FieldsFunc(local, func(r rune) bool {
+			return r == '-' || r == '_' || r == '.'
+		})
+	}
+	return v, ok
+}
+
+func (v version) compare(other version) int {
+	return cmp.Or(
+		cmp.Compare(v.epoch, other.epoch),
+		slices.Compare(v.release, other.release),
+		slices.Compare(v.pre[:], other.pre[:]),
+		cmp.Compare(v.post, other.post),
+		cmp.Compare(v.dev, other.dev),
+		slices.CompareFunc(v.local, other.local, compareLocalSegments),
+	)
+}
+
+// compareLocalSegments compares segments of local version labels, where
+// numeric segments are compared as numbers and sort after alphanumeric ones.
+func compareLocalSegments(a, b string) int {
+	aNum, aErr := strconv.Atoi(a)
+	bNum, bErr := strconv.Atoi(b)
+	switch {
+	case aErr == nil && bErr == nil:
+		return cmp.Compare(aNum, bNum)
+	case aErr == nil:
+		return 1
+	case bErr == nil:
+		return -1
+	default:
+		return strings.Compare(a, b)
+	}
+}
+
+// CompareVersions returns -1, 0 or 1 if version a is lower, equal to or higher
+// than b, following the ordering of PEP 440. Invalid versions are lower than valid
+// ones, and versions that are equal by PEP 440 (e.g. '1.0' and '1.0.0') are
+// compared as strings, so that the order is always the same.
+func CompareVersions(a, b string) int {
+	va, aOK := parseVersion(a)
+	vb, bOK := parseVersion(b)
+	switch {
+	case aOK && bOK:
+		return cmp.Or(va.compare(vb), strings.Compare(a, b))
+	case aOK:
+		return 1
+	case bOK:
+		return -1
+	default:
+		return strings.Compare(a, b)
+	}
+}
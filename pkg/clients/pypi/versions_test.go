@@ -0,0 +1,78 @@
+// Copyright (C) 2025-2026 Crash Override, Inc.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the FSF, either version 3 of the License, or (at your option) any later version.
+// See the LICENSE file in the root of this repository for full license text or
+// visit: <https://www.gnu.org/licenses/gpl-3.0.html>.
+
+package pypi
+
+import "testing"
+
+func TestCompareVersions(t *testing.T) {
+	// versions in increasing order of precedence, following PEP 440
+	ordered := []string{
+		"not a version",
+		"0.1",
+		"0.9",
+		"1.0.dev0",
+		"1.0a1",
+		"1.0a2.dev1",
+		"1.0a2",
+		"1.0b1",
+		"1.0rc1",
+		"1.0",
+		"1.0+local.1",
+		"1.0+local.2",
+		"1.0.post1.dev0",
+		"1.0.post1",
+		"1.0.1",
+		"1.2",
+		"1.10",
+		"2.0",
+		"2024.1.15",
+		"1!0.1",
+	}
+
+	for i, a := range ordered {
+		for j, b := range ordered {
+			want := 0
+			switch {
+			case i < j:
+				want = -1
+			case i > j:
+				want = 1
+			}
+			if got := CompareVersions(a, b); got != want {
+				t.Errorf("CompareVersions(%q, %q) = %d, want %d", a, b, got, want)
+			}
+		}
+	}
+}
+
+func TestCompareVersionsEquivalent(t *testing.T) {
+	tests := []struct {
+		a, b string
+	}{
+		{a: "1.0", b: "1.0.0"},
+		{a: "1.0alpha1", b: "1.0a1"},
+		{a: "1.0-rc.1", b: "1.0rc1"},
+		{a: "1.0-1", b: "1.0.post1"},
+		{a: "v1.0", b: "1.0"},
+		{a: "1.0.DEV1", b: "1.0.dev1"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.a+"="+tt.b, func(t *testing.T) {
+			va, aOK := parseVersion(tt.a)
+			vb, bOK := parseVersion(tt.b)
+			if !aOK || !bOK {
+				t.Fatalf("expected valid versions, got %t and %t", aOK, bOK)
+			}
+			if got := va.compare(vb); got != 0 {
+				t.Errorf("compare(%q, %q) = %d, want 0", tt.a, tt.b, got)
+			}
+		})
+	}
+}
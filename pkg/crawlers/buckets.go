@@ -0,0 +1,116 @@
+// Copyright (C) 2025-2026 Crash Override, Inc.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the FSF, either version 3 of the License, or (at your option) any later version.
+// See the LICENSE file in the root of this repository for full license text or
+// visit: <https://www.gnu.org/licenses/gpl-3.0.html>.
+
+package crawlers
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/crashappsec/ocular/api/v1beta1"
+	"k8s.io/utils/ptr"
+)
+
+const (
+	BucketNamePatternParamName = "BUCKET_NAME_PATTERN"
+	SplitByPrefixParamName     = "SPLIT_BY_PREFIX"
+)
+
+var bucketParameters = []v1beta1.ParameterDefinition{
+	{
+		Name:        BucketNamePatternParamName,
+		Description: "Regular expression that bucket names must match to be crawled. All buckets are crawled if empty.",
+		Default:     ptr.To(""),
+	},
+	{
+		Name: SplitByPrefixParamName,
+		Description: "If true, a target is emitted for each top-level prefix of a bucket (e.g. 'bucket/prefix/') " +
+			"instead of one target for the whole bucket, keeping large buckets to a manageable size per pipeline. " +
+			"Objects outside of any prefix are emitted as a target each, which only downloads that object.",
+		Default: ptr.To("false"),
+	},
+}
+
+// bucketFilter selects which buckets are crawled by name and by their tags or labels.
+type bucketFilter struct {
+	namePattern *regexp.Regexp
+	tags        []bucketTagFilter
+}
+
+type bucketTagFilter struct {
+	key, value string
+	// anyValue is set for filters without a value, which
+	// only require the key to be present.
+	anyValue bool
+}
+
+// newBucketFilter parses the name pattern and a comma-separated list of tag filters,
+// each either 'key=value' to match an exact value or 'key' to match any value.
+func newBucketFilter(namePattern, tagFilters string) (*bucketFilter, error) {
+	f := &bucketFilter{}
+	if namePattern = strings.TrimSpace(namePattern); namePattern != "" {
+		re, err := regexp.Compile(namePattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid value for %s: %w", BucketNamePatternParamName, err)
+		}
+		f.namePattern = re
+	}
+
+	for _, tag := range splitParamList(tagFilters) {
+		key, value, found := strings.Cut(tag, "=")
+		f.tags = append(f.tags, bucketTagFilter{
+			key:      strings.TrimSpace(key),
+			value:    strings.TrimSpace(value),
+			anyValue: !found,
+		})
+	}
+	return f, nil
+}
+
+func (f *bucketFilter) matchesName(name string) bool {
+	return f.namePattern == nil || f.namePattern.MatchString(name)
+}
+
+// filtersTags reports whether any tag filters are set, so that
+// retrieving the tags of each bucket can be skipped if not.
+func (f *bucketFilter) filtersTags() bool {
+	return len(f.tags) > 0
+}
+
+func (f *bucketFilter) matchesTags(tags map[string]string) bool {
+	for _, filter := range f.tags {
+		value, ok := tags[filter.key]
+		if !ok || (!filter.anyValue && value != filter.value) {
+			return false
+		}
+	}
+	return true
+}
+
+// bucketPrefixTargets returns the targets for a bucket split by its top-level
+// prefixes and objects. If the bucket is empty, the whole bucket is returned.
+// Prefix targets end with '/', so that a prefix does not match others starting
+// with its name (e.g. 'logs/' and 'logs-old/'), and the downloaders only download
+// the object named by an object target, not the objects starting with its name.
+func bucketPrefixTargets(bucket string, prefixes, objects []string) []v1beta1.Target {
+	if len(prefixes) == 0 && len(objects) == 0 {
+		return []v1beta1.Target{{Identifier: bucket}}
+	}
+	targets := make([]v1beta1.Target, 0, len(prefixes)+len(objects))
+	for _, prefix := range prefixes {
+		if !strings.HasSuffix(prefix, "/") {
+			prefix += "/"
+		}
+		targets = append(targets, v1beta1.Target{Identifier: bucket + "/" + prefix})
+	}
+	for _, object := range objects {
+		targets = append(targets, v1beta1.Target{Identifier: bucket + "/" + object})
+	}
+	return targets
+}
@@ -0,0 +1,57 @@
+// Copyright (C) 2025-2026 Crash Override, Inc.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the FSF, either version 3 of the License, or (at your option) any later version.
+// See the LICENSE file in the root of this repository for full license text or
+// visit: <https://www.gnu.org/licenses/gpl-3.0.html>.
+
+package crawlers
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestBucketPrefixTargets(t *testing.T) {
+	tests := []struct {
+		name     string
+		prefixes []string
+		objects  []string
+		want     []string
+	}{
+		{
+			name: "empty bucket",
+			want: []string{"bucket"},
+		},
+		{
+			name:     "prefixes and objects",
+			prefixes: []string{"logs/", "data/"},
+			objects:  []string{"README"},
+			want:     []string{"bucket/logs/", "bucket/data/", "bucket/README"},
+		},
+		{
+			name:     "prefixes without delimiter",
+			prefixes: []string{"logs", "logs-old/"},
+			want:     []string{"bucket/logs/", "bucket/logs-old/"},
+		},
+		{
+			name:     "overlapping names",
+			prefixes: []string{"a/", "abc/"},
+			objects:  []string{"a", "a.txt"},
+			want:     []string{"bucket/a/", "bucket/abc/", "bucket/a", "bucket/a.txt"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			for _, target := range bucketPrefixTargets("bucket", tt.prefixes, tt.objects) {
+				got = append(got, target.Identifier)
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
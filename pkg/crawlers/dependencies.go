@@ -0,0 +1,313 @@
+// Copyright (C) 2025-2026 Crash Override, Inc.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the FSF, either version 3 of the License, or (at your option) any later version.
+// See the LICENSE file in the root of this repository for full license text or
+// visit: <https://www.gnu.org/licenses/gpl-3.0.html>.
+
+package crawlers
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path"
+	"slices"
+	"strings"
+
+	s3Service "github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/crashappsec/ocular-default-integrations/pkg/clients/aws"
+	"github.com/crashappsec/ocular-default-integrations/pkg/definitions"
+	"github.com/crashappsec/ocular/api/v1beta1"
+	gogit "github.com/go-git/go-git/v6"
+	"github.com/go-git/go-git/v6/plumbing"
+	"github.com/go-git/go-git/v6/plumbing/client"
+	githttp "github.com/go-git/go-git/v6/plumbing/transport/http"
+	"github.com/go-git/go-git/v6/storage/memory"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+const (
+	DependencySourceParamName    = "DEPENDENCY_SOURCE"
+	DependencyFormatParamName    = "DEPENDENCY_FORMAT"
+	DependencyEcosystemParamName = "DEPENDENCY_ECOSYSTEM"
+	DependencyExcludeParamName   = "EXCLUDE_PACKAGES"
+
+	DependencySourceTokenSecretEnvVar = "DEPENDENCY_SOURCE_TOKEN"
+
+	// MaxDependencyFileSize is the maximum size of a lockfile or SBOM read by the crawler.
+	MaxDependencyFileSize = 64 << 20
+)
+
+func init() {
+	All.Register(Dependencies)
+}
+
+var Dependencies = Crawler{
+	Name:        "dependencies",
+	FileSecrets: aws.FileSecrets,
+	EnvironmentSecrets: []definitions.EnvironmentSecret{
+		{
+			SecretKey:  "dependency-source-token",
+			EnvVarName: DependencySourceTokenSecretEnvVar,
+		},
+	},
+	Parameters: append(slices.Clone(aws.Parameters),
+		v1beta1.ParameterDefinition{
+			Name: DependencySourceParamName,
+			Description: "Location of the lockfile or SBOM to crawl. Can be an HTTP(S) URL, an S3 object " +
+				"('s3://bucket/key') or a file in a git repository ('git+<clone URL>//<path>[#<ref>]'). " +
+				"The token in the 'dependency-source-token' secret is used to authenticate HTTP(S) and git requests if set.",
+		},
+		v1beta1.ParameterDefinition{
+			Name: DependencyFormatParamName,
+			Description: "Format of the dependency file, one of 'auto', 'package-lock', 'yarn-lock', 'poetry-lock', " +
+				"'requirements', 'cyclonedx' or 'spdx'. SBOMs must be JSON encoded. " +
+				"When 'auto', the format is detected from the file name or content.",
+			Default: ptr.To(DependencyFormatAuto),
+		},
+		v1beta1.ParameterDefinition{
+			Name: DependencyEcosystemParamName,
+			Description: "Ecosystem of the dependencies to emit, one of 'npm', 'pypi' or 'docker'. " +
+				"This should match the downloader of the pipeline template, since each search " +
+				"can only use a single downloader. Run one search per ecosystem to cover a mixed SBOM.",
+		},
+		v1beta1.ParameterDefinition{
+			Name: DependencyExcludeParamName,
+			Description: "Comma-separated list of glob patterns (e.g. '@acme/*,acme-*') matched against package names. " +
+				"Matching packages are not emitted, which can be used to exclude internal packages.",
+			Default: ptr.To(""),
+		},
+	),
+	Crawl: crawlDependencies,
+}
+
+// crawlDependencies reads a lockfile or SBOM and sends one target for each
+// unique package and version of the configured ecosystem to the queue.
+func crawlDependencies(ctx context.Context, params map[string]string, queue chan v1beta1.Target) error {
+	l := log.FromContext(ctx).WithValues("crawler", "dependencies")
+
+	source := strings.TrimSpace(params[DependencySourceParamName])
+	format := strings.ToLower(strings.TrimSpace(params[DependencyFormatParamName]))
+	ecosystem := strings.ToLower(strings.TrimSpace(params[DependencyEcosystemParamName]))
+	excludes := splitParamList(params[DependencyExcludeParamName])
+
+	if source == "" {
+		return fmt.Errorf("no dependency source specified")
+	}
+	switch ecosystem {
+	case DependencyEcosystemNPM, DependencyEcosystemPyPI, DependencyEcosystemDocker:
+	default:
+		return fmt.Errorf("unsupported dependency ecosystem %q", ecosystem)
+	}
+	for _, pattern := range excludes {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return fmt.Errorf("invalid exclude pattern %q: %w", pattern, err)
+		}
+	}
+
+	l.Info("fetching dependency source", "source", source)
+	filename, content, err := fetchDependencySource(ctx, params, source)
+	if err != nil {
+		return fmt.Errorf("error fetching dependency source %s: %w", source, err)
+	}
+
+	if format == "" || format == DependencyFormatAuto {
+		format, err = detectDependencyFormat(filename, content)
+		if err != nil {
+			return err
+		}
+		l.Info("detected dependency file format", "format", format)
+	}
+	parser, ok := dependencyParsers[format]
+	if !ok {
+		return fmt.Errorf("unsupported dependency file format %q", format)
+	}
+
+	deps, err := parser(content)
+	if err != nil {
+		return err
+	}
+
+	seen := make(map[dependency]struct{}, len(deps))
+	for _, dep := range deps {
+		if dep.Ecosystem != ecosystem {
+			continue
+		}
+		if _, ok := seen[dep]; ok {
+			continue
+		}
+		seen[dep] = struct{}{}
+
+		if slices.ContainsFunc(excludes, func(pattern string) bool {
+			matched, _ := path.Match(pattern, dep.Name)
+			return matched
+		}) {
+			l.Info("skipping excluded package", "package", dep.Name, "version", dep.Version)
+			continue
+		}
+
+		l.Info("queuing target", "package", dep.Name, "version", dep.Version)
+		queue <- v1beta1.Target{
+			Identifier: dep.Name,
+			Version:    dep.Version,
+		}
+	}
+	l.Info(fmt.Sprintf("parsed %d dependencies", len(deps)), "dependencies", len(deps), "ecosystem", ecosystem)
+
+	return nil
+}
+
+// fetchDependencySource retrieves the contents of a dependency file, returning
+// the name of the file (used for format detection) and its contents.
+func fetchDependencySource(ctx context.Context, params map[string]string, source string) (string, []byte, error) {
+	token := os.Getenv(DependencySourceTokenSecretEnvVar)
+	switch {
+	case strings.HasPrefix(source, "s3://"):
+		bucket, key, _ := strings.Cut(strings.TrimPrefix(source, "s3://"), "/")
+		content, err := fetchS3Object(ctx, params, bucket, key)
+		return key, content, err
+	case strings.HasPrefix(source, "git+"):
+		cloneURL, filePath, ref := parseGitDependencySource(strings.TrimPrefix(source, "git+"))
+		content, err := fetchGitFile(ctx, cloneURL, filePath, ref, token)
+		return filePath, content, err
+	default:
+		content, err := fetchHTTPFile(ctx, source, token)
+		return source, content, err
+	}
+}
+
+func fetchS3Object(ctx context.Context, params map[string]string, bucket, key string) ([]byte, error) {
+	cfg, err := aws.BuildConfig(ctx,
+		aws.WithProfile(params[aws.ProfileParamName]),
+		aws.WithRegionOverride(params[aws.RegionParamName]))
+	if err != nil {
+		return nil, err
+	}
+
+	output, err := s3Service.NewFromConfig(cfg).GetObject(ctx, &s3Service.GetObjectInput{
+		Bucket: &bucket,
+		Key:    &key,
+	})
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = output.Body.Close()
+	}()
+	return readDependencyFile(output.Body)
+}
+
+func fetchHTTPFile(ctx context.Context, u, token string) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return nil, err
+	}
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to fetch URL: %s", resp.Status)
+	}
+	return readDependencyFile(resp.Body)
+}
+
+// readDependencyFile reads r, returning an error if it is larger than [MaxDependencyFileSize].
+func readDependencyFile(r io.Reader) ([]byte, error) {
+	content, err := io.ReadAll(io.LimitReader(r, MaxDependencyFileSize+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(content) > MaxDependencyFileSize {
+		return nil, fmt.Errorf("dependency file is larger than %d bytes", MaxDependencyFileSize)
+	}
+	return content, nil
+}
+
+// parseGitDependencySource splits a source of the form
+// '<clone URL>//<path>[#<ref>]' into its parts.
+func parseGitDependencySource(source string) (cloneURL, filePath, ref string) {
+	source, ref, _ = strings.Cut(source, "#")
+	offset := 0
+	if idx := strings.Index(source, "://"); idx >= 0 {
+		offset = idx + len("://")
+	}
+	idx := strings.Index(source[offset:], "//")
+	if idx < 0 {
+		return source, "", ref
+	}
+	return source[:offset+idx], source[offset+idx+2:], ref
+}
+
+// fetchGitFile retrieves a single file from a git repository using
+// a shallow, in-memory clone of the given ref (or the default branch).
+func fetchGitFile(ctx context.Context, cloneURL, filePath, ref, token string) ([]byte, error) {
+	if filePath == "" {
+		return nil, fmt.Errorf("no file path given for git repository %s", cloneURL)
+	}
+
+	cloneOpts := &gogit.CloneOptions{
+		URL:          cloneURL,
+		Depth:        1,
+		SingleBranch: true,
+		NoCheckout:   true,
+		Tags:         gogit.NoTags,
+	}
+	if token != "" {
+		cloneOpts.ClientOptions = []client.Option{
+			client.WithHTTPAuth(&githttp.BasicAuth{Username: "x-access-token", Password: token}),
+		}
+	}
+
+	candidates := []plumbing.ReferenceName{""}
+	switch {
+	case strings.HasPrefix(ref, "refs/"):
+		candidates = []plumbing.ReferenceName{plumbing.ReferenceName(ref)}
+	case ref != "":
+		candidates = []plumbing.ReferenceName{plumbing.NewBranchReferenceName(ref), plumbing.NewTagReferenceName(ref)}
+	}
+
+	var (
+		repo *gogit.Repository
+		err  error
+	)
+	for _, candidate := range candidates {
+		cloneOpts.ReferenceName = candidate
+		repo, err = gogit.CloneContext(ctx, memory.NewStorage(), nil, cloneOpts)
+		if err == nil {
+			break
+		}
+	}
+	if err != nil {
+		return nil, fmt.Errorf("cloning %s: %w", cloneURL, err)
+	}
+
+	head, err := repo.Head()
+	if err != nil {
+		return nil, err
+	}
+	commit, err := repo.CommitObject(head.Hash())
+	if err != nil {
+		return nil, err
+	}
+	file, err := commit.File(strings.TrimPrefix(filePath, "/"))
+	if err != nil {
+		return nil, fmt.Errorf("reading %s from %s: %w", filePath, cloneURL, err)
+	}
+	if file.Size > MaxDependencyFileSize {
+		return nil, fmt.Errorf("dependency file is larger than %d bytes", MaxDependencyFileSize)
+	}
+	contents, err := file.Contents()
+	return []byte(contents), err
+}
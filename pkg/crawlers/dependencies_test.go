@@ -0,0 +1,165 @@
+// Copyright (C) 2025-2026 Crash Override, Inc.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the FSF, either version 3 of the License, or (at your option) any later version.
+// See the LICENSE file in the root of this repository for full license text or
+// visit: <https://www.gnu.org/licenses/gpl-3.0.html>.
+
+package crawlers
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/crashappsec/ocular/api/v1beta1"
+)
+
+const testDependencySBOM = `{
+  "bomFormat": "CycloneDX",
+  "components": [
+    {"purl": "pkg:npm/lodash@4.17.21"},
+    {"purl": "pkg:npm/%40acme/util@2.0.0"},
+    {"purl": "pkg:npm/lodash@4.17.21"},
+    {"purl": "pkg:pypi/django@4.2.7"},
+    {"purl": "pkg:docker/library/nginx@1.25?repository_url=docker.io"},
+    {"purl": "pkg:oci/app@sha256%3Aabc?repository_url=ghcr.io/acme/app"}
+  ]
+}`
+
+func TestCrawlDependencies(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/bom.json", func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = fmt.Fprint(w, testDependencySBOM)
+	})
+	mux.HandleFunc("/missing.json", func(w http.ResponseWriter, r *http.Request) {
+		http.NotFound(w, r)
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	tests := []struct {
+		name    string
+		params  map[string]string
+		want    []v1beta1.Target
+		wantErr bool
+	}{
+		{
+			name:   "npm dependencies are deduplicated",
+			params: map[string]string{DependencyEcosystemParamName: DependencyEcosystemNPM},
+			want: []v1beta1.Target{
+				{Identifier: "@acme/util", Version: "2.0.0"},
+				{Identifier: "lodash", Version: "4.17.21"},
+			},
+		},
+		{
+			name: "excluded packages are skipped",
+			params: map[string]string{
+				DependencyEcosystemParamName: DependencyEcosystemNPM,
+				DependencyExcludeParamName:   "@acme/*, left-*",
+			},
+			want: []v1beta1.Target{{Identifier: "lodash", Version: "4.17.21"}},
+		},
+		{
+			name:   "docker and oci packages are routed to docker",
+			params: map[string]string{DependencyEcosystemParamName: DependencyEcosystemDocker},
+			want: []v1beta1.Target{
+				{Identifier: "docker.io/library/nginx", Version: "1.25"},
+				{Identifier: "ghcr.io/acme/app", Version: "sha256:abc"},
+			},
+		},
+		{
+			name:   "pypi",
+			params: map[string]string{DependencyEcosystemParamName: " PyPI "},
+			want:   []v1beta1.Target{{Identifier: "django", Version: "4.2.7"}},
+		},
+		{
+			name:    "unsupported ecosystem",
+			params:  map[string]string{DependencyEcosystemParamName: "maven"},
+			wantErr: true,
+		},
+		{
+			name: "invalid exclude pattern",
+			params: map[string]string{
+				DependencyEcosystemParamName: DependencyEcosystemNPM,
+				DependencyExcludeParamName:   "[",
+			},
+			wantErr: true,
+		},
+		{
+			name: "unavailable source",
+			params: map[string]string{
+				DependencySourceParamName:    server.URL + "/missing.json",
+				DependencyEcosystemParamName: DependencyEcosystemNPM,
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			params := map[string]string{
+				DependencySourceParamName: server.URL + "/bom.json",
+				DependencyFormatParamName: DependencyFormatAuto,
+			}
+			for key, value := range tt.params {
+				params[key] = value
+			}
+
+			queue := make(chan v1beta1.Target, 10)
+			err := crawlDependencies(context.Background(), params, queue)
+			close(queue)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("got error %v, want error %t", err, tt.wantErr)
+			}
+			var got []v1beta1.Target
+			for target := range queue {
+				got = append(got, target)
+			}
+			slices.SortFunc(got, func(a, b v1beta1.Target) int {
+				return strings.Compare(a.Identifier, b.Identifier)
+			})
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+// zeroReader is an endless reader of zero bytes.
+type zeroReader struct{}
+
+func (zeroReader) Read(p []byte) (int, error) {
+	clear(p)
+	return len(p), nil
+}
+
+func TestReadDependencyFile(t *testing.T) {
+	tests := []struct {
+		name    string
+		size    int64
+		wantErr bool
+	}{
+		{name: "empty", size: 0},
+		{name: "at limit", size: MaxDependencyFileSize},
+		{name: "over limit", size: MaxDependencyFileSize + 1, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			content, err := readDependencyFile(io.LimitReader(zeroReader{}, tt.size))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("got error %v, want error %t", err, tt.wantErr)
+			}
+			if !tt.wantErr && int64(len(content)) != tt.size {
+				t.Errorf("got %d bytes, want %d", len(content), tt.size)
+			}
+		})
+	}
+}
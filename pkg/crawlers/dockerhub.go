@@ -12,14 +12,13 @@ import (
 	"context"
 	"fmt"
 	"os"
-	"strconv"
+	"slices"
 	"strings"
 
-	"github.com/crashappsec/ocular-default-integrations/internal/definitions"
 	"github.com/crashappsec/ocular-default-integrations/pkg/clients/dockerhub"
+	"github.com/crashappsec/ocular-default-integrations/pkg/definitions"
 	"github.com/crashappsec/ocular/api/v1beta1"
 	"github.com/hashicorp/go-multierror"
-	"k8s.io/utils/ptr"
 	"sigs.k8s.io/controller-runtime/pkg/log"
 )
 
@@ -29,31 +28,26 @@ const (
 )
 
 func init() {
-	All.registerCrawler(Dockerhub)
+	All.Register(Dockerhub)
 }
 
 var Dockerhub = Crawler{
 	Name: "dockerhub",
-	Parameters: []v1beta1.ParameterDefinition{
+	Parameters: slices.Concat([]v1beta1.ParameterDefinition{
 		{
 			Name:        DockerHubOrgsParam,
 			Description: "Comma-separated list of Docker Hub organizations to crawl.",
 		},
-		{
-			Name: RecentTagLimitParam,
-			Description: "Maximum number of tags (versions) to retrieve per image. " +
-				"Will retrieve the latest N tags for each docker hub image and start a new pipeline for each. " +
-				"Set to 0 to retrieve all versions. Defaults to 1.",
-			Default: ptr.To("1"),
-		},
-	},
+	}, tagSelectionParameters("docker hub"), registryParameters),
 	EnvironmentSecrets: []definitions.EnvironmentSecret{
 		{
 			SecretKey:  "dockerhub-token",
 			EnvVarName: DockerHubTokenSecretEnvVar,
 		},
 	},
-	Crawl: crawlDockerhub,
+	FileSecrets:          registryFileSecrets,
+	EnviornmentVariables: registryEnvironmentVariables,
+	Crawl:                crawlDockerhub,
 }
 
 func crawlDockerhub(ctx context.Context, params map[string]string, queue chan v1beta1.Target) error {
@@ -66,14 +60,18 @@ func crawlDockerhub(ctx context.Context, params map[string]string, queue chan v1
 		AuthToken: token,
 	})
 
-	limit, err := strconv.Atoi(params[RecentTagLimitParam])
+	if len(orgs) == 0 {
+		return fmt.Errorf("no dockerhub org specified")
+	}
+
+	selector, err := newTagSelector(ctx, params)
 	if err != nil {
-		l.Error(err, "invalid value for limit, defaulting to 1", "limit", RecentTagLimitParam)
-		limit = 1
+		return err
 	}
 
-	if len(orgs) == 0 {
-		return fmt.Errorf("no dockerhub org specified")
+	resolver, err := newRegistryTargetResolver(ctx, params, nil)
+	if err != nil {
+		return err
 	}
 
 	var merr *multierror.Error
@@ -94,15 +92,28 @@ func crawlDockerhub(ctx context.Context, params map[string]string, queue chan v1
 				merr = multierror.Append(merr, err)
 				continue
 			}
-			if limit > 0 && len(tags) > limit {
-				tags = tags[:limit]
-			}
+			registryTags := make([]registryTag, 0, len(tags))
 			for _, tag := range tags {
-				targetVersion := tag.Name
-				l.Info("queuing target", "repository", repoName, "tag", targetVersion)
-				queue <- v1beta1.Target{
-					Version:    targetVersion,
-					Identifier: repoName,
+				pushedAt := tag.TagLastPushed
+				if pushedAt.IsZero() {
+					pushedAt = tag.LastUpdated
+				}
+				registryTags = append(registryTags, registryTag{
+					Name:     tag.Name,
+					Digest:   tag.Digest,
+					PushedAt: pushedAt,
+				})
+			}
+			for _, tag := range selector.selectTags(registryTags) {
+				targets, err := resolver.targets(ctx, repoName, tag)
+				if err != nil {
+					l.Error(err, "error resolving tag", "repository", repoName, "tag", tag)
+					merr = multierror.Append(merr, err)
+					continue
+				}
+				for _, target := range targets {
+					l.Info("queuing target", "repository", repoName, "tag", tag, "version", target.Version)
+					queue <- target
 				}
 			}
 		}
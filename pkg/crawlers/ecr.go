@@ -10,42 +10,40 @@ package crawlers
 
 import (
 	"context"
+	"encoding/base64"
 	"fmt"
-	"strconv"
+	"slices"
+	"strings"
+	"time"
 
 	"github.com/aws/aws-sdk-go-v2/service/ecr"
+	"github.com/aws/aws-sdk-go-v2/service/ecr/types"
 	"github.com/crashappsec/ocular-default-integrations/pkg/clients/aws"
 	"github.com/crashappsec/ocular/api/v1beta1"
+	"github.com/google/go-containerregistry/pkg/authn"
 	"github.com/hashicorp/go-multierror"
 	"k8s.io/utils/ptr"
 	"sigs.k8s.io/controller-runtime/pkg/log"
 )
 
 func init() {
-	All.registerCrawler(ECR)
+	All.Register(ECR)
 }
 
 var ECR = Crawler{
 	Name:        "ecr",
 	FileSecrets: aws.FileSecrets,
-	Parameters: append(aws.Parameters, v1beta1.ParameterDefinition{
-		Name: RecentTagLimitParam,
-		Description: "Maximum number of tags (versions) to retrieve per image. " +
-			"Will retrieve the latest N tags for each ECR image and start a new pipeline for each. " +
-			"Set to 0 to retrieve all versions. Defaults to 1.",
-		Default: ptr.To("1"),
-	}),
-	Crawl: crawlECR,
+	Parameters:  slices.Concat(aws.Parameters, tagSelectionParameters("ECR"), registryParameters),
+	Crawl:       crawlECR,
 }
 
 func crawlECR(ctx context.Context, params map[string]string, queue chan v1beta1.Target) error {
-	l := log.FromContext(ctx).WithValues("crawler", "ghcr")
+	l := log.FromContext(ctx).WithValues("crawler", "ecr")
 	regionOverride := params[aws.RegionParamName]
 	profileOverride := params[aws.ProfileParamName]
-	recentTagLimit, err := strconv.Atoi(params[RecentTagLimitParam])
+	selector, err := newTagSelector(ctx, params)
 	if err != nil {
-		l.Error(err, "invalid recent tag limit parameter, defaulting to 1")
-		recentTagLimit = 1
+		return err
 	}
 
 	cfg, err := aws.BuildConfig(ctx, aws.WithProfile(profileOverride), aws.WithRegionOverride(regionOverride))
@@ -59,38 +57,96 @@ func crawlECR(ctx context.Context, params map[string]string, queue chan v1beta1.
 	}
 
 	ecrClient := ecr.NewFromConfig(cfg)
-	var merr *multierror.Error
-	output, err := ecrClient.DescribeRepositories(ctx, &ecr.DescribeRepositoriesInput{})
+
+	resolver, err := newRegistryTargetResolver(ctx, params, nil)
 	if err != nil {
-		l.Error(err, "error describing ECR repositories")
-		return fmt.Errorf("error describing ECR repositories: %w", err)
+		return err
+	}
+	if resolver.accessesRegistry() {
+		auth, err := getECRAuthenticator(ctx, ecrClient)
+		if err != nil {
+			return err
+		}
+		resolver.useAuth(auth)
 	}
 
-	for _, repo := range output.Repositories {
-		repoName := *repo.RepositoryName
-		tagsOutput, err := ecrClient.ListTagsForResource(ctx, &ecr.ListTagsForResourceInput{
-			ResourceArn: repo.RepositoryArn,
-		})
+	var merr *multierror.Error
+	repoPaginator := ecr.NewDescribeRepositoriesPaginator(ecrClient, &ecr.DescribeRepositoriesInput{})
+	for repoPaginator.HasMorePages() {
+		output, err := repoPaginator.NextPage(ctx)
 		if err != nil {
-			l.Error(err, "error listing tags for repository", "repository", repoName)
-			merr = multierror.Append(merr, err)
-			continue
+			l.Error(err, "error describing ECR repositories")
+			return fmt.Errorf("error describing ECR repositories: %w", err)
+		}
+
+		for _, repo := range output.Repositories {
+			repoName := *repo.RepositoryName
+			tags, err := getECRTags(ctx, ecrClient, repoName)
+			if err != nil {
+				l.Error(err, "error describing images of repository", "repository", repoName)
+				merr = multierror.Append(merr, err)
+				continue
+			}
+
+			for _, tag := range selector.selectTags(tags) {
+				// the repository URI is the registry host followed by the repository name
+				targets, err := resolver.targets(ctx, *repo.RepositoryUri, tag)
+				if err != nil {
+					l.Error(err, "error resolving tag", "repository", repoName, "tag", tag)
+					merr = multierror.Append(merr, err)
+					continue
+				}
+				for _, target := range targets {
+					l.Info("queuing target", "repository", repoName, "tag", tag, "version", target.Version)
+					queue <- target
+				}
+			}
 		}
+	}
+	return merr.ErrorOrNil()
+}
 
-		// For simplicity, assuming tagsOutput.Tags contains the tags.
-		tags := tagsOutput.Tags
-		if recentTagLimit > 0 && len(tags) > recentTagLimit {
-			tags = tags[:recentTagLimit]
+// getECRTags returns all tags of the tagged images in the repository. These are the
+// versions of the repository, unlike its resource tags, which are key-value labels.
+// The push time of each image is returned so that the most recent tags are selected.
+func getECRTags(ctx context.Context, client *ecr.Client, repoName string) ([]registryTag, error) {
+	var tags []registryTag
+	paginator := ecr.NewDescribeImagesPaginator(client, &ecr.DescribeImagesInput{
+		RepositoryName: &repoName,
+		Filter:         &types.DescribeImagesFilter{TagStatus: types.TagStatusTagged},
+	})
+	for paginator.HasMorePages() {
+		output, err := paginator.NextPage(ctx)
+		if err != nil {
+			return nil, err
 		}
-		for _, tag := range tags {
-			targetVersion := *tag.Key
-			l.Info("queuing target", "repository", repoName, "tag", targetVersion)
-			queue <- v1beta1.Target{
-				Version:    targetVersion,
-				Identifier: fmt.Sprintf("%s/%s", *repo.RepositoryUri, repoName),
+		for _, image := range output.ImageDetails {
+			for _, tag := range image.ImageTags {
+				tags = append(tags, registryTag{
+					Name:     tag,
+					Digest:   ptr.Deref(image.ImageDigest, ""),
+					PushedAt: ptr.Deref(image.ImagePushedAt, time.Time{}),
+				})
 			}
 		}
+	}
+	return tags, nil
+}
 
+// getECRAuthenticator retrieves a registry authorization token
+// for the account, used when resolving image manifests.
+func getECRAuthenticator(ctx context.Context, client *ecr.Client) (authn.Authenticator, error) {
+	output, err := client.GetAuthorizationToken(ctx, &ecr.GetAuthorizationTokenInput{})
+	if err != nil {
+		return nil, fmt.Errorf("error retrieving ECR authorization token: %w", err)
 	}
-	return merr.ErrorOrNil()
+	if len(output.AuthorizationData) == 0 {
+		return nil, fmt.Errorf("no ECR authorization data returned")
+	}
+	decoded, err := base64.StdEncoding.DecodeString(ptr.Deref(output.AuthorizationData[0].AuthorizationToken, ""))
+	if err != nil {
+		return nil, fmt.Errorf("decoding ECR authorization token: %w", err)
+	}
+	username, password, _ := strings.Cut(string(decoded), ":")
+	return &authn.Basic{Username: username, Password: password}, nil
 }
@@ -0,0 +1,298 @@
+// Copyright (C) 2025-2026 Crash Override, Inc.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the FSF, either version 3 of the License, or (at your option) any later version.
+// See the LICENSE file in the root of this repository for full license text or
+// visit: <https://www.gnu.org/licenses/gpl-3.0.html>.
+
+package crawlers
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/crashappsec/ocular-default-integrations/internal/utils"
+	"github.com/crashappsec/ocular-default-integrations/pkg/definitions"
+	"github.com/crashappsec/ocular/api/v1beta1"
+	"github.com/hashicorp/go-multierror"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+const (
+	ExecCommandParamName = "EXEC_COMMAND"
+	ExecScriptParamName  = "EXEC_SCRIPT"
+	ExecArgsParamName    = "EXEC_ARGS"
+	ExecEnvParamName     = "EXEC_ENV"
+	ExecTimeoutParamName = "EXEC_TIMEOUT"
+
+	ExecTokenSecretEnvVar = "EXEC_TOKEN"
+)
+
+const (
+	// ExecScriptConfigMapName is the ConfigMap whose keys are mounted as executable
+	// files in [ExecScriptMountPath], to run scripts that are not included in the image.
+	ExecScriptConfigMapName = "exec-script"
+	ExecScriptMountPath     = "/ocular/exec"
+)
+
+const (
+	// execMaxLineSize is the maximum size of a line written by the command to stdout.
+	execMaxLineSize = 1024 * 1024
+	// execWaitDelay is how long to wait for the output of the command to be closed
+	// after it exited or was killed, in case it started processes that inherited it.
+	execWaitDelay = 10 * time.Second
+)
+
+func init() {
+	All.Register(Exec)
+}
+
+var Exec = Crawler{
+	Name: "exec",
+	Parameters: []v1beta1.ParameterDefinition{
+		{
+			Name: ExecCommandParamName,
+			Description: "Path or name of the command to run, from the crawler image. The command must " +
+				"write one JSON object per line to stdout, with an 'identifier' and optional 'version' key, " +
+				"for each target. Output on stderr is logged. If " + ExecScriptParamName + " is also set, " +
+				"the command is the interpreter the script is passed to as first argument (e.g. 'python3').",
+			Default: ptr.To(""),
+		},
+		{
+			Name: ExecScriptParamName,
+			Description: "Key of the '" + ExecScriptConfigMapName + "' ConfigMap to run as the command, which " +
+				"is mounted as an executable file in " + ExecScriptMountPath + ". Scripts are run directly unless " +
+				ExecCommandParamName + " is set, and the default image has no shell, " +
+				"so their interpreter must be included in the image.",
+			Default: ptr.To(""),
+		},
+		{
+			Name: ExecArgsParamName,
+			Description: "Arguments passed to the command, separated by whitespace, " +
+				"or a JSON array of strings for arguments containing whitespace.",
+			Default: ptr.To(""),
+		},
+		{
+			Name: ExecEnvParamName,
+			Description: "Comma or newline separated list of 'KEY=value' pairs set as environment variables " +
+				"of the command. The parameters of the crawler are also set as environment variables with " +
+				"their names (e.g. " + ExecArgsParamName + "), along with the environment of the crawler, " +
+				"including the " + ExecTokenSecretEnvVar + " secret if set.",
+			Default: ptr.To(""),
+		},
+		{
+			Name: ExecTimeoutParamName,
+			Description: "Maximum duration the command can run for (e.g. '30m'), after which it is killed " +
+				"and the crawler fails. Set to 0 for no timeout.",
+			Default: ptr.To("10m"),
+		},
+	},
+	EnvironmentSecrets: []definitions.EnvironmentSecret{
+		{
+			SecretKey:  "exec-token",
+			EnvVarName: ExecTokenSecretEnvVar,
+		},
+	},
+	Volumes: []corev1.Volume{
+		{
+			Name: "exec-script",
+			VolumeSource: corev1.VolumeSource{
+				ConfigMap: &corev1.ConfigMapVolumeSource{
+					LocalObjectReference: corev1.LocalObjectReference{Name: ExecScriptConfigMapName},
+					DefaultMode:          ptr.To[int32](0o755),
+					Optional:             ptr.To(true),
+				},
+			},
+		},
+	},
+	VolumeMounts: []corev1.VolumeMount{
+		{
+			Name:      "exec-script",
+			MountPath: ExecScriptMountPath,
+			ReadOnly:  true,
+		},
+	},
+	Crawl: crawlExec,
+}
+
+// crawlExec runs the configured command or script and sends each target it
+// writes to stdout to the queue. Invalid lines are reported and skipped, and
+// the crawler fails if the command exits with a non-zero code.
+func crawlExec(
+	baseCtx context.Context,
+	params map[string]string,
+	queue chan v1beta1.Target,
+) error {
+	l := log.FromContext(baseCtx).WithValues("crawler", "exec")
+
+	command, args, err := execCommandLine(params)
+	if err != nil {
+		return err
+	}
+	if script := strings.TrimSpace(params[ExecScriptParamName]); script != "" {
+		if _, err = os.Stat(filepath.Join(ExecScriptMountPath, script)); err != nil {
+			return fmt.Errorf("script %q not found in the %s ConfigMap: %w", script, ExecScriptConfigMapName, err)
+		}
+	}
+	env, err := execEnvironment(params)
+	if err != nil {
+		return err
+	}
+	timeout, err := time.ParseDuration(strings.TrimSpace(params[ExecTimeoutParamName]))
+	if err != nil {
+		return fmt.Errorf("invalid value for %s: %w", ExecTimeoutParamName, err)
+	}
+
+	ctx := baseCtx
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(baseCtx, timeout)
+		defer cancel()
+	}
+
+	cmd := exec.CommandContext(ctx, command, args...)
+	cmd.Env = env
+	cmd.Stderr = utils.NewLogWriter(l.WithValues("stream", "stderr"))
+	cmd.WaitDelay = execWaitDelay
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return fmt.Errorf("error creating stdout pipe: %w", err)
+	}
+
+	l.Info("running command", "command", command, "args", args, "timeout", timeout)
+	if err = cmd.Start(); err != nil {
+		return fmt.Errorf("error starting command %q: %w", command, err)
+	}
+
+	var (
+		merr    *multierror.Error
+		lineNum int
+	)
+	scanner := bufio.NewScanner(stdout)
+	scanner.Buffer(make([]byte, 0, 64*1024), execMaxLineSize)
+	for scanner.Scan() {
+		lineNum++
+		line := bytes.TrimSpace(scanner.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+
+		target, err := parseExecTarget(line)
+		if err != nil {
+			l.Error(err, "invalid target written by command", "line", lineNum)
+			merr = multierror.Append(merr, fmt.Errorf("line %d: %w", lineNum, err))
+			continue
+		}
+
+		l.Info("queuing target", "identifier", target.Identifier, "version", target.Version)
+		queue <- target
+	}
+	if err = scanner.Err(); err != nil {
+		merr = multierror.Append(merr, fmt.Errorf("error reading command output: %w", err))
+		// drain the output, so the command is not blocked writing to it
+		_, _ = io.Copy(io.Discard, stdout)
+	}
+
+	if err = cmd.Wait(); err != nil {
+		var exitErr *exec.ExitError
+		switch {
+		case errors.Is(ctx.Err(), context.DeadlineExceeded):
+			err = fmt.Errorf("command timed out after %s", timeout)
+		case errors.As(err, &exitErr):
+			err = fmt.Errorf("command exited with code %d", exitErr.ExitCode())
+		default:
+			err = fmt.Errorf("error running command: %w", err)
+		}
+		l.Error(err, "command failed", "command", command)
+		merr = multierror.Append(merr, err)
+	}
+
+	return merr.ErrorOrNil()
+}
+
+// execCommandLine returns the command and arguments to run. The script of [ExecScriptParamName]
+// is run as the command, or passed as first argument to the command if both are set.
+func execCommandLine(params map[string]string) (string, []string, error) {
+	command := strings.TrimSpace(params[ExecCommandParamName])
+	args, err := parseExecArgs(params[ExecArgsParamName])
+	if err != nil {
+		return "", nil, err
+	}
+
+	if script := strings.TrimSpace(params[ExecScriptParamName]); script != "" {
+		if script == "." || script == ".." || strings.ContainsRune(script, '/') {
+			return "", nil, fmt.Errorf("invalid value for %s: %q is not a ConfigMap key", ExecScriptParamName, script)
+		}
+		path := filepath.Join(ExecScriptMountPath, script)
+		if command == "" {
+			return path, args, nil
+		}
+		return command, append([]string{path}, args...), nil
+	}
+
+	if command == "" {
+		return "", nil, fmt.Errorf("no command or script specified")
+	}
+	return command, args, nil
+}
+
+// parseExecArgs parses the arguments of the command,
+// either a JSON array of strings or separated by whitespace.
+func parseExecArgs(value string) ([]string, error) {
+	value = strings.TrimSpace(value)
+	if !strings.HasPrefix(value, "[") {
+		return strings.Fields(value), nil
+	}
+	var args []string
+	if err := json.Unmarshal([]byte(value), &args); err != nil {
+		return nil, fmt.Errorf("invalid value for %s: %w", ExecArgsParamName, err)
+	}
+	return args, nil
+}
+
+// execEnvironment returns the environment of the command, which is the environment
+// of the crawler along with its parameters and the pairs of [ExecEnvParamName].
+func execEnvironment(params map[string]string) ([]string, error) {
+	env := os.Environ()
+	for name, value := range params {
+		env = append(env, name+"="+value)
+	}
+	for _, line := range strings.Split(params[ExecEnvParamName], "\n") {
+		for _, pair := range splitParamList(line) {
+			key, _, ok := strings.Cut(pair, "=")
+			if !ok || strings.TrimSpace(key) == "" {
+				return nil, fmt.Errorf("invalid value for %s: %q is not a 'KEY=value' pair", ExecEnvParamName, pair)
+			}
+			env = append(env, pair)
+		}
+	}
+	return env, nil
+}
+
+// parseExecTarget parses a line written by the command. Unknown keys are
+// rejected, so that misspelled keys are reported instead of being ignored.
+func parseExecTarget(line []byte) (v1beta1.Target, error) {
+	var target v1beta1.Target
+	decoder := json.NewDecoder(bytes.NewReader(line))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&target); err != nil {
+		return target, fmt.Errorf("error parsing target: %w", err)
+	}
+	if decoder.More() {
+		return target, fmt.Errorf("unexpected data after target")
+	}
+	return target, validateStaticTarget(target)
+}
@@ -0,0 +1,85 @@
+// Copyright (C) 2025-2026 Crash Override, Inc.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the FSF, either version 3 of the License, or (at your option) any later version.
+// See the LICENSE file in the root of this repository for full license text or
+// visit: <https://www.gnu.org/licenses/gpl-3.0.html>.
+
+package crawlers
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestExecCommandLine(t *testing.T) {
+	tests := []struct {
+		name        string
+		params      map[string]string
+		wantCommand string
+		wantArgs    []string
+		wantErr     bool
+	}{
+		{
+			name:        "command from image",
+			params:      map[string]string{ExecCommandParamName: "list-targets", ExecArgsParamName: "-a b"},
+			wantCommand: "list-targets",
+			wantArgs:    []string{"-a", "b"},
+		},
+		{
+			name:        "script run directly",
+			params:      map[string]string{ExecScriptParamName: "targets.py", ExecArgsParamName: `["a b"]`},
+			wantCommand: ExecScriptMountPath + "/targets.py",
+			wantArgs:    []string{"a b"},
+		},
+		{
+			name: "script run by interpreter",
+			params: map[string]string{
+				ExecCommandParamName: "python3",
+				ExecScriptParamName:  "targets.py",
+				ExecArgsParamName:    "--org crashappsec",
+			},
+			wantCommand: "python3",
+			wantArgs:    []string{ExecScriptMountPath + "/targets.py", "--org", "crashappsec"},
+		},
+		{
+			name:    "no command or script",
+			params:  map[string]string{ExecArgsParamName: "-a"},
+			wantErr: true,
+		},
+		{
+			name:    "script outside of ConfigMap",
+			params:  map[string]string{ExecScriptParamName: "../bin/sh"},
+			wantErr: true,
+		},
+		{
+			name:    "script parent directory",
+			params:  map[string]string{ExecScriptParamName: ".."},
+			wantErr: true,
+		},
+		{
+			name:    "invalid arguments",
+			params:  map[string]string{ExecCommandParamName: "list-targets", ExecArgsParamName: `["a"`},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			command, args, err := execCommandLine(tt.params)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("expected an error, got %q %v", command, args)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if command != tt.wantCommand || !slices.Equal(args, tt.wantArgs) {
+				t.Errorf("got %q %v, want %q %v", command, args, tt.wantCommand, tt.wantArgs)
+			}
+		})
+	}
+}
@@ -0,0 +1,149 @@
+// Copyright (C) 2025-2026 Crash Override, Inc.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the FSF, either version 3 of the License, or (at your option) any later version.
+// See the LICENSE file in the root of this repository for full license text or
+// visit: <https://www.gnu.org/licenses/gpl-3.0.html>.
+
+package crawlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+
+	"cloud.google.com/go/storage"
+	"github.com/crashappsec/ocular-default-integrations/pkg/clients/gcp"
+	"github.com/crashappsec/ocular-default-integrations/pkg/input"
+	"github.com/crashappsec/ocular/api/v1beta1"
+	"github.com/hashicorp/go-multierror"
+	"google.golang.org/api/iterator"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+const (
+	GCSProjectsParamName     = "GCP_PROJECTS"
+	GCSBucketLabelsParamName = "BUCKET_LABELS"
+)
+
+func init() {
+	All.Register(GCS)
+}
+
+var GCS = Crawler{
+	Name:        "gcs",
+	FileSecrets: gcp.FileSecrets,
+	Parameters: append([]v1beta1.ParameterDefinition{
+		{
+			Name: GCSProjectsParamName,
+			Description: "Comma-separated list of Google Cloud project IDs whose buckets will be crawled. " +
+				"The credentials file in the 'gcp-credentials' secret is used if set, " +
+				"otherwise application default credentials are used.",
+		},
+		{
+			Name: GCSBucketLabelsParamName,
+			Description: "Comma-separated list of labels buckets must have to be crawled, " +
+				"either 'key=value' to match a value or 'key' to match any value.",
+			Default: ptr.To(""),
+		},
+	}, slices.Clone(bucketParameters)...),
+	Crawl: crawlGCS,
+}
+
+// crawlGCS lists the GCS buckets of the given projects and sends each matching
+// bucket, or each of its top-level prefixes, to the queue.
+// The targets are intended to be used with the "gcs" downloader.
+func crawlGCS(ctx context.Context, params map[string]string, queue chan v1beta1.Target) error {
+	l := log.FromContext(ctx).WithValues("crawler", "gcs")
+
+	projects := splitParamList(params[GCSProjectsParamName])
+	if len(projects) == 0 {
+		return fmt.Errorf("no GCP projects specified")
+	}
+	filter, err := newBucketFilter(params[BucketNamePatternParamName], params[GCSBucketLabelsParamName])
+	if err != nil {
+		return err
+	}
+	splitByPrefix, err := input.BoolParam(params, SplitByPrefixParamName, false)
+	if err != nil {
+		return err
+	}
+
+	opts, err := gcp.ClientOptions(ctx)
+	if err != nil {
+		return err
+	}
+	client, err := storage.NewClient(ctx, opts...)
+	if err != nil {
+		return fmt.Errorf("failed to create GCS client: %w", err)
+	}
+	defer func() {
+		if err := client.Close(); err != nil {
+			l.Error(err, "unable to close client")
+		}
+	}()
+
+	var merr *multierror.Error
+	for _, project := range projects {
+		l.Info("listing buckets of project", "project", project)
+		it := client.Buckets(ctx, project)
+		for {
+			attrs, err := it.Next()
+			if errors.Is(err, iterator.Done) {
+				break
+			}
+			if err != nil {
+				l.Error(err, "error listing buckets", "project", project)
+				merr = multierror.Append(merr, fmt.Errorf("project %s: %w", project, err))
+				break
+			}
+
+			if !filter.matchesName(attrs.Name) || !filter.matchesTags(attrs.Labels) {
+				continue
+			}
+
+			targets := []v1beta1.Target{{Identifier: attrs.Name}}
+			if splitByPrefix {
+				prefixes, objects, err := listGCSTopLevel(ctx, client.Bucket(attrs.Name))
+				if err != nil {
+					l.Error(err, "error listing top-level prefixes of bucket", "bucket", attrs.Name)
+					merr = multierror.Append(merr, fmt.Errorf("bucket %s: %w", attrs.Name, err))
+					continue
+				}
+				targets = bucketPrefixTargets(attrs.Name, prefixes, objects)
+			}
+
+			for _, target := range targets {
+				l.Info("queuing target", "identifier", target.Identifier)
+				queue <- target
+			}
+		}
+	}
+
+	return merr.ErrorOrNil()
+}
+
+// listGCSTopLevel returns the top-level prefixes and the names of
+// objects outside of any prefix in the bucket.
+func listGCSTopLevel(ctx context.Context, bucket *storage.BucketHandle) (prefixes, objects []string, err error) {
+	it := bucket.Objects(ctx, &storage.Query{Delimiter: "/"})
+	for {
+		attrs, err := it.Next()
+		if errors.Is(err, iterator.Done) {
+			return prefixes, objects, nil
+		}
+		if err != nil {
+			return nil, nil, err
+		}
+		// with a delimiter set, prefixes are returned as
+		// synthetic objects with only the prefix set
+		if attrs.Prefix != "" {
+			prefixes = append(prefixes, attrs.Prefix)
+		} else {
+			objects = append(objects, attrs.Name)
+		}
+	}
+}
@@ -11,13 +11,14 @@ package crawlers
 import (
 	"context"
 	"fmt"
-	"strconv"
+	"os"
+	"slices"
 	"strings"
 
 	"github.com/crashappsec/ocular/api/v1beta1"
+	"github.com/google/go-containerregistry/pkg/authn"
 	"github.com/google/go-github/v71/github"
 	"github.com/hashicorp/go-multierror"
-	"k8s.io/utils/ptr"
 	"sigs.k8s.io/controller-runtime/pkg/log"
 )
 
@@ -26,26 +27,21 @@ const (
 )
 
 func init() {
-	All.registerCrawler(GHCR)
+	All.Register(GHCR)
 }
 
 var GHCR = Crawler{
 	Name: "ghcr",
-	Parameters: []v1beta1.ParameterDefinition{
+	Parameters: slices.Concat([]v1beta1.ParameterDefinition{
 		{
 			Name:        GitHubOrgsParamName,
 			Description: "Comma-separated list of Docker Hub organizations to crawl.",
 		},
-		{
-			Name: RecentTagLimitParam,
-			Description: "Maximum number of tags (versions) to retrieve per image. " +
-				"Will retrieve the latest N tags for each GHCR image and start a new pipeline for each. " +
-				"Set to 0 to retrieve all versions. Defaults to 1.",
-			Default: ptr.To("1"),
-		},
-	},
-	EnvironmentSecrets: githubAuthenticationEnvironmentSecrets,
-	Crawl:              crawlGHCR,
+	}, tagSelectionParameters("GHCR"), registryParameters),
+	EnvironmentSecrets:   githubAuthenticationEnvironmentSecrets,
+	FileSecrets:          registryFileSecrets,
+	EnviornmentVariables: registryEnvironmentVariables,
+	Crawl:                crawlGHCR,
 }
 
 func crawlGHCR(baseCtx context.Context, params map[string]string, queue chan v1beta1.Target) error {
@@ -60,10 +56,20 @@ func crawlGHCR(baseCtx context.Context, params map[string]string, queue chan v1b
 		return fmt.Errorf("no GHCR orgs specified")
 	}
 
-	limit, err := strconv.Atoi(params[RecentTagLimitParam])
+	selector, err := newTagSelector(ctx, params)
+	if err != nil {
+		return err
+	}
+
+	// the GitHub token can be used as the password for GHCR, otherwise
+	// the mounted docker config is used to authenticate to the registry
+	var auth authn.Authenticator
+	if token := os.Getenv(GitHubTokenSecretEnvVar); token != "" {
+		auth = &authn.Basic{Username: "x-access-token", Password: token}
+	}
+	resolver, err := newRegistryTargetResolver(ctx, params, auth)
 	if err != nil {
-		l.Error(err, "invalid value for limit, defaulting to 1", "limit", RecentTagLimitParam)
-		limit = 1
+		return err
 	}
 
 	var merr *multierror.Error
@@ -79,7 +85,7 @@ func crawlGHCR(baseCtx context.Context, params map[string]string, queue chan v1b
 		if isUser {
 			indexer = client.Users
 		}
-		err = crawlGHCRContainers(ctx, org, queue, indexer, limit)
+		err = crawlGHCRContainers(ctx, org, queue, indexer, resolver, selector)
 		if err != nil {
 			l.Error(err, "Error crawling org", "org", org)
 			merr = multierror.Append(merr, err)
@@ -103,74 +109,86 @@ func crawlGHCRContainers(
 	org string,
 	queue chan v1beta1.Target,
 	indexer GHCRPackageIndexer,
-	tagLimit int,
+	resolver *registryTargetResolver,
+	selector *tagSelector,
 ) error {
 	l := log.FromContext(ctx)
 
-	opt := &github.ListOptions{PerPage: 100}
-	containers, _, err := indexer.ListPackages(ctx, org, &github.PackageListOptions{
+	var containers []*github.Package
+	opt := &github.PackageListOptions{
 		PackageType: github.Ptr("container"),
-		ListOptions: *opt,
-	})
-	if err != nil {
-		return fmt.Errorf("listing GHCR packages for org %q: %v", org, err)
+		ListOptions: github.ListOptions{PerPage: 100},
+	}
+	for {
+		packages, resp, err := indexer.ListPackages(ctx, org, opt)
+		if err != nil {
+			return fmt.Errorf("listing GHCR packages for org %q: %v", org, err)
+		}
+		containers = append(containers, packages...)
+		if resp.NextPage == 0 {
+			break
+		}
+		opt.Page = resp.NextPage
 	}
 
 	var merr *multierror.Error
 	for _, container := range containers {
-		versions, err := getRecentGHCRTags(ctx, org, container.GetName(), indexer, tagLimit)
+		tags, err := getGHCRTags(ctx, org, container.GetName(), indexer)
 		if err != nil {
 			merr = multierror.Append(merr, err)
-			l.Error(err, "Error getting recent tags for container", "container", container.GetName())
+			l.Error(err, "Error getting tags for container", "container", container.GetName())
 			continue
 		}
 		targetID := fmt.Sprintf("ghcr.io/%s/%s", org, container.GetName())
-		for _, version := range versions {
-			target := v1beta1.Target{
-				Identifier: targetID,
-				Version:    version,
+		for _, version := range selector.selectTags(tags) {
+			targets, err := resolver.targets(ctx, targetID, version)
+			if err != nil {
+				merr = multierror.Append(merr, err)
+				l.Error(err, "Error resolving tag of container", "container", container.GetName(), "tag", version)
+				continue
+			}
+			for _, target := range targets {
+				l.Info("Discovered GHCR container", "identifier", targetID, "version", target.Version)
+				queue <- target
 			}
-			l.Info("Discovered GHCR container", "identifier", targetID, "version", version)
-			queue <- target
 		}
 	}
 	return merr.ErrorOrNil()
 }
 
-func getRecentGHCRTags(ctx context.Context,
+// getGHCRTags returns all tags of the versions of a container package.
+// The name of a container package version is the digest of its manifest.
+func getGHCRTags(ctx context.Context,
 	org string,
 	packageName string,
 	indexer GHCRPackageIndexer,
-	tagLimit int,
-) ([]string, error) {
-	var version []string
-	opt := &github.ListOptions{PerPage: 100}
+) ([]registryTag, error) {
+	var tags []registryTag
+	opt := &github.PackageListOptions{ListOptions: github.ListOptions{PerPage: 100}}
 	for {
-		versions, resp, err := indexer.PackageGetAllVersions(ctx, org, "container", packageName, &github.PackageListOptions{
-			ListOptions: *opt,
-		})
+		versions, resp, err := indexer.PackageGetAllVersions(ctx, org, "container", packageName, opt)
 		if err != nil {
 			return nil, err
 		}
 		for _, v := range versions {
 			metadata, ok := v.GetMetadata()
-			if ok {
-				if dockerMetadata := metadata.GetContainer(); dockerMetadata != nil {
-					if tagList := dockerMetadata.Tags; len(tagList) > 0 {
-						// only get the first tag as version, since all other tags
-						// in list point to the same version
-						version = append(version, tagList[0])
-					}
-				}
+			if !ok || metadata.GetContainer() == nil {
+				continue
+			}
+			for _, tag := range metadata.GetContainer().Tags {
+				tags = append(tags, registryTag{
+					Name:     tag,
+					Digest:   v.GetName(),
+					PushedAt: v.GetCreatedAt().Time,
+				})
 			}
 		}
-		if resp.NextPage == 0 || (tagLimit > 0 && len(version) >= tagLimit) {
-			break
+		if resp.NextPage == 0 {
+			return tags, nil
 		}
+		if err = waitForGitHubRateLimit(ctx, resp); err != nil {
+			return nil, err
+		}
+		opt.Page = resp.NextPage
 	}
-
-	if tagLimit > 0 && len(version) > tagLimit {
-		version = version[:tagLimit]
-	}
-	return version, nil
 }
@@ -17,8 +17,8 @@ import (
 	"strings"
 	"time"
 
-	"github.com/crashappsec/ocular-default-integrations/internal/definitions"
 	"github.com/crashappsec/ocular-default-integrations/internal/utils"
+	"github.com/crashappsec/ocular-default-integrations/pkg/definitions"
 	"github.com/crashappsec/ocular/api/v1beta1"
 	"github.com/google/go-github/v71/github"
 	"github.com/hashicorp/go-multierror"
@@ -27,7 +27,7 @@ import (
 )
 
 func init() {
-	All.registerCrawler(GitHub)
+	All.Register(GitHub)
 }
 
 var githubAuthenticationEnvironmentSecrets = []definitions.EnvironmentSecret{
@@ -164,6 +164,33 @@ func crawlOrg(
 	l.Info("crawling github org", "org", org)
 
 	l.Info("beginning to crawl github repositories")
+	err := forEachGitHubRepository(ctx, c, org, isUser, func(repo *github.Repository) {
+		if skipForks && repo.GetFork() {
+			l.Info("skipping forked repository", "repo", repo.GetFullName())
+			return
+		}
+		l.Info("enqueuing repository", "repo", repo.GetFullName(), "url", repo.GetCloneURL())
+		queue <- v1beta1.Target{
+			Identifier: repo.GetCloneURL(),
+		}
+	})
+	if err != nil {
+		return err
+	}
+	l.Info("crawling complete")
+	return nil
+}
+
+// forEachGitHubRepository calls fn for each repository owned by the
+// organization or user, paging through the results and waiting
+// for the rate limit to reset if it is reached.
+func forEachGitHubRepository(
+	ctx context.Context,
+	c *github.Client,
+	owner string,
+	isUser bool,
+	fn func(repo *github.Repository),
+) error {
 	var (
 		opt = github.ListOptions{PerPage: 100}
 		err error
@@ -175,14 +202,14 @@ func crawlOrg(
 		)
 		if isUser {
 			var results *github.RepositoriesSearchResult
-			results, resp, err = c.Search.Repositories(ctx, fmt.Sprintf("user:%s", org), &github.SearchOptions{
+			results, resp, err = c.Search.Repositories(ctx, fmt.Sprintf("user:%s", owner), &github.SearchOptions{
 				ListOptions: opt,
 			})
 			if err == nil {
 				repos = results.Repositories
 			}
 		} else {
-			repos, resp, err = c.Repositories.ListByOrg(ctx, org, &github.RepositoryListByOrgOptions{
+			repos, resp, err = c.Repositories.ListByOrg(ctx, owner, &github.RepositoryListByOrgOptions{
 				ListOptions: opt,
 			})
 		}
@@ -191,39 +218,44 @@ func crawlOrg(
 		}
 
 		for _, repo := range repos {
-			if skipForks && repo.GetFork() {
-				l.Info("skipping forked repository", "repo", repo.GetFullName())
-				continue
-			}
-			l.Info("enqueuing repository", "repo", repo.GetFullName(), "url", repo.GetCloneURL())
-			queue <- v1beta1.Target{
-				Identifier: repo.GetCloneURL(),
-			}
+			fn(repo)
 		}
 		if resp.NextPage == 0 {
-			break
+			return nil
 		}
 
-		// Attempt to handle rate limiting via header
-		if strings.TrimSpace(resp.Header.Get("x-ratelimit-remaining")) == "0" {
-			reset := resp.Header.Get("x-ratelimit-reset")
-			resetTime, convertErr := strconv.Atoi(reset)
-			sleep := time.Hour
-			if convertErr != nil {
-				l.
-					Error(convertErr, "unable to convert ratelimit reset", "reset", reset)
-				l.Info("using default sleep duration", "duration", sleep)
-			} else {
-				sleep = time.Until(time.Unix(int64(resetTime), 0))
-			}
-			l.Info("rate limit reached, sleeping until reset", "duration", sleep)
-			time.Sleep(sleep)
+		if err = waitForGitHubRateLimit(ctx, resp); err != nil {
+			return err
 		}
-
 		opt.Page = resp.NextPage
 	}
-	l.Info("crawling complete")
-	return nil
+}
+
+// waitForGitHubRateLimit sleeps until the rate limit resets if the response indicates
+// no requests are remaining. The error of the context is returned if it is done first.
+func waitForGitHubRateLimit(ctx context.Context, resp *github.Response) error {
+	l := log.FromContext(ctx)
+	// Attempt to handle rate limiting via header
+	if strings.TrimSpace(resp.Header.Get("x-ratelimit-remaining")) != "0" {
+		return nil
+	}
+	reset := resp.Header.Get("x-ratelimit-reset")
+	resetTime, convertErr := strconv.Atoi(reset)
+	sleep := time.Hour
+	if convertErr != nil {
+		l.
+			Error(convertErr, "unable to convert ratelimit reset", "reset", reset)
+		l.Info("using default sleep duration", "duration", sleep)
+	} else {
+		sleep = time.Until(time.Unix(int64(resetTime), 0))
+	}
+	l.Info("rate limit reached, sleeping until reset", "duration", sleep)
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-time.After(sleep):
+		return nil
+	}
 }
 
 // isGitHubUser checks if the given name corresponds to a GitHub user.
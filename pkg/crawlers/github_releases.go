@@ -0,0 +1,195 @@
+// Copyright (C) 2025-2026 Crash Override, Inc.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the FSF, either version 3 of the License, or (at your option) any later version.
+// See the LICENSE file in the root of this repository for full license text or
+// visit: <https://www.gnu.org/licenses/gpl-3.0.html>.
+
+package crawlers
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/crashappsec/ocular-default-integrations/pkg/input"
+	"github.com/crashappsec/ocular/api/v1beta1"
+	"github.com/google/go-github/v71/github"
+	"github.com/hashicorp/go-multierror"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+const (
+	GitHubSkipPrereleasesParamName = "SKIP_PRERELEASES"
+)
+
+func init() {
+	All.Register(GitHubReleases)
+}
+
+var GitHubReleases = Crawler{
+	Name:               "github-releases",
+	EnvironmentSecrets: githubAuthenticationEnvironmentSecrets,
+	Parameters: []v1beta1.ParameterDefinition{
+		{
+			Name: GitHubOrgsParamName,
+			Description: "Comma-separated list of GitHub organizations, users or repositories ('owner/repo') " +
+				"whose releases will be crawled.",
+		},
+		{
+			Name: VersionLimitParamName,
+			Description: "Maximum number of releases to retrieve per repository. " +
+				"Will retrieve the latest N releases of each repository and start a new pipeline for each. " +
+				"Set to 0 to retrieve all releases. Defaults to 1.",
+			Default: ptr.To("1"),
+		},
+		{
+			Name:        GitHubSkipPrereleasesParamName,
+			Description: "If true, releases marked as pre-releases will be skipped. Draft releases are always skipped.",
+			Default:     ptr.To("true"),
+		},
+		{
+			Name:        GitHubSkipForksParamName,
+			Description: "If true, releases of forked repositories will be skipped.",
+			Default:     ptr.To("false"),
+		},
+	},
+	Crawl: crawlGitHubReleases,
+}
+
+// crawlGitHubReleases retrieves the latest releases of the repositories of the given
+// organizations, users or repositories and sends the repository clone URL with the
+// release tag as version to the queue. The targets are intended to be used with the "git" downloader.
+func crawlGitHubReleases(
+	baseCtx context.Context,
+	params map[string]string,
+	queue chan v1beta1.Target,
+) error {
+	l := log.FromContext(baseCtx).WithValues("crawler", "github-releases")
+	ctx := log.IntoContext(baseCtx, l)
+
+	entries := splitParamList(params[GitHubOrgsParamName])
+	if len(entries) == 0 {
+		return fmt.Errorf("no github orgs or repositories specified")
+	}
+	limit, err := strconv.Atoi(params[VersionLimitParamName])
+	if err != nil {
+		l.Error(err, "invalid value for limit, defaulting to 1", "limit", VersionLimitParamName)
+		limit = 1
+	}
+	skipPrereleases, err := input.BoolParam(params, GitHubSkipPrereleasesParamName, true)
+	if err != nil {
+		return err
+	}
+	skipForks, err := input.BoolParam(params, GitHubSkipForksParamName, false)
+	if err != nil {
+		return err
+	}
+
+	// group repositories by owner, since the client is authenticated per owner.
+	// An entry without a repository (stored as "") means all of its repositories.
+	owners := make(map[string][]string)
+	for _, entry := range entries {
+		owner, repo, _ := strings.Cut(entry, "/")
+		owners[owner] = append(owners[owner], repo)
+	}
+
+	var merr *multierror.Error
+	for _, owner := range slices.Sorted(maps.Keys(owners)) {
+		client := createGitHubClientForOrg(ctx, owner)
+
+		var repos []*github.Repository
+		if names := owners[owner]; !slices.Contains(names, "") {
+			for _, name := range names {
+				repo, _, err := client.Repositories.Get(ctx, owner, name)
+				if err != nil {
+					l.Error(err, "error retrieving repository", "owner", owner, "repo", name)
+					merr = multierror.Append(merr, fmt.Errorf("repository %s/%s: %w", owner, name, err))
+					continue
+				}
+				repos = append(repos, repo)
+			}
+		} else {
+			isUser, err := isGitHubUser(ctx, client, owner)
+			if err != nil {
+				l.Error(err, "Error determining if org is an organization or user", "org", owner)
+				merr = multierror.Append(merr, err)
+				continue
+			}
+			err = forEachGitHubRepository(ctx, client, owner, isUser, func(repo *github.Repository) {
+				repos = append(repos, repo)
+			})
+			if err != nil {
+				l.Error(err, "Error listing repositories of org", "org", owner)
+				merr = multierror.Append(merr, err)
+				continue
+			}
+		}
+
+		for _, repo := range repos {
+			if skipForks && repo.GetFork() {
+				l.Info("skipping forked repository", "repo", repo.GetFullName())
+				continue
+			}
+			tags, err := getRecentGitHubReleaseTags(ctx, client, repo, skipPrereleases, limit)
+			if err != nil {
+				l.Error(err, "error listing releases of repository", "repo", repo.GetFullName())
+				merr = multierror.Append(merr, fmt.Errorf("repository %s: %w", repo.GetFullName(), err))
+				continue
+			}
+			for _, tag := range tags {
+				l.Info("queuing target", "repo", repo.GetFullName(), "url", repo.GetCloneURL(), "tag", tag)
+				queue <- v1beta1.Target{
+					Identifier: repo.GetCloneURL(),
+					Version:    tag,
+				}
+			}
+		}
+	}
+
+	return merr.ErrorOrNil()
+}
+
+// getRecentGitHubReleaseTags returns the tags of the most recent published releases
+// of the repository, newest first, truncated to limit if limit is greater than 0.
+func getRecentGitHubReleaseTags(
+	ctx context.Context,
+	c *github.Client,
+	repo *github.Repository,
+	skipPrereleases bool,
+	limit int,
+) ([]string, error) {
+	var (
+		tags []string
+		opt  = github.ListOptions{PerPage: 100}
+	)
+	for {
+		releases, resp, err := c.Repositories.ListReleases(ctx, repo.GetOwner().GetLogin(), repo.GetName(), &opt)
+		if err != nil {
+			return nil, err
+		}
+		// releases are returned ordered by creation date, newest first
+		for _, release := range releases {
+			if release.GetDraft() || (skipPrereleases && release.GetPrerelease()) {
+				continue
+			}
+			tags = append(tags, release.GetTagName())
+			if limit > 0 && len(tags) >= limit {
+				return tags, nil
+			}
+		}
+		if resp.NextPage == 0 {
+			return tags, nil
+		}
+
+		if err = waitForGitHubRateLimit(ctx, resp); err != nil {
+			return nil, err
+		}
+		opt.Page = resp.NextPage
+	}
+}
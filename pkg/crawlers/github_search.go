@@ -0,0 +1,222 @@
+// Copyright (C) 2025-2026 Crash Override, Inc.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the FSF, either version 3 of the License, or (at your option) any later version.
+// See the LICENSE file in the root of this repository for full license text or
+// visit: <https://www.gnu.org/licenses/gpl-3.0.html>.
+
+package crawlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/crashappsec/ocular-default-integrations/pkg/input"
+	"github.com/crashappsec/ocular/api/v1beta1"
+	"github.com/google/go-github/v71/github"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+const (
+	GitHubSearchQueryParamName = "GITHUB_SEARCH_QUERY"
+	GitHubSearchTypeParamName  = "GITHUB_SEARCH_TYPE"
+)
+
+const (
+	GitHubSearchTypeRepositories = "repositories"
+	GitHubSearchTypeCode         = "code"
+)
+
+const (
+	// githubSearchResultLimit is the maximum number of results
+	// the GitHub search API returns for a single query.
+	githubSearchResultLimit = 1000
+	// githubSearchMaxRetries is the number of times a search request
+	// is retried after being rate limited before giving up.
+	githubSearchMaxRetries = 5
+)
+
+func init() {
+	All.Register(GitHubSearch)
+}
+
+var GitHubSearch = Crawler{
+	Name:               "github-search",
+	EnvironmentSecrets: githubAuthenticationEnvironmentSecrets,
+	Parameters: []v1beta1.ParameterDefinition{
+		{
+			Name: GitHubSearchQueryParamName,
+			Description: "GitHub search query (e.g. 'org:acme language:go topic:payments' or 'filename:Dockerfile org:acme'). " +
+				"When authenticating as a GitHub App, the first 'org:' or 'user:' qualifier selects the installation used. " +
+				"GitHub returns at most 1000 results per query.",
+		},
+		{
+			Name: GitHubSearchTypeParamName,
+			Description: "Type of search to run, either 'repositories' or 'code'. " +
+				"Code search requires authentication, and each repository with a matching file is emitted once.",
+			Default: ptr.To(GitHubSearchTypeRepositories),
+		},
+		{
+			Name:        GitHubSkipForksParamName,
+			Description: "If true, forked repositories will be skipped.",
+			Default:     ptr.To("false"),
+		},
+	},
+	Crawl: crawlGitHubSearch,
+}
+
+// crawlGitHubSearch runs a GitHub repository or code search and sends the clone URL
+// of each unique repository in the results to the queue. The targets are intended
+// to be used with the "git" downloader.
+func crawlGitHubSearch(
+	baseCtx context.Context,
+	params map[string]string,
+	queue chan v1beta1.Target,
+) error {
+	l := log.FromContext(baseCtx).WithValues("crawler", "github-search")
+	ctx := log.IntoContext(baseCtx, l)
+
+	query := strings.TrimSpace(params[GitHubSearchQueryParamName])
+	if query == "" {
+		return fmt.Errorf("no github search query specified")
+	}
+	searchType := strings.ToLower(strings.TrimSpace(params[GitHubSearchTypeParamName]))
+	if searchType != GitHubSearchTypeRepositories && searchType != GitHubSearchTypeCode {
+		return fmt.Errorf("unsupported github search type %q", searchType)
+	}
+	skipForks, err := input.BoolParam(params, GitHubSkipForksParamName, false)
+	if err != nil {
+		return err
+	}
+
+	client := createGitHubClientForOrg(ctx, githubSearchOwner(query))
+
+	l.Info("starting github search", "query", query, "type", searchType)
+	var (
+		seen    = make(map[string]struct{})
+		opt     = &github.SearchOptions{ListOptions: github.ListOptions{PerPage: 100}}
+		results int
+	)
+	for {
+		var (
+			repos []*github.Repository
+			total int
+		)
+		resp, err := retryGitHubRateLimit(ctx, func() (*github.Response, error) {
+			if searchType == GitHubSearchTypeCode {
+				result, resp, err := client.Search.Code(ctx, query, opt)
+				if err == nil {
+					total = result.GetTotal()
+					for _, code := range result.CodeResults {
+						repos = append(repos, code.GetRepository())
+					}
+				}
+				return resp, err
+			}
+			result, resp, err := client.Search.Repositories(ctx, query, opt)
+			if err == nil {
+				total = result.GetTotal()
+				repos = result.Repositories
+			}
+			return resp, err
+		})
+		if err != nil {
+			return fmt.Errorf("error searching github: %w", err)
+		}
+
+		for _, repo := range repos {
+			results++
+			if _, ok := seen[repo.GetFullName()]; ok {
+				continue
+			}
+			seen[repo.GetFullName()] = struct{}{}
+
+			if skipForks && repo.GetFork() {
+				l.Info("skipping forked repository", "repo", repo.GetFullName())
+				continue
+			}
+			cloneURL := repo.GetCloneURL()
+			if cloneURL == "" {
+				// code search results only include a subset of the repository fields
+				cloneURL = repo.GetHTMLURL() + ".git"
+			}
+			l.Info("queuing target", "repo", repo.GetFullName(), "url", cloneURL)
+			queue <- v1beta1.Target{
+				Identifier: cloneURL,
+			}
+		}
+
+		if resp.NextPage == 0 {
+			break
+		}
+		if results >= githubSearchResultLimit {
+			l.Info("github search result limit reached, narrow the query to retrieve the remaining results",
+				"total", total, "retrieved", results)
+			break
+		}
+		if err = waitForGitHubRateLimit(ctx, resp); err != nil {
+			return err
+		}
+		opt.Page = resp.NextPage
+	}
+
+	l.Info(fmt.Sprintf("found %d repositories", len(seen)), "repositories", len(seen), "results", results)
+	return nil
+}
+
+// githubSearchOwner returns the value of the first 'org:' or 'user:'
+// qualifier of a search query, or an empty string if there is none.
+func githubSearchOwner(query string) string {
+	for _, term := range strings.Fields(query) {
+		for _, qualifier := range []string{"org:", "user:"} {
+			if owner, ok := strings.CutPrefix(term, qualifier); ok {
+				return strings.Trim(owner, `"`)
+			}
+		}
+	}
+	return ""
+}
+
+// retryGitHubRateLimit calls fn, retrying it after waiting
+// if the request was rejected by the primary or secondary rate limit.
+func retryGitHubRateLimit(
+	ctx context.Context,
+	fn func() (*github.Response, error),
+) (*github.Response, error) {
+	l := log.FromContext(ctx)
+	for attempt := 0; ; attempt++ {
+		resp, err := fn()
+		if err == nil || attempt >= githubSearchMaxRetries {
+			return resp, err
+		}
+
+		var (
+			rateLimitErr      *github.RateLimitError
+			abuseRateLimitErr *github.AbuseRateLimitError
+			sleep             time.Duration
+		)
+		switch {
+		case errors.As(err, &rateLimitErr):
+			sleep = time.Until(rateLimitErr.Rate.Reset.Time)
+		case errors.As(err, &abuseRateLimitErr):
+			sleep = abuseRateLimitErr.GetRetryAfter()
+			if sleep == 0 {
+				sleep = time.Minute
+			}
+		default:
+			return resp, err
+		}
+
+		l.Info("rate limit reached, sleeping until reset", "duration", sleep, "attempt", attempt+1)
+		select {
+		case <-ctx.Done():
+			return resp, ctx.Err()
+		case <-time.After(sleep):
+		}
+	}
+}
@@ -12,19 +12,22 @@ import (
 	"context"
 	"fmt"
 	"os"
+	"slices"
 	"strconv"
 	"strings"
 	"time"
 
-	"github.com/crashappsec/ocular-default-integrations/internal/definitions"
+	"github.com/crashappsec/ocular-default-integrations/pkg/definitions"
+	"github.com/crashappsec/ocular-default-integrations/pkg/input"
 	"github.com/crashappsec/ocular/api/v1beta1"
 	"github.com/hashicorp/go-multierror"
 	gitlab "gitlab.com/gitlab-org/api/client-go"
+	"k8s.io/utils/ptr"
 	"sigs.k8s.io/controller-runtime/pkg/log"
 )
 
 func init() {
-	All.registerCrawler(GitLab)
+	All.Register(GitLab)
 }
 
 var GitLab = Crawler{
@@ -37,16 +40,47 @@ var GitLab = Crawler{
 	},
 	Parameters: []v1beta1.ParameterDefinition{
 		{
-			Name:        GitLabGroupsParamName,
-			Description: "Comma-separated list of GitLab groups to crawl. If empty, the entire instance will be crawled.",
+			Name: GitLabGroupsParamName,
+			Description: "Comma-separated list of GitLab groups or users to crawl. " +
+				"If empty, the entire instance will be crawled, which is not supported for GitLab.com.",
+			Default: ptr.To(""),
 		},
 		{
 			Name:        GitlabInstanceURLParamName,
-			Description: "The base URL of the GitLab instance to crawl. For GitLab.com, use https://gitlab.com/api/v4",
+			Description: "The base URL of the API of the GitLab instance to crawl.",
+			Default:     ptr.To(GitLabDefaultInstanceURL),
 		},
 		{
 			Name:        GitlabIncludeSubgroupParamName,
-			Description: "If set, include projects from subgroups of the specified groups.",
+			Description: "If true, include projects from subgroups of the specified groups.",
+			Default:     ptr.To("false"),
+		},
+		{
+			Name:        GitLabSkipArchivedParamName,
+			Description: "If true, archived projects will be skipped.",
+			Default:     ptr.To("false"),
+		},
+		{
+			Name:        GitLabSkipForksParamName,
+			Description: "If true, forked projects will be skipped.",
+			Default:     ptr.To("false"),
+		},
+		{
+			Name: GitLabVisibilityParamName,
+			Description: "Comma-separated list of project visibilities to crawl ('public', 'internal' or 'private'). " +
+				"All projects are crawled if empty.",
+			Default: ptr.To(""),
+		},
+		{
+			Name:        GitLabTopicsParamName,
+			Description: "Comma-separated list of topics. Only projects with all of the topics will be crawled.",
+			Default:     ptr.To(""),
+		},
+		{
+			Name: GitLabLastActivityParamName,
+			Description: "Only crawl projects with activity within the given duration (e.g. '720h') " +
+				"or since the given RFC 3339 timestamp (e.g. '2025-01-01T00:00:00Z'). All projects are crawled if empty.",
+			Default: ptr.To(""),
 		},
 	},
 	Crawl: crawlGitLab,
@@ -56,13 +90,31 @@ const (
 	GitLabGroupsParamName          = "GITLAB_GROUPS"
 	GitlabInstanceURLParamName     = "GITLAB_INSTANCE_URL"
 	GitlabIncludeSubgroupParamName = "INCLUDE_SUBGROUPS"
+	GitLabSkipArchivedParamName    = "SKIP_ARCHIVED"
+	GitLabSkipForksParamName       = "SKIP_FORKS"
+	GitLabVisibilityParamName      = "GITLAB_VISIBILITY"
+	GitLabTopicsParamName          = "GITLAB_TOPICS"
+	GitLabLastActivityParamName    = "LAST_ACTIVITY_WITHIN"
 )
 
 const (
 	GitlabTokenSecretEnvVar = "GITLAB_TOKEN"
+
+	GitLabDefaultInstanceURL = "https://gitlab.com/api/v4"
 )
 
-// Crawl retrieves all repositories from a specified GitLab groups
+// gitlabProjectFilter holds the filters projects must match to be crawled.
+// Filters are passed to the API where supported, and are always checked
+// for each project, since not all endpoints support all filters.
+type gitlabProjectFilter struct {
+	skipArchived      bool
+	skipForks         bool
+	visibilities      []gitlab.VisibilityValue
+	topics            []string
+	lastActivityAfter *time.Time
+}
+
+// Crawl retrieves all repositories from the specified GitLab groups or users
 // and sends their clone URLs to the provided queue channel. By default, the downloader
 // used is "git", but this can be overridden by setting the parameter variable
 // [DownloaderParamName] to a different value.
@@ -72,156 +124,293 @@ func crawlGitLab(
 	queue chan v1beta1.Target,
 ) error {
 	l := log.FromContext(ctx)
-	splitGroups := strings.Split(params[GitLabGroupsParamName], ",")
-	var groups []string
-	for _, group := range splitGroups {
-		trimmed := strings.TrimSpace(group)
-		if trimmed != "" {
-			groups = append(groups, trimmed)
-		}
-	}
-	l.Info("crawling groups", "groups", groups)
+	namespaces := splitParamList(params[GitLabGroupsParamName])
+	l.Info("crawling groups", "groups", namespaces)
 
 	token := os.Getenv(GitlabTokenSecretEnvVar)
 
-	baseURL := params[GitlabInstanceURLParamName]
+	baseURL := strings.TrimSpace(params[GitlabInstanceURLParamName])
+	if baseURL == "" {
+		baseURL = GitLabDefaultInstanceURL
+	}
 
-	// Check if the recursive parameter is set
-	includeSubGroup := params[GitlabIncludeSubgroupParamName] != ""
+	includeSubGroup, err := input.BoolParam(params, GitlabIncludeSubgroupParamName, false)
+	if err != nil {
+		return err
+	}
 
-	l = l.WithValues("url", baseURL, "groups", groups)
+	filter, err := parseGitLabProjectFilter(params)
+	if err != nil {
+		return err
+	}
+
+	l = l.WithValues("url", baseURL, "groups", namespaces)
+	ctx = log.IntoContext(ctx, l)
 
 	client, err := gitlab.NewClient(token, gitlab.WithBaseURL(baseURL))
 	if err != nil {
 		return fmt.Errorf("error creating gitlab client: %w", err)
 	}
-	if len(groups) == 0 {
+	if len(namespaces) == 0 {
+		if strings.TrimSuffix(baseURL, "/") == GitLabDefaultInstanceURL {
+			return fmt.Errorf("no groups specified, crawling all of GitLab.com is not supported")
+		}
 		// if there are no groups specified, crawl the entire instance
-		return crawlGitlabInstance(ctx, client, queue)
+		return crawlGitlabInstance(ctx, client, filter, queue)
 	}
 
 	var merr *multierror.Error
-	l.Info(fmt.Sprintf("crawling %d gitlab groups", len(groups)), "groups", len(groups))
-	for _, group := range groups {
-		groupL := l.WithValues("group", group)
-		groupL.Info(fmt.Sprintf("crawling gitlab group %s", group))
-		if err := crawlGitlabGroup(ctx, client, group, includeSubGroup, queue); err != nil {
-			groupL.Error(err, "Error crawling gitlab group")
+	l.Info(fmt.Sprintf("crawling %d gitlab groups", len(namespaces)), "groups", len(namespaces))
+	for _, namespace := range namespaces {
+		groupL := l.WithValues("group", namespace)
+		ns, _, err := client.Namespaces.GetNamespace(namespace, gitlab.WithContext(ctx))
+		if err != nil {
+			groupL.Error(err, "Error retrieving gitlab namespace")
+			merr = multierror.Append(merr, fmt.Errorf("namespace %s: %w", namespace, err))
+			continue
+		}
+
+		if ns.Kind == "user" {
+			groupL.Info(fmt.Sprintf("crawling gitlab user %s", namespace))
+			err = crawlGitlabUser(log.IntoContext(ctx, groupL), client, namespace, filter, queue)
+		} else {
+			groupL.Info(fmt.Sprintf("crawling gitlab group %s", namespace))
+			err = crawlGitlabGroup(log.IntoContext(ctx, groupL), client, namespace, includeSubGroup, filter, queue)
+		}
+		if err != nil {
+			groupL.Error(err, "Error crawling gitlab namespace")
 			merr = multierror.Append(merr, err)
 		}
 	}
-	l.Info("finished crawling gitlab groups", "groups", len(groups))
+	l.Info("finished crawling gitlab groups", "groups", len(namespaces))
 
 	return merr.ErrorOrNil()
 }
 
+func parseGitLabProjectFilter(params map[string]string) (gitlabProjectFilter, error) {
+	filter := gitlabProjectFilter{
+		topics: splitParamList(params[GitLabTopicsParamName]),
+	}
+	var err error
+	if filter.skipArchived, err = input.BoolParam(params, GitLabSkipArchivedParamName, false); err != nil {
+		return filter, err
+	}
+	if filter.skipForks, err = input.BoolParam(params, GitLabSkipForksParamName, false); err != nil {
+		return filter, err
+	}
+
+	for _, visibility := range splitParamList(params[GitLabVisibilityParamName]) {
+		switch v := gitlab.VisibilityValue(strings.ToLower(visibility)); v {
+		case gitlab.PublicVisibility, gitlab.InternalVisibility, gitlab.PrivateVisibility:
+			filter.visibilities = append(filter.visibilities, v)
+		default:
+			return filter, fmt.Errorf("invalid value for %s: unknown visibility %q", GitLabVisibilityParamName, visibility)
+		}
+	}
+
+	if lastActivity := strings.TrimSpace(params[GitLabLastActivityParamName]); lastActivity != "" {
+		if d, err := time.ParseDuration(lastActivity); err == nil {
+			filter.lastActivityAfter = ptr.To(time.Now().Add(-d))
+		} else if t, err := time.Parse(time.RFC3339, lastActivity); err == nil {
+			filter.lastActivityAfter = &t
+		} else {
+			return filter, fmt.Errorf("invalid value for %s: %q is not a duration or RFC 3339 timestamp",
+				GitLabLastActivityParamName, lastActivity)
+		}
+	}
+	return filter, nil
+}
+
+// archived returns the value of the 'archived' API filter.
+func (f gitlabProjectFilter) archived() *bool {
+	if f.skipArchived {
+		return ptr.To(false)
+	}
+	return nil
+}
+
+// topic returns the value of the 'topic' API filter, which
+// matches projects that have all of the comma-separated topics.
+func (f gitlabProjectFilter) topic() *string {
+	if len(f.topics) == 0 {
+		return nil
+	}
+	return ptr.To(strings.Join(f.topics, ","))
+}
+
+// listProjectsOptions returns the options for the endpoints
+// listing projects of a user or the whole instance.
+func (f gitlabProjectFilter) listProjectsOptions(opt gitlab.ListOptions) *gitlab.ListProjectsOptions {
+	return &gitlab.ListProjectsOptions{
+		ListOptions:       opt,
+		Archived:          f.archived(),
+		Topic:             f.topic(),
+		LastActivityAfter: f.lastActivityAfter,
+	}
+}
+
+func (f gitlabProjectFilter) matches(p *gitlab.Project) bool {
+	switch {
+	case f.skipArchived && p.Archived:
+		return false
+	case f.skipForks && p.ForkedFromProject != nil:
+		return false
+	case len(f.visibilities) > 0 && !slices.Contains(f.visibilities, p.Visibility):
+		return false
+	case f.lastActivityAfter != nil && p.LastActivityAt != nil && p.LastActivityAt.Before(*f.lastActivityAfter):
+		return false
+	}
+	for _, topic := range f.topics {
+		if !slices.Contains(p.Topics, topic) {
+			return false
+		}
+	}
+	return true
+}
+
+// queueGitLabProjects sends the clone URL of each project matching the filter to the queue.
+func queueGitLabProjects(
+	ctx context.Context,
+	projects []*gitlab.Project,
+	filter gitlabProjectFilter,
+	queue chan v1beta1.Target,
+) {
+	l := log.FromContext(ctx)
+	for _, repo := range projects {
+		if !filter.matches(repo) {
+			l.V(1).Info("skipping filtered gitlab repo", "repo", repo.PathWithNamespace)
+			continue
+		}
+		l.Info("enqueuing gitlab repo", "repo", repo.HTTPURLToRepo)
+		queue <- v1beta1.Target{
+			Identifier: repo.HTTPURLToRepo,
+		}
+	}
+}
+
 func crawlGitlabGroup(
 	ctx context.Context,
 	c *gitlab.Client,
 	org string, includeSubGroups bool,
+	filter gitlabProjectFilter,
 	queue chan v1beta1.Target,
 ) error {
-	l := log.FromContext(ctx)
 	opt := gitlab.ListOptions{PerPage: 100}
 	for {
-		var (
-			projs []*gitlab.Project
-			resp  *gitlab.Response
-			err   error
-		)
-
-		projs, resp, err = c.Groups.ListGroupProjects(
+		projs, resp, err := c.Groups.ListGroupProjects(
 			org,
 			&gitlab.ListGroupProjectsOptions{
 				ListOptions:      opt,
 				IncludeSubGroups: &includeSubGroups,
+				Archived:         filter.archived(),
+				Topic:            filter.topic(),
 			},
+			gitlab.WithContext(ctx),
 		)
 		if err != nil {
 			return err
 		}
 
-		for _, repo := range projs {
-			l.Info("enqueuing gitlab repo", "repo", repo.HTTPURLToRepo)
-			queue <- v1beta1.Target{
-				Identifier: repo.HTTPURLToRepo,
-			}
-		}
-		if resp.NextPage == 0 || resp.NextPage >= resp.TotalPages {
+		queueGitLabProjects(ctx, projs, filter, queue)
+		if resp.NextPage == 0 {
 			break
 		}
 
-		// Attempt to handle rate limiting via header
-		if strings.TrimSpace(resp.Header.Get("RateLimit-Remaining")) == "0" {
-			reset := resp.Header.Get("RateLimit-Reset")
-			resetTime, convertErr := strconv.Atoi(reset)
-			sleep := time.Hour
-			if convertErr != nil {
-				l.Error(convertErr, "unable to convert ratelimit reset", "reset", reset)
-				l.Info("using default sleep duration", "duration", sleep)
-			} else {
-				sleep = time.Until(time.Unix(int64(resetTime), 0))
-			}
-			l.Info("rate limit reached, sleeping until reset", "duration", sleep)
-			time.Sleep(sleep)
+		if err = waitForGitLabRateLimit(ctx, resp); err != nil {
+			return err
 		}
-
 		opt.Page = resp.NextPage
 	}
 	return nil
 }
 
-func crawlGitlabInstance(
+func crawlGitlabUser(
 	ctx context.Context,
 	c *gitlab.Client,
+	user string,
+	filter gitlabProjectFilter,
 	queue chan v1beta1.Target,
 ) error {
-	l := log.FromContext(ctx)
 	opt := gitlab.ListOptions{PerPage: 100}
 	for {
-		var (
-			groups []*gitlab.Group
-			resp   *gitlab.Response
-			err    error
-		)
-
-		groups, resp, err = c.Groups.ListGroups(
-			&gitlab.ListGroupsOptions{
-				ListOptions: opt,
-			},
-		)
+		projs, resp, err := c.Projects.ListUserProjects(user, filter.listProjectsOptions(opt), gitlab.WithContext(ctx))
 		if err != nil {
 			return err
 		}
 
-		for _, group := range groups {
-			err = crawlGitlabGroup(ctx, c, group.FullPath, true, queue)
-			if err != nil {
-				l.Error(err, "Error crawling gitlab group", "group", group.FullPath)
-				continue
-			}
-		}
-		if resp.NextPage == 0 || resp.NextPage >= resp.TotalPages {
+		queueGitLabProjects(ctx, projs, filter, queue)
+		if resp.NextPage == 0 {
 			break
 		}
 
-		// Attempt to handle rate limiting via header
-		if strings.TrimSpace(resp.Header.Get("RateLimit-Remaining")) == "0" {
-			reset := resp.Header.Get("RateLimit-Reset")
-			resetTime, convertErr := strconv.Atoi(reset)
-			sleep := time.Hour
-			if convertErr != nil {
-				l.Error(convertErr, "unable to convert ratelimit reset", "reset", reset)
-				l.Info("using default sleep duration", "duration", sleep)
-			} else {
-				sleep = time.Until(time.Unix(int64(resetTime), 0))
-			}
-			l.Info("rate limit reached, sleeping until reset", "duration", sleep)
-			time.Sleep(sleep)
+		if err = waitForGitLabRateLimit(ctx, resp); err != nil {
+			return err
 		}
-
 		opt.Page = resp.NextPage
 	}
+	return nil
+}
+
+// crawlGitlabInstance crawls all projects of the instance visible to the token,
+// using keyset pagination so very large instances can be listed efficiently.
+func crawlGitlabInstance(
+	ctx context.Context,
+	c *gitlab.Client,
+	filter gitlabProjectFilter,
+	queue chan v1beta1.Target,
+) error {
+	opt := filter.listProjectsOptions(gitlab.ListOptions{
+		Pagination: "keyset",
+		PerPage:    100,
+		OrderBy:    "id",
+		Sort:       "asc",
+	})
+	reqOpts := []gitlab.RequestOptionFunc{gitlab.WithContext(ctx)}
+	for {
+		projs, resp, err := c.Projects.ListProjects(opt, reqOpts...)
+		if err != nil {
+			return err
+		}
+
+		queueGitLabProjects(ctx, projs, filter, queue)
+		if resp.NextLink == "" {
+			break
+		}
+
+		if err = waitForGitLabRateLimit(ctx, resp); err != nil {
+			return err
+		}
+		reqOpts = []gitlab.RequestOptionFunc{
+			gitlab.WithContext(ctx),
+			gitlab.WithKeysetPaginationParameters(resp.NextLink),
+		}
+	}
 
 	return nil
 }
+
+// waitForGitLabRateLimit sleeps until the rate limit resets if the response indicates
+// no requests are remaining. The error of the context is returned if it is done first.
+func waitForGitLabRateLimit(ctx context.Context, resp *gitlab.Response) error {
+	l := log.FromContext(ctx)
+	// Attempt to handle rate limiting via header
+	if strings.TrimSpace(resp.Header.Get("RateLimit-Remaining")) != "0" {
+		return nil
+	}
+	reset := resp.Header.Get("RateLimit-Reset")
+	resetTime, convertErr := strconv.Atoi(reset)
+	sleep := time.Hour
+	if convertErr != nil {
+		l.Error(convertErr, "unable to convert ratelimit reset", "reset", reset)
+		l.Info("using default sleep duration", "duration", sleep)
+	} else {
+		sleep = time.Until(time.Unix(int64(resetTime), 0))
+	}
+	l.Info("rate limit reached, sleeping until reset", "duration", sleep)
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-time.After(sleep):
+		return nil
+	}
+}
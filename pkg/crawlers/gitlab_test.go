@@ -0,0 +1,81 @@
+// Copyright (C) 2025-2026 Crash Override, Inc.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the FSF, either version 3 of the License, or (at your option) any later version.
+// See the LICENSE file in the root of this repository for full license text or
+// visit: <https://www.gnu.org/licenses/gpl-3.0.html>.
+
+package crawlers
+
+import (
+	"slices"
+	"testing"
+
+	gitlab "gitlab.com/gitlab-org/api/client-go"
+)
+
+func TestParseGitLabProjectFilter(t *testing.T) {
+	tests := []struct {
+		name             string
+		params           map[string]string
+		wantSkipArchived bool
+		wantSkipForks    bool
+		wantVisibilities []gitlab.VisibilityValue
+		wantErr          bool
+	}{
+		{
+			name: "defaults",
+		},
+		{
+			name: "booleans and visibilities",
+			params: map[string]string{
+				GitLabSkipArchivedParamName: "true",
+				GitLabSkipForksParamName:    "1",
+				GitLabVisibilityParamName:   "Public, internal",
+			},
+			wantSkipArchived: true,
+			wantSkipForks:    true,
+			wantVisibilities: []gitlab.VisibilityValue{gitlab.PublicVisibility, gitlab.InternalVisibility},
+		},
+		{
+			name:    "invalid skip archived",
+			params:  map[string]string{GitLabSkipArchivedParamName: "yes"},
+			wantErr: true,
+		},
+		{
+			name:    "invalid skip forks",
+			params:  map[string]string{GitLabSkipForksParamName: "ture"},
+			wantErr: true,
+		},
+		{
+			name:    "unknown visibility",
+			params:  map[string]string{GitLabVisibilityParamName: "secret"},
+			wantErr: true,
+		},
+		{
+			name:    "invalid last activity",
+			params:  map[string]string{GitLabLastActivityParamName: "last week"},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseGitLabProjectFilter(tt.params)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("expected an error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got.skipArchived != tt.wantSkipArchived || got.skipForks != tt.wantSkipForks ||
+				!slices.Equal(got.visibilities, tt.wantVisibilities) {
+				t.Errorf("got %+v", got)
+			}
+		})
+	}
+}
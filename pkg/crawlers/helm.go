@@ -0,0 +1,395 @@
+// Copyright (C) 2025-2026 Crash Override, Inc.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the FSF, either version 3 of the License, or (at your option) any later version.
+// See the LICENSE file in the root of this repository for full license text or
+// visit: <https://www.gnu.org/licenses/gpl-3.0.html>.
+
+package crawlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/crashappsec/ocular-default-integrations/pkg/definitions"
+	"github.com/crashappsec/ocular-default-integrations/pkg/input"
+	"github.com/crashappsec/ocular/api/v1beta1"
+	"github.com/google/go-containerregistry/pkg/authn"
+	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+	"github.com/google/go-containerregistry/pkg/v1/remote/transport"
+	"github.com/hashicorp/go-multierror"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/yaml"
+)
+
+const (
+	HelmRepositoryParamName       = "HELM_REPOSITORY"
+	HelmChartNamePatternParamName = "CHART_NAME_PATTERN"
+
+	HelmRepositoryUsernameSecretEnvVar = "HELM_REPOSITORY_USERNAME"
+	HelmRepositoryPasswordSecretEnvVar = "HELM_REPOSITORY_PASSWORD"
+
+	helmArtifactHubPrefix = "artifacthub:"
+	helmOCIPrefix         = "oci://"
+)
+
+const artifactHubAPIURL = "https://artifacthub.io/api/v1"
+
+func init() {
+	All.Register(Helm)
+}
+
+var Helm = Crawler{
+	Name: "helm",
+	Parameters: []v1beta1.ParameterDefinition{
+		{
+			Name: HelmRepositoryParamName,
+			Description: "Chart source to crawl. Either the URL of a classic chart repository serving an 'index.yaml', " +
+				"an OCI registry namespace or chart ('oci://registry/namespace[/chart]') " +
+				"or an Artifact Hub search query ('artifacthub:<query>'). " +
+				"Targets have the identifier '<repository URL>/<chart>' and the chart version as version. " +
+				"The 'helm-repository-username' and 'helm-repository-password' secrets are used " +
+				"to authenticate to classic and OCI repositories if set.",
+		},
+		{
+			Name:        HelmChartNamePatternParamName,
+			Description: "Regular expression that chart names must match to be crawled. All charts are crawled if empty.",
+			Default:     ptr.To(""),
+		},
+		{
+			Name: VersionLimitParamName,
+			Description: "Maximum number of versions to retrieve per chart. Will retrieve the N highest " +
+				"semantic versions of each chart and start a new pipeline for each. " +
+				"Set to 0 to retrieve all versions. Defaults to 1.",
+			Default: ptr.To("1"),
+		},
+		{
+			Name:        IncludePrereleasesParamName,
+			Description: "If true, pre-release chart versions (e.g. '1.0.0-rc.1') are included.",
+			Default:     ptr.To("false"),
+		},
+	},
+	EnvironmentSecrets: []definitions.EnvironmentSecret{
+		{
+			SecretKey:  "helm-repository-username",
+			EnvVarName: HelmRepositoryUsernameSecretEnvVar,
+		},
+		{
+			SecretKey:  "helm-repository-password",
+			EnvVarName: HelmRepositoryPasswordSecretEnvVar,
+		},
+	},
+	Crawl: crawlHelm,
+}
+
+// helmChart is a chart found in a chart source, with all its versions.
+type helmChart struct {
+	Name       string
+	Identifier string
+	Versions   []string
+}
+
+// crawlHelm lists the charts of a chart repository, OCI registry or Artifact Hub
+// search and sends the latest versions of each matching chart to the queue.
+func crawlHelm(ctx context.Context, params map[string]string, queue chan v1beta1.Target) error {
+	l := log.FromContext(ctx).WithValues("crawler", "helm")
+
+	source := strings.TrimSpace(params[HelmRepositoryParamName])
+	if source == "" {
+		return fmt.Errorf("no helm repository specified")
+	}
+
+	var namePattern *regexp.Regexp
+	if pattern := strings.TrimSpace(params[HelmChartNamePatternParamName]); pattern != "" {
+		var err error
+		if namePattern, err = regexp.Compile(pattern); err != nil {
+			return fmt.Errorf("invalid value for %s: %w", HelmChartNamePatternParamName, err)
+		}
+	}
+
+	limit, err := strconv.Atoi(params[VersionLimitParamName])
+	if err != nil {
+		l.Error(err, "invalid value for limit, defaulting to 1", "limit", VersionLimitParamName)
+		limit = 1
+	}
+	includePrereleases, err := input.BoolParam(params, IncludePrereleasesParamName, false)
+	if err != nil {
+		return err
+	}
+
+	var charts []helmChart
+	switch {
+	case strings.HasPrefix(source, helmArtifactHubPrefix):
+		l.Info("searching artifact hub", "query", strings.TrimPrefix(source, helmArtifactHubPrefix))
+		charts, err = listArtifactHubCharts(ctx, strings.TrimPrefix(source, helmArtifactHubPrefix), namePattern)
+	case strings.HasPrefix(source, helmOCIPrefix):
+		l.Info("listing OCI helm charts", "repository", source)
+		charts, err = listOCIHelmCharts(ctx, strings.TrimPrefix(source, helmOCIPrefix), namePattern)
+	default:
+		l.Info("reading helm repository index", "repository", source)
+		charts, err = listHelmIndexCharts(ctx, source)
+	}
+	if err != nil {
+		// charts that were found before the error are still crawled
+		l.Error(err, "error listing charts", "repository", source, "found", len(charts))
+		err = fmt.Errorf("error listing charts of %s: %w", source, err)
+	}
+
+	for _, chart := range charts {
+		if namePattern != nil && !namePattern.MatchString(chart.Name) {
+			continue
+		}
+
+		versions := latestSemverVersions(chart.Versions, includePrereleases, limit)
+		if len(versions) == 0 {
+			l.Info("no matching versions found for chart", "chart", chart.Name)
+			continue
+		}
+		for _, version := range versions {
+			l.Info("queuing target", "chart", chart.Identifier, "version", version)
+			queue <- v1beta1.Target{
+				Identifier: chart.Identifier,
+				Version:    version,
+			}
+		}
+	}
+
+	return err
+}
+
+// helmIndex is the subset of a chart repository 'index.yaml' used by the crawler.
+type helmIndex struct {
+	Entries map[string][]struct {
+		Version    string `json:"version"`
+		Deprecated bool   `json:"deprecated"`
+	} `json:"entries"`
+}
+
+func listHelmIndexCharts(ctx context.Context, repoURL string) ([]helmChart, error) {
+	repoURL = strings.TrimSuffix(strings.TrimSuffix(repoURL, "/index.yaml"), "/")
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, repoURL+"/index.yaml", nil)
+	if err != nil {
+		return nil, err
+	}
+	if username := os.Getenv(HelmRepositoryUsernameSecretEnvVar); username != "" {
+		req.SetBasicAuth(username, os.Getenv(HelmRepositoryPasswordSecretEnvVar))
+	}
+	content, err := doHelmRequest(req)
+	if err != nil {
+		return nil, err
+	}
+
+	var index helmIndex
+	if err = yaml.Unmarshal(content, &index); err != nil {
+		return nil, fmt.Errorf("parsing index.yaml: %w", err)
+	}
+
+	charts := make([]helmChart, 0, len(index.Entries))
+	for chartName, entries := range index.Entries {
+		chart := helmChart{
+			Name:       chartName,
+			Identifier: repoURL + "/" + chartName,
+		}
+		for _, entry := range entries {
+			if !entry.Deprecated {
+				chart.Versions = append(chart.Versions, entry.Version)
+			}
+		}
+		charts = append(charts, chart)
+	}
+	slices.SortFunc(charts, func(a, b helmChart) int {
+		return strings.Compare(a.Name, b.Name)
+	})
+	return charts, nil
+}
+
+// listOCIHelmCharts lists the charts of an OCI registry. If the reference is a
+// chart repository its tags are listed, otherwise the registry catalog
+// is used to find the chart repositories under the namespace.
+func listOCIHelmCharts(ctx context.Context, reference string, namePattern *regexp.Regexp) ([]helmChart, error) {
+	l := log.FromContext(ctx)
+	repo, err := name.NewRepository(strings.TrimSuffix(reference, "/"))
+	if err != nil {
+		return nil, err
+	}
+
+	opts := []remote.Option{remote.WithContext(ctx), remote.WithAuthFromKeychain(authn.DefaultKeychain)}
+	if username := os.Getenv(HelmRepositoryUsernameSecretEnvVar); username != "" {
+		opts[1] = remote.WithAuth(&authn.Basic{
+			Username: username,
+			Password: os.Getenv(HelmRepositoryPasswordSecretEnvVar),
+		})
+	}
+
+	tags, err := remote.List(repo, opts...)
+	if err == nil {
+		return []helmChart{ociHelmChart(repo, tags)}, nil
+	}
+	if !isOCIRepositoryNotFound(err) {
+		return nil, fmt.Errorf("listing tags of %s: %w", repo.Name(), err)
+	}
+
+	l.Info("reference is not a chart repository, listing registry catalog", "reference", reference)
+	catalog, err := remote.Catalog(ctx, repo.Registry, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("listing catalog of registry %s: %w", repo.RegistryStr(), err)
+	}
+
+	var (
+		charts []helmChart
+		merr   *multierror.Error
+	)
+	for _, repoName := range catalog {
+		if !strings.HasPrefix(repoName, repo.RepositoryStr()+"/") {
+			continue
+		}
+		if namePattern != nil && !namePattern.MatchString(path.Base(repoName)) {
+			continue
+		}
+		chartRepo := repo.Registry.Repo(repoName)
+		tags, err := remote.List(chartRepo, opts...)
+		if err != nil {
+			l.Error(err, "error listing tags of chart repository", "repository", chartRepo.Name())
+			merr = multierror.Append(merr, err)
+			continue
+		}
+		charts = append(charts, ociHelmChart(chartRepo, tags))
+	}
+	return charts, merr.ErrorOrNil()
+}
+
+// isOCIRepositoryNotFound reports whether listing the tags of a repository failed because
+// it does not exist or the registry does not support it, in which case the reference may be
+// a namespace of chart repositories. Other errors, such as authentication failures, are not.
+func isOCIRepositoryNotFound(err error) bool {
+	var terr *transport.Error
+	if !errors.As(err, &terr) {
+		return false
+	}
+	if terr.StatusCode == http.StatusNotFound {
+		return true
+	}
+	for _, diagnostic := range terr.Errors {
+		switch diagnostic.Code {
+		case transport.NameUnknownErrorCode, transport.NameInvalidErrorCode, transport.UnsupportedErrorCode:
+			return true
+		}
+	}
+	return false
+}
+
+func ociHelmChart(repo name.Repository, tags []string) helmChart {
+	chart := helmChart{
+		Name:       path.Base(repo.RepositoryStr()),
+		Identifier: helmOCIPrefix + repo.Name(),
+	}
+	for _, tag := range tags {
+		// OCI tags cannot contain '+', so helm replaces it with '_' when pushing
+		chart.Versions = append(chart.Versions, strings.ReplaceAll(tag, "_", "+"))
+	}
+	return chart
+}
+
+type artifactHubPackage struct {
+	Name       string `json:"name"`
+	Repository struct {
+		Name string `json:"name"`
+		URL  string `json:"url"`
+	} `json:"repository"`
+}
+
+// listArtifactHubCharts searches Artifact Hub for helm charts
+// and retrieves the available versions of each result.
+func listArtifactHubCharts(ctx context.Context, query string, namePattern *regexp.Regexp) ([]helmChart, error) {
+	l := log.FromContext(ctx)
+	const pageSize = 60
+
+	var packages []artifactHubPackage
+	for offset := 0; ; offset += pageSize {
+		u := fmt.Sprintf("%s/packages/search?kind=0&limit=%d&offset=%d&ts_query_web=%s",
+			artifactHubAPIURL, pageSize, offset, url.QueryEscape(query))
+		var result struct {
+			Packages []artifactHubPackage `json:"packages"`
+		}
+		if err := getArtifactHubJSON(ctx, u, &result); err != nil {
+			return nil, err
+		}
+		packages = append(packages, result.Packages...)
+		if len(result.Packages) < pageSize {
+			break
+		}
+	}
+
+	var (
+		charts []helmChart
+		merr   *multierror.Error
+	)
+	for _, pkg := range packages {
+		if namePattern != nil && !namePattern.MatchString(pkg.Name) {
+			continue
+		}
+		var details struct {
+			AvailableVersions []struct {
+				Version string `json:"version"`
+			} `json:"available_versions"`
+		}
+		u := fmt.Sprintf("%s/packages/helm/%s/%s",
+			artifactHubAPIURL, url.PathEscape(pkg.Repository.Name), url.PathEscape(pkg.Name))
+		if err := getArtifactHubJSON(ctx, u, &details); err != nil {
+			l.Error(err, "error retrieving chart versions", "repository", pkg.Repository.Name, "chart", pkg.Name)
+			merr = multierror.Append(merr, err)
+			continue
+		}
+
+		chart := helmChart{
+			Name:       pkg.Name,
+			Identifier: strings.TrimSuffix(pkg.Repository.URL, "/") + "/" + pkg.Name,
+		}
+		for _, v := range details.AvailableVersions {
+			chart.Versions = append(chart.Versions, v.Version)
+		}
+		charts = append(charts, chart)
+	}
+	return charts, merr.ErrorOrNil()
+}
+
+func getArtifactHubJSON(ctx context.Context, u string, result any) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+	content, err := doHelmRequest(req)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(content, result)
+}
+
+func doHelmRequest(req *http.Request) ([]byte, error) {
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected response from %s: %s", req.URL.Redacted(), resp.Status)
+	}
+	return io.ReadAll(resp.Body)
+}
@@ -0,0 +1,50 @@
+// Copyright (C) 2025-2026 Crash Override, Inc.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the FSF, either version 3 of the License, or (at your option) any later version.
+// See the LICENSE file in the root of this repository for full license text or
+// visit: <https://www.gnu.org/licenses/gpl-3.0.html>.
+
+package crawlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/google/go-containerregistry/pkg/v1/remote/transport"
+)
+
+func TestIsOCIRepositoryNotFound(t *testing.T) {
+	diagnostic := func(status int, code transport.ErrorCode) error {
+		return &transport.Error{
+			StatusCode: status,
+			Errors:     []transport.Diagnostic{{Code: code}},
+		}
+	}
+
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{name: "not found", err: &transport.Error{StatusCode: http.StatusNotFound}, want: true},
+		{name: "unknown name", err: diagnostic(http.StatusBadRequest, transport.NameUnknownErrorCode), want: true},
+		{name: "unsupported", err: diagnostic(http.StatusMethodNotAllowed, transport.UnsupportedErrorCode), want: true},
+		{name: "wrapped not found", err: fmt.Errorf("listing: %w", diagnostic(http.StatusNotFound, "")), want: true},
+		{name: "unauthorized", err: diagnostic(http.StatusUnauthorized, transport.UnauthorizedErrorCode)},
+		{name: "denied", err: diagnostic(http.StatusForbidden, transport.DeniedErrorCode)},
+		{name: "server error", err: &transport.Error{StatusCode: http.StatusInternalServerError}},
+		{name: "network error", err: errors.New("connection refused")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := isOCIRepositoryNotFound(tt.err); got != tt.want {
+				t.Errorf("got %t, want %t", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,503 @@
+// Copyright (C) 2025-2026 Crash Override, Inc.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the FSF, either version 3 of the License, or (at your option) any later version.
+// See the LICENSE file in the root of this repository for full license text or
+// visit: <https://www.gnu.org/licenses/gpl-3.0.html>.
+
+package crawlers
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/crashappsec/ocular-default-integrations/pkg/definitions"
+	"github.com/crashappsec/ocular-default-integrations/pkg/input"
+	"github.com/crashappsec/ocular/api/v1beta1"
+	"github.com/hashicorp/go-multierror"
+	"k8s.io/client-go/util/jsonpath"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+const (
+	HTTPListURLParamName                  = "HTTP_URL"
+	HTTPListFormatParamName               = "HTTP_FORMAT"
+	HTTPListItemsPathParamName            = "ITEMS_PATH"
+	HTTPListIdentifierExpressionParamName = "IDENTIFIER_EXPRESSION"
+	HTTPListVersionExpressionParamName    = "VERSION_EXPRESSION"
+	HTTPListCursorPathParamName           = "CURSOR_PATH"
+	HTTPListCursorParamParamName          = "CURSOR_PARAM"
+
+	HTTPListBearerTokenSecretEnvVar = "HTTP_BEARER_TOKEN"
+	HTTPListUsernameSecretEnvVar    = "HTTP_USERNAME"
+	HTTPListPasswordSecretEnvVar    = "HTTP_PASSWORD"
+)
+
+const (
+	HTTPListFormatAuto  = "auto"
+	HTTPListFormatJSON  = "json"
+	HTTPListFormatJSONL = "jsonl"
+	HTTPListFormatCSV   = "csv"
+	HTTPListFormatText  = "text"
+)
+
+func init() {
+	All.Register(HTTPList)
+}
+
+var HTTPList = Crawler{
+	Name: "http-list",
+	EnvironmentSecrets: []definitions.EnvironmentSecret{
+		{
+			SecretKey:  "http-bearer-token",
+			EnvVarName: HTTPListBearerTokenSecretEnvVar,
+		},
+		{
+			SecretKey:  "http-username",
+			EnvVarName: HTTPListUsernameSecretEnvVar,
+		},
+		{
+			SecretKey:  "http-password",
+			EnvVarName: HTTPListPasswordSecretEnvVar,
+		},
+	},
+	Parameters: []v1beta1.ParameterDefinition{
+		{
+			Name: HTTPListURLParamName,
+			Description: "URL to retrieve the target list from with a GET request. " +
+				"If the 'http-bearer-token' secret is set it is sent as a bearer token, " +
+				"otherwise the 'http-username' and 'http-password' secrets are sent as basic auth if set.",
+		},
+		{
+			Name: HTTPListFormatParamName,
+			Description: "Format of the response body, one of 'auto', 'json', 'jsonl', 'csv' or 'text'. " +
+				"When 'auto', the format is determined from the Content-Type header. " +
+				"CSV bodies must have a header row, and each item is an object of the row keyed by column. " +
+				"Each line of a text body is an item of the form 'identifier [version]', lines starting with '#' are ignored.",
+			Default: ptr.To(HTTPListFormatAuto),
+		},
+		{
+			Name: HTTPListItemsPathParamName,
+			Description: "JSONPath expression (e.g. '{.data.items}') selecting the list of items in a JSON body. " +
+				"If empty, the body must be a JSON array.",
+			Default: ptr.To(""),
+		},
+		{
+			Name: HTTPListIdentifierExpressionParamName,
+			Description: "Expression to extract the target identifier from each item. Either a JSONPath expression " +
+				"(e.g. '{.name}' or '$.name') or a Go template (e.g. '{{ .namespace }}/{{ .name }}'). " +
+				"Templates fail for items missing a key they refer to, optional keys can be read with " +
+				"'index' and 'default' (e.g. '{{ index . \"tag\" | default \"latest\" }}'), and null values are empty.",
+			Default: ptr.To("{.identifier}"),
+		},
+		{
+			Name: HTTPListVersionExpressionParamName,
+			Description: "Expression to extract the target version from each item, in the same syntax as " +
+				HTTPListIdentifierExpressionParamName + ". Items without a version are emitted without one.",
+			Default: ptr.To("{.version}"),
+		},
+		{
+			Name: HTTPListCursorPathParamName,
+			Description: "JSONPath expression selecting the pagination cursor in a JSON body. If the cursor is a URL, " +
+				"it is requested next, otherwise it is set as the query parameter " + HTTPListCursorParamParamName + ". " +
+				"If empty, pagination follows the 'Link' header with rel=\"next\" when present.",
+			Default: ptr.To(""),
+		},
+		{
+			Name:        HTTPListCursorParamParamName,
+			Description: "Name of the query parameter the cursor value is sent in for the next page.",
+			Default:     ptr.To("cursor"),
+		},
+	},
+	Crawl: crawlHTTPList,
+}
+
+// crawlHTTPList retrieves a list of targets from an HTTP endpoint, following
+// pagination, and sends a target for each item in the list to the queue.
+func crawlHTTPList(ctx context.Context, params map[string]string, queue chan v1beta1.Target) error {
+	l := log.FromContext(ctx).WithValues("crawler", "http-list")
+
+	nextURL := strings.TrimSpace(params[HTTPListURLParamName])
+	if nextURL == "" {
+		return fmt.Errorf("no URL specified")
+	}
+	format := strings.ToLower(strings.TrimSpace(params[HTTPListFormatParamName]))
+	cursorParam := params[HTTPListCursorParamParamName]
+
+	identifierExpr, err := parseItemExpression(
+		HTTPListIdentifierExpressionParamName, params[HTTPListIdentifierExpressionParamName])
+	if err != nil {
+		return err
+	}
+	versionExpr, err := parseItemExpression(
+		HTTPListVersionExpressionParamName, params[HTTPListVersionExpressionParamName])
+	if err != nil {
+		return err
+	}
+	itemsPath, err := parseJSONPath(HTTPListItemsPathParamName, params[HTTPListItemsPathParamName])
+	if err != nil {
+		return err
+	}
+	cursorPath, err := parseJSONPath(HTTPListCursorPathParamName, params[HTTPListCursorPathParamName])
+	if err != nil {
+		return err
+	}
+
+	var (
+		merr    *multierror.Error
+		visited = make(map[string]struct{})
+	)
+	for nextURL != "" {
+		if _, ok := visited[nextURL]; ok {
+			l.Info("pagination returned an already visited page, stopping", "url", nextURL)
+			break
+		}
+		visited[nextURL] = struct{}{}
+
+		l.Info("retrieving target list page", "url", nextURL)
+		body, header, err := getHTTPListPage(ctx, nextURL)
+		if err != nil {
+			return fmt.Errorf("error retrieving %s: %w", nextURL, err)
+		}
+
+		pageFormat := format
+		if pageFormat == "" || pageFormat == HTTPListFormatAuto {
+			pageFormat = detectHTTPListFormat(header.Get("Content-Type"))
+		}
+
+		var document any
+		items, err := decodeHTTPListItems(pageFormat, body, itemsPath, &document)
+		if err != nil {
+			return fmt.Errorf("error decoding %s: %w", nextURL, err)
+		}
+
+		for i, item := range items {
+			identifier, err := identifierExpr.evaluate(item)
+			if err == nil && identifier == "" {
+				err = fmt.Errorf("identifier is empty")
+			}
+			if err != nil {
+				l.Error(err, "unable to extract identifier from item", "url", nextURL, "item", i)
+				merr = multierror.Append(merr, fmt.Errorf("%s item %d: %w", nextURL, i, err))
+				continue
+			}
+			version, err := versionExpr.evaluate(item)
+			if err != nil {
+				l.Error(err, "unable to extract version from item", "url", nextURL, "item", i)
+				merr = multierror.Append(merr, fmt.Errorf("%s item %d: %w", nextURL, i, err))
+				continue
+			}
+
+			l.Info("queuing target", "identifier", identifier, "version", version)
+			queue <- v1beta1.Target{
+				Identifier: identifier,
+				Version:    version,
+			}
+		}
+
+		nextURL, err = nextHTTPListPage(nextURL, header, document, cursorPath, cursorParam)
+		if err != nil {
+			return err
+		}
+	}
+
+	return merr.ErrorOrNil()
+}
+
+func getHTTPListPage(ctx context.Context, u string) ([]byte, http.Header, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+	if token := os.Getenv(HTTPListBearerTokenSecretEnvVar); token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	} else if username, password := os.Getenv(HTTPListUsernameSecretEnvVar),
+		os.Getenv(HTTPListPasswordSecretEnvVar); username != "" || password != "" {
+		req.SetBasicAuth(username, password)
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return nil, nil, fmt.Errorf("received non-2xx response: %s", resp.Status)
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	return body, resp.Header, err
+}
+
+func detectHTTPListFormat(contentType string) string {
+	mediaType, _, _ := mime.ParseMediaType(contentType)
+	switch {
+	case mediaType == "application/x-ndjson" || mediaType == "application/jsonl" ||
+		mediaType == "application/x-jsonlines" || mediaType == "application/json-seq":
+		return HTTPListFormatJSONL
+	case mediaType == "application/json" || strings.HasSuffix(mediaType, "+json"):
+		return HTTPListFormatJSON
+	case mediaType == "text/csv":
+		return HTTPListFormatCSV
+	default:
+		return HTTPListFormatText
+	}
+}
+
+// decodeHTTPListItems decodes the items of a single page. For JSON bodies, the
+// decoded document is stored in document so the pagination cursor can be read from it.
+func decodeHTTPListItems(format string, body []byte, itemsPath *jsonpath.JSONPath, document *any) ([]any, error) {
+	switch format {
+	case HTTPListFormatJSON:
+		if err := json.Unmarshal(body, document); err != nil {
+			return nil, err
+		}
+		if itemsPath == nil {
+			items, ok := (*document).([]any)
+			if !ok {
+				return nil, fmt.Errorf("body is not a JSON array and no %s is set", HTTPListItemsPathParamName)
+			}
+			return items, nil
+		}
+		results, err := itemsPath.FindResults(*document)
+		if err != nil {
+			return nil, fmt.Errorf("evaluating %s: %w", HTTPListItemsPathParamName, err)
+		}
+		var items []any
+		for _, result := range results {
+			for _, value := range result {
+				if list, ok := value.Interface().([]any); ok && len(result) == 1 {
+					items = append(items, list...)
+				} else {
+					items = append(items, value.Interface())
+				}
+			}
+		}
+		return items, nil
+	case HTTPListFormatJSONL:
+		var items []any
+		decoder := json.NewDecoder(bytes.NewReader(body))
+		for {
+			var item any
+			err := decoder.Decode(&item)
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			if err != nil {
+				return nil, err
+			}
+			items = append(items, item)
+		}
+		return items, nil
+	case HTTPListFormatCSV:
+		records, err := csv.NewReader(bytes.NewReader(body)).ReadAll()
+		if err != nil {
+			return nil, err
+		}
+		if len(records) == 0 {
+			return nil, nil
+		}
+		columns := records[0]
+		items := make([]any, 0, len(records)-1)
+		for _, record := range records[1:] {
+			item := make(map[string]any, len(columns))
+			for i, column := range columns {
+				if i < len(record) {
+					item[strings.TrimSpace(column)] = strings.TrimSpace(record[i])
+				}
+			}
+			items = append(items, item)
+		}
+		return items, nil
+	case HTTPListFormatText:
+		var items []any
+		scanner := bufio.NewScanner(bytes.NewReader(body))
+		for scanner.Scan() {
+			fields := strings.Fields(scanner.Text())
+			if len(fields) == 0 || strings.HasPrefix(fields[0], "#") {
+				continue
+			}
+			item := map[string]any{"line": scanner.Text(), "identifier": fields[0]}
+			if len(fields) > 1 {
+				item["version"] = fields[1]
+			}
+			items = append(items, item)
+		}
+		return items, scanner.Err()
+	default:
+		return nil, fmt.Errorf("unsupported format %q", format)
+	}
+}
+
+// nextHTTPListPage determines the URL of the next page, either from the cursor
+// in the JSON document or the 'Link' header. An empty string is returned for the last page.
+func nextHTTPListPage(
+	currentURL string,
+	header http.Header,
+	document any,
+	cursorPath *jsonpath.JSONPath,
+	cursorParam string,
+) (string, error) {
+	current, err := url.Parse(currentURL)
+	if err != nil {
+		return "", err
+	}
+
+	if cursorPath != nil {
+		if document == nil {
+			return "", nil
+		}
+		cursor, err := firstJSONPathValue(cursorPath, document)
+		if err != nil || cursor == "" {
+			return "", err
+		}
+		if next, err := url.Parse(cursor); err == nil && next.IsAbs() {
+			return next.String(), nil
+		}
+		q := current.Query()
+		q.Set(cursorParam, cursor)
+		current.RawQuery = q.Encode()
+		return current.String(), nil
+	}
+
+	for _, link := range header.Values("Link") {
+		for _, part := range strings.Split(link, ",") {
+			target, attributes, ok := strings.Cut(strings.TrimSpace(part), ";")
+			if !ok || !strings.HasPrefix(target, "<") || !strings.HasSuffix(target, ">") {
+				continue
+			}
+			for _, attribute := range strings.Split(attributes, ";") {
+				key, value, _ := strings.Cut(strings.TrimSpace(attribute), "=")
+				if key != "rel" || !strings.Contains(" "+strings.Trim(value, `"`)+" ", " next ") {
+					continue
+				}
+				next, err := current.Parse(strings.Trim(target, "<>"))
+				if err != nil {
+					return "", fmt.Errorf("invalid next link %q: %w", target, err)
+				}
+				return next.String(), nil
+			}
+		}
+	}
+	return "", nil
+}
+
+// itemExpression extracts a string value from an item, using either a JSONPath
+// expression or a Go template parsed with the [input.UserTemplater].
+type itemExpression struct {
+	jsonPath *jsonpath.JSONPath
+	template *input.UserTemplate
+}
+
+func parseItemExpression(name, expression string) (*itemExpression, error) {
+	expression = strings.TrimSpace(expression)
+	e := &itemExpression{}
+	switch {
+	case expression == "":
+	case strings.Contains(expression, "{{"):
+		tmpl, err := input.NewUserTemplater(name).WithErrorOnMissingKey().Parse(expression)
+		if err != nil {
+			return nil, fmt.Errorf("invalid template for %s: %w", name, err)
+		}
+		e.template = tmpl
+	default:
+		jp, err := parseJSONPath(name, expression)
+		if err != nil {
+			return nil, err
+		}
+		e.jsonPath = jp
+	}
+	return e, nil
+}
+
+func (e *itemExpression) evaluate(item any) (string, error) {
+	switch {
+	case e.template != nil:
+		return e.template.Execute(emptyNullValues(item))
+	case e.jsonPath != nil:
+		return firstJSONPathValue(e.jsonPath, item)
+	default:
+		return "", nil
+	}
+}
+
+// emptyNullValues returns a copy of a decoded JSON value with null values replaced by empty
+// strings, so that templates render them empty rather than as '<no value>'.
+func emptyNullValues(value any) any {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case map[string]any:
+		values := make(map[string]any, len(v))
+		for key, element := range v {
+			values[key] = emptyNullValues(element)
+		}
+		return values
+	case []any:
+		values := make([]any, len(v))
+		for i, element := range v {
+			values[i] = emptyNullValues(element)
+		}
+		return values
+	default:
+		return v
+	}
+}
+
+// parseJSONPath parses a JSONPath expression in the syntax of
+// kubectl (e.g. '{.items[*].name}'), also accepting '$.items' style
+// expressions. Nil is returned for an empty expression.
+func parseJSONPath(name, expression string) (*jsonpath.JSONPath, error) {
+	expression = strings.TrimSpace(expression)
+	if expression == "" {
+		return nil, nil
+	}
+	if !strings.HasPrefix(expression, "{") {
+		expression = "{" + expression + "}"
+	}
+	jp := jsonpath.New(name).AllowMissingKeys(true)
+	if err := jp.Parse(expression); err != nil {
+		return nil, fmt.Errorf("invalid JSONPath expression for %s: %w", name, err)
+	}
+	return jp, nil
+}
+
+// firstJSONPathValue returns the first value matched by the expression formatted
+// as a string, or an empty string if the expression did not match anything.
+func firstJSONPathValue(jp *jsonpath.JSONPath, data any) (string, error) {
+	results, err := jp.FindResults(data)
+	if err != nil {
+		return "", err
+	}
+	for _, result := range results {
+		for _, value := range result {
+			switch v := value.Interface().(type) {
+			case nil:
+				return "", nil
+			case string:
+				return v, nil
+			case float64:
+				return strconv.FormatFloat(v, 'f', -1, 64), nil
+			default:
+				return fmt.Sprint(v), nil
+			}
+		}
+	}
+	return "", nil
+}
@@ -0,0 +1,445 @@
+// Copyright (C) 2025-2026 Crash Override, Inc.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the FSF, either version 3 of the License, or (at your option) any later version.
+// See the LICENSE file in the root of this repository for full license text or
+// visit: <https://www.gnu.org/licenses/gpl-3.0.html>.
+
+package crawlers
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"slices"
+	"testing"
+
+	"github.com/crashappsec/ocular/api/v1beta1"
+)
+
+func TestDetectHTTPListFormat(t *testing.T) {
+	tests := []struct {
+		contentType string
+		want        string
+	}{
+		{contentType: "application/json", want: HTTPListFormatJSON},
+		{contentType: "application/json; charset=utf-8", want: HTTPListFormatJSON},
+		{contentType: "application/vnd.api+json", want: HTTPListFormatJSON},
+		{contentType: "application/x-ndjson", want: HTTPListFormatJSONL},
+		{contentType: "application/jsonl", want: HTTPListFormatJSONL},
+		{contentType: "text/csv", want: HTTPListFormatCSV},
+		{contentType: "text/plain", want: HTTPListFormatText},
+		{contentType: "", want: HTTPListFormatText},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.contentType, func(t *testing.T) {
+			if got := detectHTTPListFormat(tt.contentType); got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDecodeHTTPListItems(t *testing.T) {
+	tests := []struct {
+		name      string
+		format    string
+		body      string
+		itemsPath string
+		want      []any
+		wantErr   bool
+	}{
+		{
+			name:   "json array",
+			format: HTTPListFormatJSON,
+			body:   `[{"identifier": "a"}, {"identifier": "b", "version": "1"}]`,
+			want: []any{
+				map[string]any{"identifier": "a"},
+				map[string]any{"identifier": "b", "version": "1"},
+			},
+		},
+		{
+			name:      "json items path",
+			format:    HTTPListFormatJSON,
+			body:      `{"data": {"items": [{"name": "a"}, {"name": "b"}]}, "next": "x"}`,
+			itemsPath: "{.data.items}",
+			want:      []any{map[string]any{"name": "a"}, map[string]any{"name": "b"}},
+		},
+		{
+			name:      "json items path with wildcard",
+			format:    HTTPListFormatJSON,
+			body:      `{"groups": [{"repos": ["a", "b"]}, {"repos": ["c"]}]}`,
+			itemsPath: "$.groups[*].repos[*]",
+			want:      []any{"a", "b", "c"},
+		},
+		{
+			name:      "json items path missing",
+			format:    HTTPListFormatJSON,
+			body:      `{"data": {}}`,
+			itemsPath: "{.data.items}",
+		},
+		{
+			name:    "json object without items path",
+			format:  HTTPListFormatJSON,
+			body:    `{"items": []}`,
+			wantErr: true,
+		},
+		{
+			name:    "invalid json",
+			format:  HTTPListFormatJSON,
+			body:    `[{"identifier": "a"`,
+			wantErr: true,
+		},
+		{
+			name:   "jsonl",
+			format: HTTPListFormatJSONL,
+			body:   "{\"identifier\": \"a\"}\n\n{\"identifier\": \"b\"}\n",
+			want:   []any{map[string]any{"identifier": "a"}, map[string]any{"identifier": "b"}},
+		},
+		{
+			name:    "invalid jsonl",
+			format:  HTTPListFormatJSONL,
+			body:    "{\"identifier\": \"a\"}\nnot json\n",
+			wantErr: true,
+		},
+		{
+			name:   "csv",
+			format: HTTPListFormatCSV,
+			body:   "identifier, version\nhttps://github.com/org/a, v1\nhttps://github.com/org/b,\n",
+			want: []any{
+				map[string]any{"identifier": "https://github.com/org/a", "version": "v1"},
+				map[string]any{"identifier": "https://github.com/org/b", "version": ""},
+			},
+		},
+		{
+			name:   "csv header only",
+			format: HTTPListFormatCSV,
+			body:   "identifier,version\n",
+			want:   []any{},
+		},
+		{
+			name:    "csv with missing column",
+			format:  HTTPListFormatCSV,
+			body:    "identifier,version\na\n",
+			wantErr: true,
+		},
+		{
+			name:   "text",
+			format: HTTPListFormatText,
+			body:   "# targets\na\n\n  b 1.0  \n",
+			want: []any{
+				map[string]any{"line": "a", "identifier": "a"},
+				map[string]any{"line": "  b 1.0  ", "identifier": "b", "version": "1.0"},
+			},
+		},
+		{
+			name:    "unsupported format",
+			format:  "xml",
+			body:    "<targets/>",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			itemsPath, err := parseJSONPath(HTTPListItemsPathParamName, tt.itemsPath)
+			if err != nil {
+				t.Fatalf("parsing items path: %v", err)
+			}
+			var document any
+			got, err := decodeHTTPListItems(tt.format, []byte(tt.body), itemsPath, &document)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("expected an error, got %v", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if len(got) != 0 || len(tt.want) != 0 {
+				if !reflect.DeepEqual(got, tt.want) {
+					t.Errorf("got %#v, want %#v", got, tt.want)
+				}
+			}
+		})
+	}
+}
+
+func TestNextHTTPListPage(t *testing.T) {
+	const current = "https://inventory.example.com/api/assets?limit=10"
+
+	tests := []struct {
+		name        string
+		links       []string
+		document    any
+		cursorPath  string
+		cursorParam string
+		want        string
+	}{
+		{
+			name:        "cursor value",
+			document:    map[string]any{"meta": map[string]any{"next": "abc"}},
+			cursorPath:  "{.meta.next}",
+			cursorParam: "after",
+			want:        "https://inventory.example.com/api/assets?after=abc&limit=10",
+		},
+		{
+			name:        "cursor value replaces previous cursor",
+			document:    map[string]any{"next": 20},
+			cursorPath:  "$.next",
+			cursorParam: "limit",
+			want:        "https://inventory.example.com/api/assets?limit=20",
+		},
+		{
+			name:       "cursor URL",
+			document:   map[string]any{"next": "https://inventory.example.com/api/assets?page=2"},
+			cursorPath: "{.next}",
+			want:       "https://inventory.example.com/api/assets?page=2",
+		},
+		{
+			name:       "empty cursor is last page",
+			document:   map[string]any{"next": ""},
+			cursorPath: "{.next}",
+		},
+		{
+			name:       "null cursor is last page",
+			document:   map[string]any{"next": nil},
+			cursorPath: "{.next}",
+		},
+		{
+			name:       "missing cursor is last page",
+			document:   map[string]any{},
+			cursorPath: "{.next}",
+		},
+		{
+			name:       "cursor without JSON document is last page",
+			cursorPath: "{.next}",
+		},
+		{
+			name:  "link header",
+			links: []string{`<https://inventory.example.com/api/assets?page=2>; rel="next"`},
+			want:  "https://inventory.example.com/api/assets?page=2",
+		},
+		{
+			name: "relative link among others",
+			links: []string{
+				`</api/assets?page=1>; rel="first", </api/assets?page=3>; rel="prev next"`,
+			},
+			want: "https://inventory.example.com/api/assets?page=3",
+		},
+		{
+			name:  "link header without next",
+			links: []string{`<https://inventory.example.com/api/assets?page=1>; rel="prev"`},
+		},
+		{
+			name: "no pagination",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cursorPath, err := parseJSONPath(HTTPListCursorPathParamName, tt.cursorPath)
+			if err != nil {
+				t.Fatalf("parsing cursor path: %v", err)
+			}
+			header := http.Header{}
+			for _, link := range tt.links {
+				header.Add("Link", link)
+			}
+			got, err := nextHTTPListPage(current, header, tt.document, cursorPath, tt.cursorParam)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestItemExpression(t *testing.T) {
+	item := map[string]any{
+		"name":      "api",
+		"namespace": "org",
+		"stars":     float64(1200),
+		"archived":  false,
+		"tag":       nil,
+		"labels":    map[string]any{"team": "platform"},
+	}
+
+	tests := []struct {
+		name         string
+		expression   string
+		want         string
+		wantParseErr bool
+		wantEvalErr  bool
+	}{
+		{name: "empty expression", expression: ""},
+		{name: "jsonpath", expression: "{.name}", want: "api"},
+		{name: "jsonpath with dollar", expression: "$.labels.team", want: "platform"},
+		{name: "jsonpath number", expression: "{.stars}", want: "1200"},
+		{name: "jsonpath boolean", expression: "{.archived}", want: "false"},
+		{name: "jsonpath null", expression: "{.tag}", want: ""},
+		{name: "jsonpath missing key", expression: "{.version}", want: ""},
+		{name: "template", expression: "{{ .namespace }}/{{ .name }}", want: "org/api"},
+		{name: "template function", expression: "{{ .name | toupper }}", want: "API"},
+		{name: "template null", expression: "{{ .name }}:{{ .tag }}", want: "api:"},
+		{name: "template default for null", expression: `{{ .tag | default "latest" }}`, want: "latest"},
+		{name: "template default for missing key", expression: `{{ index . "version" | default "1.0" }}`, want: "1.0"},
+		{name: "template default for present key", expression: `{{ index . "name" | default "x" }}`, want: "api"},
+		{name: "template missing key", expression: "{{ .version }}", wantEvalErr: true},
+		{name: "invalid template", expression: "{{ .name ", wantParseErr: true},
+		{name: "invalid jsonpath", expression: "{.name[}", wantParseErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e, err := parseItemExpression(HTTPListIdentifierExpressionParamName, tt.expression)
+			if tt.wantParseErr {
+				if err == nil {
+					t.Fatal("expected a parse error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected parse error: %v", err)
+			}
+			// each expression is evaluated twice, since it is parsed once for all items
+			for range 2 {
+				got, err := e.evaluate(item)
+				if tt.wantEvalErr {
+					if err == nil {
+						t.Fatalf("expected an error, got %q", got)
+					}
+					continue
+				}
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				if got != tt.want {
+					t.Errorf("got %q, want %q", got, tt.want)
+				}
+			}
+		})
+	}
+}
+
+func TestCrawlHTTPListPagination(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/link", func(w http.ResponseWriter, r *http.Request) {
+		page := r.URL.Query().Get("page")
+		w.Header().Set("Content-Type", "application/json")
+		switch page {
+		case "":
+			w.Header().Set("Link", `</link?page=2>; rel="next"`)
+			_, _ = fmt.Fprint(w, `[{"identifier": "a", "version": "1"}, {"identifier": "b"}]`)
+		case "2":
+			// links back to the first page, which is not requested again
+			w.Header().Set("Link", `</link>; rel="next"`)
+			_, _ = fmt.Fprint(w, `[{"identifier": "c"}]`)
+		}
+	})
+	mux.HandleFunc("/cursor", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/x-ndjson")
+		switch r.URL.Query().Get("after") {
+		case "":
+			w.Header().Set("Link", `</cursor?after=x>; rel="next"`)
+			_, _ = fmt.Fprint(w, "{\"identifier\": \"a\"}\n")
+		case "x":
+			_, _ = fmt.Fprint(w, "{\"identifier\": \"b\"}\n")
+		}
+	})
+	mux.HandleFunc("/wrapped", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Query().Get("cursor") {
+		case "":
+			_, _ = fmt.Fprint(w, `{"items": [{"repo": "a"}], "next": "p2"}`)
+		case "p2":
+			_, _ = fmt.Fprint(w, `{"items": [{"repo": "b", "ref": "main"}], "next": null}`)
+		}
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	tests := []struct {
+		name    string
+		params  map[string]string
+		want    []v1beta1.Target
+		wantErr bool
+	}{
+		{
+			name:   "link header",
+			params: map[string]string{HTTPListURLParamName: server.URL + "/link"},
+			want: []v1beta1.Target{
+				{Identifier: "a", Version: "1"},
+				{Identifier: "b"},
+				{Identifier: "c"},
+			},
+		},
+		{
+			name: "jsonl with link header",
+			params: map[string]string{
+				HTTPListURLParamName:    server.URL + "/cursor",
+				HTTPListFormatParamName: HTTPListFormatAuto,
+			},
+			want: []v1beta1.Target{{Identifier: "a"}, {Identifier: "b"}},
+		},
+		{
+			name: "cursor in body",
+			params: map[string]string{
+				HTTPListURLParamName:                  server.URL + "/wrapped",
+				HTTPListItemsPathParamName:            "{.items}",
+				HTTPListCursorPathParamName:           "{.next}",
+				HTTPListCursorParamParamName:          "cursor",
+				HTTPListIdentifierExpressionParamName: "https://github.com/org/{{ .repo }}",
+				HTTPListVersionExpressionParamName:    `{{ index . "ref" | default "" }}`,
+			},
+			want: []v1beta1.Target{
+				{Identifier: "https://github.com/org/a"},
+				{Identifier: "https://github.com/org/b", Version: "main"},
+			},
+		},
+		{
+			name: "items with errors are skipped",
+			params: map[string]string{
+				HTTPListURLParamName:               server.URL + "/link",
+				HTTPListVersionExpressionParamName: "{{ .version }}",
+			},
+			want:    []v1beta1.Target{{Identifier: "a", Version: "1"}},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			params := map[string]string{
+				HTTPListFormatParamName:               HTTPListFormatAuto,
+				HTTPListIdentifierExpressionParamName: "{.identifier}",
+				HTTPListVersionExpressionParamName:    "{.version}",
+				HTTPListCursorParamParamName:          "cursor",
+			}
+			for key, value := range tt.params {
+				params[key] = value
+			}
+
+			queue := make(chan v1beta1.Target, 10)
+			err := crawlHTTPList(context.Background(), params, queue)
+			close(queue)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("got error %v, want error %t", err, tt.wantErr)
+			}
+			var got []v1beta1.Target
+			for target := range queue {
+				got = append(got, target)
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -10,25 +10,41 @@ package crawlers
 
 import (
 	"context"
+	"fmt"
+	"slices"
+	"strings"
 
-	"github.com/crashappsec/ocular-default-integrations/internal/definitions"
+	"github.com/crashappsec/ocular-default-integrations/pkg/definitions"
 	"github.com/crashappsec/ocular/api/v1beta1"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
-var All = make(all)
+// All is the registry of the default crawlers, populated when the package is initialized.
+// Other modules can register their own crawlers to it and pass it to [Run],
+// to build a single image containing both the defaults and their integrations.
+var All = make(Registry)
 
-type all map[string]Crawler
+// Registry holds crawlers by name.
+type Registry map[string]Crawler
 
-func (a all) registerCrawler(c Crawler) {
-	if a == nil {
+// Register adds the crawler to the registry. It panics if the crawler has no name
+// or Crawl function, or if a crawler with the same name is already registered.
+// To replace a registered crawler, delete it from the registry first.
+func (r Registry) Register(c Crawler) {
+	if r == nil {
 		return
 	}
+	if c.Name == "" {
+		panic("crawler name must be set")
+	}
 	if c.Crawl == nil {
 		panic("crawl function must be set")
 	}
-	a[c.Name] = c
+	if _, exists := r[c.Name]; exists {
+		panic(fmt.Sprintf("crawler %q is already registered", c.Name))
+	}
+	r[c.Name] = c
 }
 
 type Crawler struct {
@@ -38,11 +54,21 @@ type Crawler struct {
 	EnvironmentSecrets   []definitions.EnvironmentSecret
 	FileSecrets          []definitions.FileSecret
 	EnviornmentVariables []corev1.EnvVar
+	// Volumes are added to the pod of the crawler, and mounted in its container with VolumeMounts.
+	Volumes      []corev1.Volume
+	VolumeMounts []corev1.VolumeMount
 }
 
+// GenerateObjects returns the ClusterCrawler definitions of the default crawlers.
 func GenerateObjects(image, secretName string) []*v1beta1.ClusterCrawler {
-	crawlerObjs := make([]*v1beta1.ClusterCrawler, 0, len(All))
-	for _, c := range All {
+	return All.GenerateObjects(image, secretName)
+}
+
+// GenerateObjects returns a ClusterCrawler definition for each crawler in the registry,
+// running the given image and reading secrets from the secret secretName.
+func (r Registry) GenerateObjects(image, secretName string) []*v1beta1.ClusterCrawler {
+	crawlerObjs := make([]*v1beta1.ClusterCrawler, 0, len(r))
+	for _, c := range r {
 		crawlerParams := c.Parameters
 
 		seenParams := make(map[string]struct{}, len(crawlerParams))
@@ -60,16 +86,19 @@ func GenerateObjects(image, secretName string) []*v1beta1.ClusterCrawler {
 			},
 			Spec: v1beta1.CrawlerSpec{
 				Container: corev1.Container{
-					Name:  c.Name,
-					Image: image,
-					Env:   c.EnviornmentVariables,
+					Name:         c.Name,
+					Image:        image,
+					Env:          slices.Clone(c.EnviornmentVariables),
+					VolumeMounts: slices.Clone(c.VolumeMounts),
 				},
+				Volumes:    slices.Clone(c.Volumes),
 				Parameters: crawlerParams,
 			},
 		}
 
 		if envSecrets := c.EnvironmentSecrets; envSecrets != nil {
-			crawlerObj.Spec.Container.Env = definitions.EnvironmentSecretsToEnvVars(secretName, envSecrets)
+			crawlerObj.Spec.Container.Env = append(crawlerObj.Spec.Container.Env,
+				definitions.EnvironmentSecretsToEnvVars(secretName, envSecrets)...)
 		}
 
 		if fileSecrets := c.FileSecrets; fileSecrets != nil {
@@ -82,3 +111,15 @@ func GenerateObjects(image, secretName string) []*v1beta1.ClusterCrawler {
 	}
 	return crawlerObjs
 }
+
+// splitParamList splits a comma-separated parameter value,
+// trimming whitespace and dropping any empty entries.
+func splitParamList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if trimmed := strings.TrimSpace(item); trimmed != "" {
+			items = append(items, trimmed)
+		}
+	}
+	return items
+}
@@ -0,0 +1,399 @@
+// Copyright (C) 2025-2026 Crash Override, Inc.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the FSF, either version 3 of the License, or (at your option) any later version.
+// See the LICENSE file in the root of this repository for full license text or
+// visit: <https://www.gnu.org/licenses/gpl-3.0.html>.
+
+package crawlers
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"path"
+	"strings"
+)
+
+// dependency is a single package parsed from a lockfile or SBOM.
+type dependency struct {
+	// Ecosystem is the name of the downloader able to retrieve the dependency,
+	// one of [DependencyEcosystemNPM], [DependencyEcosystemPyPI] or [DependencyEcosystemDocker].
+	Ecosystem string
+	Name      string
+	Version   string
+}
+
+const (
+	DependencyEcosystemNPM    = "npm"
+	DependencyEcosystemPyPI   = "pypi"
+	DependencyEcosystemDocker = "docker"
+)
+
+const (
+	DependencyFormatAuto         = "auto"
+	DependencyFormatPackageLock  = "package-lock"
+	DependencyFormatYarnLock     = "yarn-lock"
+	DependencyFormatPoetryLock   = "poetry-lock"
+	DependencyFormatRequirements = "requirements"
+	DependencyFormatCycloneDX    = "cyclonedx"
+	DependencyFormatSPDX         = "spdx"
+)
+
+type dependencyParser func(content []byte) ([]dependency, error)
+
+var dependencyParsers = map[string]dependencyParser{
+	DependencyFormatPackageLock:  parsePackageLock,
+	DependencyFormatYarnLock:     parseYarnLock,
+	DependencyFormatPoetryLock:   parsePoetryLock,
+	DependencyFormatRequirements: parseRequirements,
+	DependencyFormatCycloneDX:    parseCycloneDX,
+	DependencyFormatSPDX:         parseSPDX,
+}
+
+// detectDependencyFormat determines the format of a dependency file
+// from its file name, falling back to inspecting JSON content for SBOMs.
+func detectDependencyFormat(filename string, content []byte) (string, error) {
+	base := strings.ToLower(path.Base(filename))
+	switch {
+	case base == "package-lock.json" || base == "npm-shrinkwrap.json":
+		return DependencyFormatPackageLock, nil
+	case base == "yarn.lock":
+		return DependencyFormatYarnLock, nil
+	case base == "poetry.lock":
+		return DependencyFormatPoetryLock, nil
+	case strings.HasSuffix(base, ".txt") && strings.Contains(base, "requirements"):
+		return DependencyFormatRequirements, nil
+	}
+
+	var doc struct {
+		BOMFormat       string          `json:"bomFormat"`
+		SPDXVersion     string          `json:"spdxVersion"`
+		LockfileVersion json.RawMessage `json:"lockfileVersion"`
+	}
+	if err := json.Unmarshal(content, &doc); err == nil {
+		switch {
+		case strings.EqualFold(doc.BOMFormat, "CycloneDX"):
+			return DependencyFormatCycloneDX, nil
+		case doc.SPDXVersion != "":
+			return DependencyFormatSPDX, nil
+		case len(doc.LockfileVersion) > 0:
+			return DependencyFormatPackageLock, nil
+		}
+	}
+	return "", fmt.Errorf("unable to detect dependency file format of %q", filename)
+}
+
+type packageLockDependency struct {
+	Version      string                           `json:"version"`
+	Dependencies map[string]packageLockDependency `json:"dependencies"`
+}
+
+// parsePackageLock parses npm package-lock.json and npm-shrinkwrap.json files.
+// Lockfile versions 2 and 3 use the "packages" key, while version 1 only has
+// the nested "dependencies" key.
+func parsePackageLock(content []byte) ([]dependency, error) {
+	var lock struct {
+		Packages map[string]struct {
+			Name    string `json:"name"`
+			Version string `json:"version"`
+			Link    bool   `json:"link"`
+		} `json:"packages"`
+		Dependencies map[string]packageLockDependency `json:"dependencies"`
+	}
+	if err := json.Unmarshal(content, &lock); err != nil {
+		return nil, fmt.Errorf("parsing package-lock.json: %w", err)
+	}
+
+	var deps []dependency
+	if len(lock.Packages) > 0 {
+		for location, pkg := range lock.Packages {
+			idx := strings.LastIndex(location, "node_modules/")
+			if location == "" || idx < 0 || pkg.Link || pkg.Version == "" {
+				// the root project, workspace packages and links are not dependencies
+				continue
+			}
+			name := location[idx+len("node_modules/"):]
+			if pkg.Name != "" {
+				name = pkg.Name
+			}
+			deps = append(deps, dependency{Ecosystem: DependencyEcosystemNPM, Name: name, Version: pkg.Version})
+		}
+		return deps, nil
+	}
+
+	var walk func(map[string]packageLockDependency)
+	walk = func(m map[string]packageLockDependency) {
+		for name, dep := range m {
+			if dep.Version != "" && !strings.Contains(dep.Version, ":") {
+				deps = append(deps, dependency{Ecosystem: DependencyEcosystemNPM, Name: name, Version: dep.Version})
+			}
+			walk(dep.Dependencies)
+		}
+	}
+	walk(lock.Dependencies)
+	return deps, nil
+}
+
+// parseYarnLock parses both classic (v1) and berry (v2+) yarn.lock files.
+// Each entry starts with an unindented line of comma-separated specifiers
+// followed by an indented "version" field.
+func parseYarnLock(content []byte) ([]dependency, error) {
+	var (
+		deps    []dependency
+		current string
+	)
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	for scanner.Scan() {
+		line := scanner.Text()
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" || strings.HasPrefix(trimmed, "#") {
+			continue
+		}
+
+		if !strings.HasPrefix(line, " ") {
+			current = ""
+			spec := strings.TrimSuffix(trimmed, ":")
+			spec = strings.Trim(strings.TrimSpace(strings.Split(spec, ",")[0]), `"`)
+			if spec == "__metadata" || len(spec) < 2 {
+				continue
+			}
+			idx := strings.Index(spec[1:], "@")
+			if idx < 0 {
+				continue
+			}
+			name, protocol := spec[:idx+1], spec[idx+2:]
+			if strings.HasPrefix(protocol, "workspace:") ||
+				strings.HasPrefix(protocol, "link:") ||
+				strings.HasPrefix(protocol, "portal:") ||
+				strings.HasPrefix(protocol, "file:") {
+				continue
+			}
+			current = name
+			continue
+		}
+
+		if current == "" {
+			continue
+		}
+		field, value, ok := strings.Cut(trimmed, " ")
+		if !ok || strings.TrimSuffix(field, ":") != "version" {
+			continue
+		}
+		deps = append(deps, dependency{
+			Ecosystem: DependencyEcosystemNPM,
+			Name:      current,
+			Version:   strings.Trim(strings.TrimSpace(value), `"`),
+		})
+		current = ""
+	}
+	return deps, scanner.Err()
+}
+
+// parsePoetryLock parses the [[package]] tables of a poetry.lock file.
+// Only the top level keys of each table are read, so the keys of
+// nested tables such as [package.dependencies] are ignored.
+func parsePoetryLock(content []byte) ([]dependency, error) {
+	var (
+		deps     []dependency
+		current  *dependency
+		inPkgTbl bool
+	)
+	flush := func() {
+		if current != nil && current.Name != "" && current.Version != "" {
+			deps = append(deps, *current)
+		}
+		current = nil
+	}
+
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		switch {
+		case line == "[[package]]":
+			flush()
+			current = &dependency{Ecosystem: DependencyEcosystemPyPI}
+			inPkgTbl = true
+			continue
+		case strings.HasPrefix(line, "["):
+			inPkgTbl = false
+			continue
+		case !inPkgTbl || current == nil:
+			continue
+		}
+
+		key, value, ok := strings.Cut(line, "=")
+		if !ok {
+			continue
+		}
+		value = strings.Trim(strings.TrimSpace(value), `"'`)
+		switch strings.TrimSpace(key) {
+		case "name":
+			current.Name = value
+		case "version":
+			current.Version = value
+		}
+	}
+	flush()
+	return deps, scanner.Err()
+}
+
+// parseRequirements parses a pip requirements file, only including
+// requirements pinned to an exact version using '==' or '==='.
+func parseRequirements(content []byte) ([]dependency, error) {
+	var (
+		deps    []dependency
+		logical string
+	)
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	for scanner.Scan() {
+		line := scanner.Text()
+		if strings.HasSuffix(line, `\`) {
+			logical += strings.TrimSuffix(line, `\`) + " "
+			continue
+		}
+		line = logical + line
+		logical = ""
+
+		if idx := strings.Index(line, " #"); idx >= 0 {
+			line = line[:idx]
+		}
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") || strings.HasPrefix(line, "-") {
+			continue
+		}
+		// drop environment markers and per-requirement options such as --hash
+		line, _, _ = strings.Cut(line, ";")
+		line, _, _ = strings.Cut(line, " --")
+
+		name, version, ok := strings.Cut(line, "===")
+		if !ok {
+			name, version, ok = strings.Cut(line, "==")
+		}
+		if !ok || strings.ContainsAny(version, ",<>!~*") {
+			continue
+		}
+		name, _, _ = strings.Cut(name, "[")
+		deps = append(deps, dependency{
+			Ecosystem: DependencyEcosystemPyPI,
+			Name:      strings.TrimSpace(name),
+			Version:   strings.TrimSpace(version),
+		})
+	}
+	return deps, scanner.Err()
+}
+
+type cycloneDXComponent struct {
+	Name       string               `json:"name"`
+	Version    string               `json:"version"`
+	PURL       string               `json:"purl"`
+	Components []cycloneDXComponent `json:"components"`
+}
+
+// parseCycloneDX parses a CycloneDX JSON SBOM, using the package URL
+// of each (possibly nested) component to determine its ecosystem.
+func parseCycloneDX(content []byte) ([]dependency, error) {
+	var bom struct {
+		Components []cycloneDXComponent `json:"components"`
+	}
+	if err := json.Unmarshal(content, &bom); err != nil {
+		return nil, fmt.Errorf("parsing CycloneDX SBOM: %w", err)
+	}
+
+	var (
+		deps []dependency
+		walk func([]cycloneDXComponent)
+	)
+	walk = func(components []cycloneDXComponent) {
+		for _, c := range components {
+			if dep, ok := dependencyFromPURL(c.PURL); ok {
+				deps = append(deps, dep)
+			}
+			walk(c.Components)
+		}
+	}
+	walk(bom.Components)
+	return deps, nil
+}
+
+// parseSPDX parses an SPDX JSON SBOM, using the package URL external
+// reference of each package to determine its ecosystem.
+func parseSPDX(content []byte) ([]dependency, error) {
+	var doc struct {
+		Packages []struct {
+			ExternalRefs []struct {
+				ReferenceCategory string `json:"referenceCategory"`
+				ReferenceType     string `json:"referenceType"`
+				ReferenceLocator  string `json:"referenceLocator"`
+			} `json:"externalRefs"`
+		} `json:"packages"`
+	}
+	if err := json.Unmarshal(content, &doc); err != nil {
+		return nil, fmt.Errorf("parsing SPDX SBOM: %w", err)
+	}
+
+	var deps []dependency
+	for _, pkg := range doc.Packages {
+		for _, ref := range pkg.ExternalRefs {
+			if ref.ReferenceType != "purl" {
+				continue
+			}
+			if dep, ok := dependencyFromPURL(ref.ReferenceLocator); ok {
+				deps = append(deps, dep)
+				break
+			}
+		}
+	}
+	return deps, nil
+}
+
+// dependencyFromPURL converts a package URL (https://github.com/package-url/purl-spec)
+// into a dependency. Only npm, pypi, docker and oci package types are supported.
+func dependencyFromPURL(purl string) (dependency, bool) {
+	rest, ok := strings.CutPrefix(purl, "pkg:")
+	if !ok {
+		return dependency{}, false
+	}
+	rest, _, _ = strings.Cut(rest, "#")
+	rest, rawQualifiers, _ := strings.Cut(rest, "?")
+	qualifiers, _ := url.ParseQuery(rawQualifiers)
+
+	var version string
+	if idx := strings.LastIndex(rest, "@"); idx >= 0 {
+		version, _ = url.PathUnescape(rest[idx+1:])
+		rest = rest[:idx]
+	}
+
+	segments := strings.Split(strings.Trim(rest, "/"), "/")
+	if len(segments) < 2 || version == "" {
+		return dependency{}, false
+	}
+	for i, s := range segments {
+		segments[i], _ = url.PathUnescape(s)
+	}
+	purlType, nameSegments := strings.ToLower(segments[0]), segments[1:]
+	name := strings.Join(nameSegments, "/")
+
+	switch purlType {
+	case "npm":
+		return dependency{Ecosystem: DependencyEcosystemNPM, Name: name, Version: version}, true
+	case "pypi":
+		return dependency{Ecosystem: DependencyEcosystemPyPI, Name: name, Version: version}, true
+	case "docker":
+		if repositoryURL := qualifiers.Get("repository_url"); repositoryURL != "" {
+			name = strings.TrimSuffix(repositoryURL, "/") + "/" + name
+		}
+		return dependency{Ecosystem: DependencyEcosystemDocker, Name: name, Version: version}, true
+	case "oci":
+		// for oci, the repository_url qualifier contains the full repository including the name
+		if repositoryURL := qualifiers.Get("repository_url"); repositoryURL != "" {
+			name = repositoryURL
+		}
+		return dependency{Ecosystem: DependencyEcosystemDocker, Name: name, Version: version}, true
+	default:
+		return dependency{}, false
+	}
+}
@@ -0,0 +1,299 @@
+// Copyright (C) 2025-2026 Crash Override, Inc.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the FSF, either version 3 of the License, or (at your option) any later version.
+// See the LICENSE file in the root of this repository for full license text or
+// visit: <https://www.gnu.org/licenses/gpl-3.0.html>.
+
+package crawlers
+
+import (
+	"cmp"
+	"slices"
+	"testing"
+)
+
+func sortDependencies(deps []dependency) []dependency {
+	slices.SortFunc(deps, func(a, b dependency) int {
+		return cmp.Or(
+			cmp.Compare(a.Ecosystem, b.Ecosystem),
+			cmp.Compare(a.Name, b.Name),
+			cmp.Compare(a.Version, b.Version),
+		)
+	})
+	return deps
+}
+
+func TestDetectDependencyFormat(t *testing.T) {
+	tests := []struct {
+		filename string
+		content  string
+		want     string
+		wantErr  bool
+	}{
+		{filename: "app/package-lock.json", want: DependencyFormatPackageLock},
+		{filename: "npm-shrinkwrap.json", want: DependencyFormatPackageLock},
+		{filename: "yarn.lock", want: DependencyFormatYarnLock},
+		{filename: "poetry.lock", want: DependencyFormatPoetryLock},
+		{filename: "requirements-dev.txt", want: DependencyFormatRequirements},
+		{filename: "bom.json", content: `{"bomFormat": "CycloneDX"}`, want: DependencyFormatCycloneDX},
+		{filename: "sbom.json", content: `{"spdxVersion": "SPDX-2.3"}`, want: DependencyFormatSPDX},
+		{filename: "lock.json", content: `{"lockfileVersion": 3}`, want: DependencyFormatPackageLock},
+		{filename: "notes.txt", content: "django==4.2", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.filename, func(t *testing.T) {
+			got, err := detectDependencyFormat(tt.filename, []byte(tt.content))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("got error %v, want error %t", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDependencyParsers(t *testing.T) {
+	tests := []struct {
+		name    string
+		format  string
+		content string
+		want    []dependency
+		wantErr bool
+	}{
+		{
+			name:   "package-lock v3",
+			format: DependencyFormatPackageLock,
+			content: `{
+  "lockfileVersion": 3,
+  "packages": {
+    "": {"name": "app", "version": "1.0.0"},
+    "node_modules/left-pad": {"version": "1.3.0"},
+    "node_modules/@acme/util": {"version": "2.0.0"},
+    "node_modules/a/node_modules/left-pad": {"version": "1.1.0"},
+    "node_modules/alias": {"name": "real-name", "version": "3.0.0"},
+    "node_modules/linked": {"resolved": "packages/linked", "link": true},
+    "packages/linked": {"version": "0.1.0"}
+  }
+}`,
+			want: []dependency{
+				{Ecosystem: DependencyEcosystemNPM, Name: "@acme/util", Version: "2.0.0"},
+				{Ecosystem: DependencyEcosystemNPM, Name: "left-pad", Version: "1.1.0"},
+				{Ecosystem: DependencyEcosystemNPM, Name: "left-pad", Version: "1.3.0"},
+				{Ecosystem: DependencyEcosystemNPM, Name: "real-name", Version: "3.0.0"},
+			},
+		},
+		{
+			name:   "package-lock v1",
+			format: DependencyFormatPackageLock,
+			content: `{
+  "lockfileVersion": 1,
+  "dependencies": {
+    "express": {
+      "version": "4.18.2",
+      "dependencies": {"debug": {"version": "2.6.9"}}
+    },
+    "local": {"version": "file:../local"}
+  }
+}`,
+			want: []dependency{
+				{Ecosystem: DependencyEcosystemNPM, Name: "debug", Version: "2.6.9"},
+				{Ecosystem: DependencyEcosystemNPM, Name: "express", Version: "4.18.2"},
+			},
+		},
+		{
+			name:    "invalid package-lock",
+			format:  DependencyFormatPackageLock,
+			content: `{"packages": [`,
+			wantErr: true,
+		},
+		{
+			name:   "yarn.lock v1",
+			format: DependencyFormatYarnLock,
+			content: `# yarn lockfile v1
+
+"@babel/core@^7.0.0", "@babel/core@^7.1.0":
+  version "7.23.0"
+  resolved "https://registry.yarnpkg.com/@babel/core/-/core-7.23.0.tgz"
+
+lodash@^4.17.21:
+  version "4.17.21"
+`,
+			want: []dependency{
+				{Ecosystem: DependencyEcosystemNPM, Name: "@babel/core", Version: "7.23.0"},
+				{Ecosystem: DependencyEcosystemNPM, Name: "lodash", Version: "4.17.21"},
+			},
+		},
+		{
+			name:   "yarn.lock berry",
+			format: DependencyFormatYarnLock,
+			content: `__metadata:
+  version: 6
+
+"app@workspace:.":
+  version: 0.0.0-use.local
+
+"react@npm:^18.2.0":
+  version: 18.2.0
+  dependencies:
+    loose-envify: ^1.1.0
+`,
+			want: []dependency{
+				{Ecosystem: DependencyEcosystemNPM, Name: "react", Version: "18.2.0"},
+			},
+		},
+		{
+			name:   "poetry.lock",
+			format: DependencyFormatPoetryLock,
+			content: `[[package]]
+name = "requests"
+version = "2.31.0"
+description = "Python HTTP for Humans."
+
+[package.dependencies]
+version = "not a package version"
+
+[[package]]
+name = "urllib3"
+version = "2.0.7"
+
+[metadata]
+lock-version = "2.0"
+`,
+			want: []dependency{
+				{Ecosystem: DependencyEcosystemPyPI, Name: "requests", Version: "2.31.0"},
+				{Ecosystem: DependencyEcosystemPyPI, Name: "urllib3", Version: "2.0.7"},
+			},
+		},
+		{
+			name:   "requirements",
+			format: DependencyFormatRequirements,
+			content: `# pinned requirements
+-r base.txt
+django==4.2.7  # web framework
+requests[socks]===2.31.0 ; python_version >= "3.8"
+numpy==1.26.2 \
+    --hash=sha256:abc
+flask>=2.0
+pytest==7.*
+`,
+			want: []dependency{
+				{Ecosystem: DependencyEcosystemPyPI, Name: "django", Version: "4.2.7"},
+				{Ecosystem: DependencyEcosystemPyPI, Name: "numpy", Version: "1.26.2"},
+				{Ecosystem: DependencyEcosystemPyPI, Name: "requests", Version: "2.31.0"},
+			},
+		},
+		{
+			name:   "cyclonedx",
+			format: DependencyFormatCycloneDX,
+			content: `{
+  "bomFormat": "CycloneDX",
+  "components": [
+    {"name": "lodash", "version": "4.17.21", "purl": "pkg:npm/lodash@4.17.21"},
+    {
+      "name": "app",
+      "purl": "pkg:docker/library/nginx@1.25?repository_url=docker.io",
+      "components": [{"purl": "pkg:pypi/django@4.2.7"}]
+    },
+    {"name": "openssl", "purl": "pkg:deb/debian/openssl@3.0.11"},
+    {"name": "no-purl", "version": "1.0.0"}
+  ]
+}`,
+			want: []dependency{
+				{Ecosystem: DependencyEcosystemDocker, Name: "docker.io/library/nginx", Version: "1.25"},
+				{Ecosystem: DependencyEcosystemNPM, Name: "lodash", Version: "4.17.21"},
+				{Ecosystem: DependencyEcosystemPyPI, Name: "django", Version: "4.2.7"},
+			},
+		},
+		{
+			name:   "spdx",
+			format: DependencyFormatSPDX,
+			content: `{
+  "spdxVersion": "SPDX-2.3",
+  "packages": [
+    {"externalRefs": [
+      {"referenceCategory": "SECURITY", "referenceType": "cpe23Type", "referenceLocator": "cpe:2.3:a:acme:x"},
+      {"referenceCategory": "PACKAGE-MANAGER", "referenceType": "purl",
+       "referenceLocator": "pkg:npm/%40acme/util@2.0.0"}
+    ]},
+    {"externalRefs": [
+      {"referenceType": "purl",
+       "referenceLocator": "pkg:oci/app@sha256%3Aabc?repository_url=ghcr.io/acme/app"}
+    ]},
+    {"externalRefs": []}
+  ]
+}`,
+			want: []dependency{
+				{Ecosystem: DependencyEcosystemDocker, Name: "ghcr.io/acme/app", Version: "sha256:abc"},
+				{Ecosystem: DependencyEcosystemNPM, Name: "@acme/util", Version: "2.0.0"},
+			},
+		},
+		{
+			name:    "invalid spdx",
+			format:  DependencyFormatSPDX,
+			content: `[]`,
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := dependencyParsers[tt.format]([]byte(tt.content))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("got error %v, want error %t", err, tt.wantErr)
+			}
+			if got = sortDependencies(got); !slices.Equal(got, tt.want) {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDependencyFromPURL(t *testing.T) {
+	tests := []struct {
+		purl   string
+		want   dependency
+		wantOK bool
+	}{
+		{
+			purl:   "pkg:npm/%40scope/name@1.0.0",
+			want:   dependency{Ecosystem: DependencyEcosystemNPM, Name: "@scope/name", Version: "1.0.0"},
+			wantOK: true,
+		},
+		{
+			purl:   "pkg:pypi/django@4.2.7#subpath",
+			want:   dependency{Ecosystem: DependencyEcosystemPyPI, Name: "django", Version: "4.2.7"},
+			wantOK: true,
+		},
+		{
+			purl:   "pkg:docker/library/alpine@3.19",
+			want:   dependency{Ecosystem: DependencyEcosystemDocker, Name: "library/alpine", Version: "3.19"},
+			wantOK: true,
+		},
+		{
+			purl:   "pkg:docker/acme/app@v1?repository_url=registry.example.com/",
+			want:   dependency{Ecosystem: DependencyEcosystemDocker, Name: "registry.example.com/acme/app", Version: "v1"},
+			wantOK: true,
+		},
+		{
+			purl:   "pkg:oci/app@sha256%3Aabc?repository_url=ghcr.io/acme/app",
+			want:   dependency{Ecosystem: DependencyEcosystemDocker, Name: "ghcr.io/acme/app", Version: "sha256:abc"},
+			wantOK: true,
+		},
+		{purl: "pkg:npm/lodash"},
+		{purl: "pkg:golang/github.com/acme/x@v1.0.0"},
+		{purl: "npm/lodash@4.17.21"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.purl, func(t *testing.T) {
+			got, ok := dependencyFromPURL(tt.purl)
+			if ok != tt.wantOK || got != tt.want {
+				t.Errorf("got (%v, %t), want (%v, %t)", got, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+}
@@ -0,0 +1,207 @@
+// Copyright (C) 2025-2026 Crash Override, Inc.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the FSF, either version 3 of the License, or (at your option) any later version.
+// See the LICENSE file in the root of this repository for full license text or
+// visit: <https://www.gnu.org/licenses/gpl-3.0.html>.
+
+package crawlers
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/crashappsec/ocular-default-integrations/pkg/clients/npm"
+	"github.com/crashappsec/ocular-default-integrations/pkg/definitions"
+	"github.com/crashappsec/ocular/api/v1beta1"
+	"github.com/hashicorp/go-multierror"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+const (
+	NPMScopesParamName      = "NPM_SCOPES"
+	NPMMaintainersParamName = "NPM_MAINTAINERS"
+	NPMRegistryURLParamName = "NPM_REGISTRY_URL"
+	VersionLimitParamName   = "VERSION_LIMIT"
+
+	NPMTokenSecretEnvVar = "NPM_TOKEN"
+)
+
+func init() {
+	All.Register(NPM)
+}
+
+var NPM = Crawler{
+	Name: "npm",
+	Parameters: []v1beta1.ParameterDefinition{
+		{
+			Name:        NPMScopesParamName,
+			Description: "Comma-separated list of npm scopes (e.g. '@acme') whose packages will be crawled.",
+			Default:     ptr.To(""),
+		},
+		{
+			Name:        NPMMaintainersParamName,
+			Description: "Comma-separated list of npm usernames. All packages maintained by these users will be crawled.",
+			Default:     ptr.To(""),
+		},
+		{
+			Name: NPMRegistryURLParamName,
+			Description: "Base URL of the npm registry to search. The registry must implement the " +
+				"'/-/v1/search' API. The token in the 'npm-token' secret is sent as a bearer token if set.",
+			Default: ptr.To(npm.DefaultRegistryURL),
+		},
+		{
+			Name: VersionLimitParamName,
+			Description: "Maximum number of versions to retrieve per package. " +
+				"A value of 1 will only retrieve the version of the 'latest' dist-tag, " +
+				"otherwise the N most recently published versions are retrieved and a new pipeline is started for each. " +
+				"Set to 0 to retrieve all versions. Defaults to 1.",
+			Default: ptr.To("1"),
+		},
+	},
+	EnvironmentSecrets: []definitions.EnvironmentSecret{
+		{
+			SecretKey:  "npm-token",
+			EnvVarName: NPMTokenSecretEnvVar,
+		},
+	},
+	Crawl: crawlNPM,
+}
+
+// crawlNPM searches the npm registry for all packages in the given scopes or
+// maintained by the given users and sends the package names with their most recent
+// versions to the queue. The targets are intended to be used with the "npm" downloader.
+func crawlNPM(ctx context.Context, params map[string]string, queue chan v1beta1.Target) error {
+	l := log.FromContext(ctx).WithValues("crawler", "npm")
+
+	scopes := splitParamList(params[NPMScopesParamName])
+	maintainers := splitParamList(params[NPMMaintainersParamName])
+	if len(scopes) == 0 && len(maintainers) == 0 {
+		return fmt.Errorf("no npm scopes or maintainers specified")
+	}
+
+	limit, err := strconv.Atoi(params[VersionLimitParamName])
+	if err != nil {
+		l.Error(err, "invalid value for limit, defaulting to 1", "limit", VersionLimitParamName)
+		limit = 1
+	}
+
+	client := npm.NewClient(npm.Options{
+		RegistryURL: params[NPMRegistryURLParamName],
+		AuthToken:   os.Getenv(NPMTokenSecretEnvVar),
+	})
+
+	var (
+		merr     *multierror.Error
+		packages = make(map[string]npm.SearchPackage)
+	)
+	for _, scope := range scopes {
+		scope = strings.TrimPrefix(scope, "@")
+		l.Info("searching npm scope", "scope", scope)
+		results, err := client.SearchPackages(ctx, "scope:"+scope)
+		if err != nil {
+			l.Error(err, "error searching npm scope", "scope", scope)
+			merr = multierror.Append(merr, err)
+			continue
+		}
+		for _, pkg := range results {
+			// search is fuzzy, ensure only packages from the scope are included
+			if strings.HasPrefix(pkg.Name, "@"+scope+"/") {
+				packages[pkg.Name] = pkg
+			}
+		}
+	}
+
+	for _, maintainer := range maintainers {
+		l.Info("searching npm maintainer", "maintainer", maintainer)
+		results, err := client.SearchPackages(ctx, "maintainer:"+maintainer)
+		if err != nil {
+			l.Error(err, "error searching npm maintainer", "maintainer", maintainer)
+			merr = multierror.Append(merr, err)
+			continue
+		}
+		for _, pkg := range results {
+			if slices.ContainsFunc(pkg.Maintainers, func(m npm.Maintainer) bool {
+				return strings.EqualFold(m.Username, maintainer)
+			}) {
+				packages[pkg.Name] = pkg
+			}
+		}
+	}
+
+	l.Info(fmt.Sprintf("found %d npm packages", len(packages)), "packages", len(packages))
+	for _, name := range slices.Sorted(maps.Keys(packages)) {
+		pkg := packages[name]
+		versions := []string{pkg.Version}
+		if limit != 1 {
+			versions, err = getRecentNPMVersions(ctx, client, name, limit)
+			if err != nil {
+				l.Error(err, "error retrieving package versions", "package", name)
+				merr = multierror.Append(merr, err)
+				continue
+			}
+		}
+
+		for _, version := range versions {
+			l.Info("queuing target", "package", name, "version", version)
+			queue <- v1beta1.Target{
+				Identifier: name,
+				Version:    version,
+			}
+		}
+	}
+
+	return merr.ErrorOrNil()
+}
+
+// getRecentNPMVersions returns the versions of the package ordered by
+// publish time, newest first, truncated to limit if limit is greater than 0.
+func getRecentNPMVersions(ctx context.Context, client npm.Client, packageName string, limit int) ([]string, error) {
+	packument, err := client.GetPackument(ctx, packageName)
+	if err != nil {
+		return nil, err
+	}
+
+	type publishedVersion struct {
+		version     string
+		publishedAt time.Time
+	}
+	var published []publishedVersion
+	for version := range packument.Versions {
+		publishedAt, ok := packument.PublishTime(version)
+		if !ok {
+			continue
+		}
+		published = append(published, publishedVersion{version, publishedAt})
+	}
+
+	if len(published) == 0 {
+		// registry did not return publish times, fall back to the latest dist-tag
+		latest, ok := packument.DistTags["latest"]
+		if !ok {
+			return nil, fmt.Errorf("no publish times or latest dist-tag found for package %s", packageName)
+		}
+		return []string{latest}, nil
+	}
+
+	slices.SortFunc(published, func(a, b publishedVersion) int {
+		return b.publishedAt.Compare(a.publishedAt)
+	})
+	if limit > 0 && len(published) > limit {
+		published = published[:limit]
+	}
+
+	versions := make([]string, 0, len(published))
+	for _, p := range published {
+		versions = append(versions, p.version)
+	}
+	return versions, nil
+}
@@ -0,0 +1,200 @@
+// Copyright (C) 2025-2026 Crash Override, Inc.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the FSF, either version 3 of the License, or (at your option) any later version.
+// See the LICENSE file in the root of this repository for full license text or
+// visit: <https://www.gnu.org/licenses/gpl-3.0.html>.
+
+package crawlers
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/crashappsec/ocular-default-integrations/pkg/clients/pypi"
+	"github.com/crashappsec/ocular-default-integrations/pkg/definitions"
+	"github.com/crashappsec/ocular-default-integrations/pkg/input"
+	"github.com/crashappsec/ocular/api/v1beta1"
+	"github.com/hashicorp/go-multierror"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+const (
+	PyPIUsersParamName         = "PYPI_USERS"
+	PyPIOrganizationsParamName = "PYPI_ORGANIZATIONS"
+	PyPIIndexURLParamName      = "PYPI_INDEX_URL"
+	PyPISkipYankedParamName    = "SKIP_YANKED"
+
+	PyPIIndexUsernameSecretEnvVar = "PYPI_INDEX_USERNAME"
+	PyPIIndexPasswordSecretEnvVar = "PYPI_INDEX_PASSWORD"
+)
+
+func init() {
+	All.Register(PyPI)
+}
+
+var PyPI = Crawler{
+	Name: "pypi",
+	Parameters: []v1beta1.ParameterDefinition{
+		{
+			Name:        PyPIUsersParamName,
+			Description: "Comma-separated list of PyPI usernames. All projects the users are collaborators on will be crawled.",
+			Default:     ptr.To(""),
+		},
+		{
+			Name: PyPIOrganizationsParamName,
+			Description: "Comma-separated list of PyPI organization names. " +
+				"All projects owned by the organizations will be crawled.",
+			Default: ptr.To(""),
+		},
+		{
+			Name: PyPIIndexURLParamName,
+			Description: "Base URL of a package index implementing the PEP 691 JSON simple API. " +
+				"If no users or organizations are given, every project in the index is crawled, " +
+				"which is only allowed for private indexes. Credentials in the 'pypi-index-username' and " +
+				"'pypi-index-password' secrets are sent as basic auth if set.",
+			Default: ptr.To(pypi.DefaultIndexURL),
+		},
+		{
+			Name: VersionLimitParamName,
+			Description: "Maximum number of versions to retrieve per project. " +
+				"Will retrieve the N most recently uploaded releases for each project and start a new pipeline for each. " +
+				"Set to 0 to retrieve all versions. Defaults to 1.",
+			Default: ptr.To("1"),
+		},
+		{
+			Name:        PyPISkipYankedParamName,
+			Description: "If true, releases that have been yanked will be skipped.",
+			Default:     ptr.To("true"),
+		},
+	},
+	EnvironmentSecrets: []definitions.EnvironmentSecret{
+		{
+			SecretKey:  "pypi-index-username",
+			EnvVarName: PyPIIndexUsernameSecretEnvVar,
+		},
+		{
+			SecretKey:  "pypi-index-password",
+			EnvVarName: PyPIIndexPasswordSecretEnvVar,
+		},
+	},
+	Crawl: crawlPyPI,
+}
+
+// crawlPyPI discovers the projects owned by PyPI users and organizations, or
+// every project in a private index, and sends the project names with their
+// most recent release versions to the queue. The targets are intended to be
+// used with the "pypi" downloader.
+func crawlPyPI(ctx context.Context, params map[string]string, queue chan v1beta1.Target) error {
+	l := log.FromContext(ctx).WithValues("crawler", "pypi")
+
+	users := splitParamList(params[PyPIUsersParamName])
+	orgs := splitParamList(params[PyPIOrganizationsParamName])
+	indexURL := strings.TrimSuffix(params[PyPIIndexURLParamName], "/")
+	skipYanked, err := input.BoolParam(params, PyPISkipYankedParamName, true)
+	if err != nil {
+		return err
+	}
+
+	limit, err := strconv.Atoi(params[VersionLimitParamName])
+	if err != nil {
+		l.Error(err, "invalid value for limit, defaulting to 1", "limit", VersionLimitParamName)
+		limit = 1
+	}
+
+	client := pypi.NewClient(pypi.Options{
+		IndexURL: indexURL,
+		Username: os.Getenv(PyPIIndexUsernameSecretEnvVar),
+		Password: os.Getenv(PyPIIndexPasswordSecretEnvVar),
+	})
+
+	var (
+		merr     *multierror.Error
+		projects []string
+	)
+	addProjects := func(found []string) {
+		for _, p := range found {
+			if !slices.Contains(projects, p) {
+				projects = append(projects, p)
+			}
+		}
+	}
+
+	switch {
+	case len(users) > 0 || len(orgs) > 0:
+		for _, user := range users {
+			l.Info("listing projects for pypi user", "user", user)
+			found, err := client.ListUserProjects(ctx, user)
+			if err != nil {
+				l.Error(err, "error listing projects for user", "user", user)
+				merr = multierror.Append(merr, err)
+				continue
+			}
+			addProjects(found)
+		}
+		for _, org := range orgs {
+			l.Info("listing projects for pypi organization", "organization", org)
+			found, err := client.ListOrganizationProjects(ctx, org)
+			if err != nil {
+				l.Error(err, "error listing projects for organization", "organization", org)
+				merr = multierror.Append(merr, err)
+				continue
+			}
+			addProjects(found)
+		}
+	case indexURL == "" || indexURL == pypi.DefaultIndexURL:
+		return fmt.Errorf("no pypi users or organizations specified, and no private index set")
+	default:
+		l.Info("listing all projects in index", "index", indexURL)
+		found, err := client.ListIndexProjects(ctx)
+		if err != nil {
+			return fmt.Errorf("error listing projects in index %s: %w", indexURL, err)
+		}
+		addProjects(found)
+	}
+
+	l.Info(fmt.Sprintf("found %d pypi projects", len(projects)), "projects", len(projects))
+	for _, project := range projects {
+		releases, err := client.ListProjectReleases(ctx, project)
+		if err != nil {
+			l.Error(err, "error listing releases for project", "project", project)
+			merr = multierror.Append(merr, err)
+			continue
+		}
+
+		for _, release := range latestPyPIReleases(releases, skipYanked, limit) {
+			l.Info("queuing target", "project", project, "version", release.Version)
+			queue <- v1beta1.Target{
+				Identifier: project,
+				Version:    release.Version,
+			}
+		}
+	}
+
+	return merr.ErrorOrNil()
+}
+
+// latestPyPIReleases orders releases from the most recently uploaded, and returns at most
+// limit of them (all if limit is 0 or less). Upload times are optional (PEP 700), so releases
+// uploaded at the same time or without an upload time are ordered by version, highest first.
+func latestPyPIReleases(releases []pypi.Release, skipYanked bool, limit int) []pypi.Release {
+	if skipYanked {
+		releases = slices.DeleteFunc(releases, func(r pypi.Release) bool {
+			return r.Yanked
+		})
+	}
+	slices.SortFunc(releases, func(a, b pypi.Release) int {
+		return cmp.Or(b.UploadTime.Compare(a.UploadTime), pypi.CompareVersions(b.Version, a.Version))
+	})
+	if limit > 0 && len(releases) > limit {
+		releases = releases[:limit]
+	}
+	return releases
+}
@@ -0,0 +1,101 @@
+// Copyright (C) 2025-2026 Crash Override, Inc.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the FSF, either version 3 of the License, or (at your option) any later version.
+// See the LICENSE file in the root of this repository for full license text or
+// visit: <https://www.gnu.org/licenses/gpl-3.0.html>.
+
+package crawlers
+
+import (
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/crashappsec/ocular-default-integrations/pkg/clients/pypi"
+)
+
+func TestLatestPyPIReleases(t *testing.T) {
+	uploaded := func(day int) time.Time {
+		return time.Date(2025, time.January, day, 0, 0, 0, 0, time.UTC)
+	}
+
+	tests := []struct {
+		name       string
+		releases   []pypi.Release
+		skipYanked bool
+		limit      int
+		want       []string
+	}{
+		{
+			name: "most recently uploaded first",
+			releases: []pypi.Release{
+				{Version: "1.0", UploadTime: uploaded(1)},
+				{Version: "2.0", UploadTime: uploaded(3)},
+				{Version: "1.1", UploadTime: uploaded(5)},
+			},
+			want: []string{"1.1", "2.0", "1.0"},
+		},
+		{
+			name: "no upload times are ordered by version",
+			releases: []pypi.Release{
+				{Version: "0.1"},
+				{Version: "1.10"},
+				{Version: "1.9"},
+				{Version: "2.0"},
+				{Version: "2.0rc1"},
+			},
+			want: []string{"2.0", "2.0rc1", "1.10", "1.9", "0.1"},
+		},
+		{
+			name: "no upload times with limit",
+			releases: []pypi.Release{
+				{Version: "0.1"},
+				{Version: "1.10"},
+				{Version: "2.0"},
+			},
+			limit: 1,
+			want:  []string{"2.0"},
+		},
+		{
+			name: "same upload time is ordered by version",
+			releases: []pypi.Release{
+				{Version: "1.0.post1", UploadTime: uploaded(2)},
+				{Version: "1.0", UploadTime: uploaded(2)},
+				{Version: "0.9", UploadTime: uploaded(1)},
+			},
+			want: []string{"1.0.post1", "1.0", "0.9"},
+		},
+		{
+			name: "releases without upload time are last",
+			releases: []pypi.Release{
+				{Version: "3.0"},
+				{Version: "1.0", UploadTime: uploaded(1)},
+			},
+			want: []string{"1.0", "3.0"},
+		},
+		{
+			name: "yanked releases are skipped",
+			releases: []pypi.Release{
+				{Version: "1.0", UploadTime: uploaded(1)},
+				{Version: "1.1", UploadTime: uploaded(2), Yanked: true},
+			},
+			skipYanked: true,
+			limit:      1,
+			want:       []string{"1.0"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			for _, release := range latestPyPIReleases(tt.releases, tt.skipYanked, tt.limit) {
+				got = append(got, release.Version)
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,216 @@
+// Copyright (C) 2025-2026 Crash Override, Inc.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the FSF, either version 3 of the License, or (at your option) any later version.
+// See the LICENSE file in the root of this repository for full license text or
+// visit: <https://www.gnu.org/licenses/gpl-3.0.html>.
+
+package crawlers
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/crashappsec/ocular-default-integrations/pkg/definitions"
+	"github.com/crashappsec/ocular-default-integrations/pkg/input"
+	"github.com/crashappsec/ocular/api/v1beta1"
+	"github.com/google/go-containerregistry/pkg/authn"
+	"github.com/google/go-containerregistry/pkg/name"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+const (
+	ExpandPlatformsParamName = "EXPAND_PLATFORMS"
+	PinDigestParamName       = "PIN_DIGEST"
+
+	RegistryDockerConfigFolder = "/ocular/docker"
+
+	// registryAllPlatforms is the value of [ExpandPlatformsParamName]
+	// to expand a multi-platform tag to all of its platforms.
+	registryAllPlatforms = "all"
+)
+
+// registryParameters are the parameters shared by all container registry crawlers.
+var registryParameters = []v1beta1.ParameterDefinition{
+	{
+		Name: ExpandPlatformsParamName,
+		Description: "Comma-separated list of platforms (e.g. 'linux/amd64,linux/arm64') to expand multi-platform tags to, " +
+			"or 'all' for every platform. For each matching platform of a tag, a target is emitted with the version " +
+			"'<tag>@<platform manifest digest>'. Attestation manifests are always skipped. " +
+			"If empty, each tag is emitted once and the downloader pulls the platform given by its PLATFORM parameter.",
+		Default: ptr.To(""),
+	},
+	{
+		Name: PinDigestParamName,
+		Description: "If true, each tag is resolved to the digest of its manifest when crawled, and the target " +
+			"version is '<tag>@<manifest digest>', so that the downloader pulls the image that was crawled " +
+			"even if the tag is moved before the pipeline runs.",
+		Default: ptr.To("false"),
+	},
+}
+
+// registryFileSecrets mounts a docker config file, used to authenticate to
+// registries when resolving manifests if a crawler has no other credentials for it.
+var registryFileSecrets = []definitions.FileSecret{
+	{
+		SecretKey: "dockerconfig",
+		MountPath: RegistryDockerConfigFolder + "/config.json",
+	},
+}
+
+var registryEnvironmentVariables = []corev1.EnvVar{
+	{
+		Name:  "DOCKER_CONFIG",
+		Value: RegistryDockerConfigFolder,
+	},
+}
+
+// registryTargetResolver converts the tags found by a registry crawler into targets,
+// resolving multi-platform tags to their platform manifests if configured.
+type registryTargetResolver struct {
+	platforms    []v1.Platform
+	allPlatforms bool
+	pinDigest    bool
+	opts         []remote.Option
+}
+
+// newRegistryTargetResolver parses the shared registry parameters. If auth is nil,
+// registries are authenticated to using the mounted docker config, if any.
+func newRegistryTargetResolver(
+	ctx context.Context,
+	params map[string]string,
+	auth authn.Authenticator,
+) (*registryTargetResolver, error) {
+	pinDigest, err := input.BoolParam(params, PinDigestParamName, false)
+	if err != nil {
+		return nil, err
+	}
+	r := &registryTargetResolver{
+		pinDigest: pinDigest,
+		opts:      []remote.Option{remote.WithContext(ctx), remote.WithAuthFromKeychain(authn.DefaultKeychain)},
+	}
+	if auth != nil {
+		r.useAuth(auth)
+	}
+
+	for _, platform := range splitParamList(params[ExpandPlatformsParamName]) {
+		if strings.EqualFold(platform, registryAllPlatforms) {
+			r.allPlatforms = true
+			continue
+		}
+		p, err := v1.ParsePlatform(platform)
+		if err != nil {
+			return nil, fmt.Errorf("invalid value for %s: %w", ExpandPlatformsParamName, err)
+		}
+		r.platforms = append(r.platforms, *p)
+	}
+	return r, nil
+}
+
+// useAuth sets the credentials used to authenticate to the registry,
+// instead of the mounted docker config.
+func (r *registryTargetResolver) useAuth(auth authn.Authenticator) {
+	r.opts[1] = remote.WithAuth(auth)
+}
+
+func (r *registryTargetResolver) expandsPlatforms() bool {
+	return r.allPlatforms || len(r.platforms) > 0
+}
+
+// accessesRegistry reports whether resolving targets requests the registry,
+// so crawlers only need to retrieve registry credentials if it does.
+func (r *registryTargetResolver) accessesRegistry() bool {
+	return r.expandsPlatforms() || r.pinDigest
+}
+
+// targets returns the targets for a tag of the repository. If platform expansion
+// is enabled and the tag is an index, one target is returned for each platform
+// manifest matching the allow-list. Otherwise the tag itself is returned,
+// pinned to the digest of its manifest if enabled.
+func (r *registryTargetResolver) targets(ctx context.Context, repository, tag string) ([]v1beta1.Target, error) {
+	if !r.accessesRegistry() {
+		return []v1beta1.Target{{Identifier: repository, Version: tag}}, nil
+	}
+
+	l := log.FromContext(ctx)
+	ref, err := name.NewTag(repository + ":" + tag)
+	if err != nil {
+		return nil, err
+	}
+	if !r.expandsPlatforms() {
+		// only the digest is needed, which a HEAD request returns
+		// without counting towards the pull rate limit of some registries
+		desc, err := remote.Head(ref, r.opts...)
+		if err != nil {
+			return nil, fmt.Errorf("retrieving digest of %s: %w", ref, err)
+		}
+		return []v1beta1.Target{{Identifier: repository, Version: tag + "@" + desc.Digest.String()}}, nil
+	}
+
+	desc, err := remote.Get(ref, r.opts...)
+	if err != nil {
+		return nil, fmt.Errorf("retrieving manifest of %s: %w", ref, err)
+	}
+	if !desc.MediaType.IsIndex() {
+		version := tag
+		if r.pinDigest {
+			version += "@" + desc.Digest.String()
+		}
+		return []v1beta1.Target{{Identifier: repository, Version: version}}, nil
+	}
+
+	index, err := desc.ImageIndex()
+	if err != nil {
+		return nil, err
+	}
+	manifest, err := index.IndexManifest()
+	if err != nil {
+		return nil, fmt.Errorf("reading index manifest of %s: %w", ref, err)
+	}
+
+	var targets []v1beta1.Target
+	for _, m := range manifest.Manifests {
+		if !r.includesPlatform(m) {
+			continue
+		}
+		l.V(1).Info("expanded platform of tag", "image", ref.String(), "platform", m.Platform.String())
+		targets = append(targets, v1beta1.Target{
+			Identifier: repository,
+			Version:    tag + "@" + m.Digest.String(),
+		})
+	}
+	if len(targets) == 0 {
+		l.Info("no manifests of index matched the platform allow-list", "image", ref.String())
+	}
+	return targets, nil
+}
+
+// includesPlatform reports whether the manifest of an index is an image for
+// one of the allowed platforms. Attestation manifests, which buildkit
+// stores with the platform 'unknown/unknown', are never included.
+func (r *registryTargetResolver) includesPlatform(m v1.Descriptor) bool {
+	if m.Platform == nil || m.Platform.OS == "unknown" || m.Platform.Architecture == "unknown" {
+		return false
+	}
+	if m.Annotations["vnd.docker.reference.type"] == "attestation-manifest" {
+		return false
+	}
+	if !m.MediaType.IsImage() {
+		return false
+	}
+	if r.allPlatforms {
+		return true
+	}
+	for _, platform := range r.platforms {
+		if m.Platform.Satisfies(platform) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,91 @@
+// Copyright (C) 2025-2026 Crash Override, Inc.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the FSF, either version 3 of the License, or (at your option) any later version.
+// See the LICENSE file in the root of this repository for full license text or
+// visit: <https://www.gnu.org/licenses/gpl-3.0.html>.
+
+package crawlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"syscall"
+
+	"github.com/crashappsec/ocular-default-integrations/pkg/input"
+	"github.com/crashappsec/ocular/api/v1beta1"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// Run runs the crawler of the registry selected by the environment of the search
+// container, writing each target it discovers to the pipeline FIFO. An error is
+// returned if the crawler cannot be started or fails, in which case the targets
+// discovered before it failed are still written to the FIFO.
+func Run(ctx context.Context, registry Registry) error {
+	logger := log.FromContext(ctx)
+
+	searchName := os.Getenv(v1beta1.EnvVarSearchName)
+	if searchName == "" {
+		return fmt.Errorf("%s environment variable not set", v1beta1.EnvVarSearchName)
+	}
+
+	crawlerName := strings.TrimPrefix(os.Getenv(v1beta1.EnvVarCrawlerName), "ocular-defaults-")
+	if crawlerName == "" {
+		return fmt.Errorf("%s environment variable not set", v1beta1.EnvVarCrawlerName)
+	}
+
+	if crawlerOverride := os.Getenv("OCULAR_CRAWLER_NAME_OVERRIDE"); crawlerOverride != "" {
+		crawlerName = crawlerOverride
+	}
+
+	crawler, found := registry[crawlerName]
+	if !found {
+		return fmt.Errorf("unknown crawler %s", crawlerName)
+	}
+
+	params, err := input.ParseParamsFromEnv(crawler.Parameters)
+	if err != nil {
+		return fmt.Errorf("unable to parse parameters from environment: %w", err)
+	}
+
+	fifo, err := os.OpenFile(os.Getenv(v1beta1.EnvVarPipelineFIFO), syscall.O_WRONLY, os.ModeNamedPipe)
+	if err != nil {
+		return fmt.Errorf("unable to open pipeline FIFO: %w", err)
+	}
+	logger = logger.WithValues("crawler", crawler.Name, "params", params)
+	logger.Info("executing crawler")
+
+	var (
+		queue    = make(chan v1beta1.Target)
+		crawlErr error
+	)
+
+	go func() {
+		defer close(queue)
+		crawlErr = crawler.Crawl(ctx, params, queue)
+	}()
+
+	logger.Info("awaiting target discovery")
+	encoder := json.NewEncoder(fifo)
+	for {
+		target, ok := <-queue
+		if !ok {
+			logger.Info("queue closed, exiting")
+			break
+		}
+		err := encoder.Encode(&target)
+		if err != nil {
+			logger.Error(err, "unable to encode target JSON", "target", target)
+		}
+	}
+
+	if crawlErr != nil {
+		return fmt.Errorf("error running crawler: %w", crawlErr)
+	}
+	logger.Info("search finished successfully")
+	return nil
+}
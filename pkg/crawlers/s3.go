@@ -0,0 +1,176 @@
+// Copyright (C) 2025-2026 Crash Override, Inc.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the FSF, either version 3 of the License, or (at your option) any later version.
+// See the LICENSE file in the root of this repository for full license text or
+// visit: <https://www.gnu.org/licenses/gpl-3.0.html>.
+
+package crawlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+
+	s3Service "github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/smithy-go"
+	"github.com/crashappsec/ocular-default-integrations/pkg/clients/aws"
+	"github.com/crashappsec/ocular-default-integrations/pkg/input"
+	"github.com/crashappsec/ocular/api/v1beta1"
+	"github.com/hashicorp/go-multierror"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+const (
+	S3BucketTagsParamName = "BUCKET_TAGS"
+)
+
+func init() {
+	All.Register(S3)
+}
+
+var S3 = Crawler{
+	Name:        "s3",
+	FileSecrets: aws.FileSecrets,
+	Parameters: append(append(slices.Clone(aws.Parameters), bucketParameters...),
+		v1beta1.ParameterDefinition{
+			Name: S3BucketTagsParamName,
+			Description: "Comma-separated list of resource tags buckets must have to be crawled, " +
+				"either 'key=value' to match a value or 'key' to match any value.",
+			Default: ptr.To(""),
+		},
+	),
+	Crawl: crawlS3,
+}
+
+// crawlS3 lists the S3 buckets visible to the configured credentials and sends
+// each matching bucket, or each of its top-level prefixes, to the queue.
+// The targets are intended to be used with the "s3" downloader.
+func crawlS3(ctx context.Context, params map[string]string, queue chan v1beta1.Target) error {
+	l := log.FromContext(ctx).WithValues("crawler", "s3")
+
+	filter, err := newBucketFilter(params[BucketNamePatternParamName], params[S3BucketTagsParamName])
+	if err != nil {
+		return err
+	}
+	splitByPrefix, err := input.BoolParam(params, SplitByPrefixParamName, false)
+	if err != nil {
+		return err
+	}
+
+	cfg, err := aws.BuildConfig(ctx,
+		aws.WithProfile(params[aws.ProfileParamName]),
+		aws.WithRegionOverride(params[aws.RegionParamName]))
+	if err != nil {
+		return err
+	}
+	client := s3Service.NewFromConfig(cfg)
+
+	var merr *multierror.Error
+	paginator := s3Service.NewListBucketsPaginator(client, &s3Service.ListBucketsInput{})
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			l.Error(err, "error listing S3 buckets")
+			return fmt.Errorf("error listing S3 buckets: %w", err)
+		}
+
+		for _, bucket := range page.Buckets {
+			name := ptr.Deref(bucket.Name, "")
+			if !filter.matchesName(name) {
+				continue
+			}
+
+			// bucket operations must be sent to the region of the bucket
+			withBucketRegion := func(o *s3Service.Options) {
+				if region := ptr.Deref(bucket.BucketRegion, ""); region != "" {
+					o.Region = region
+				}
+			}
+
+			if filter.filtersTags() {
+				tags, err := getS3BucketTags(ctx, client, name, withBucketRegion)
+				if err != nil {
+					l.Error(err, "error retrieving tags for bucket", "bucket", name)
+					merr = multierror.Append(merr, fmt.Errorf("bucket %s: %w", name, err))
+					continue
+				}
+				if !filter.matchesTags(tags) {
+					continue
+				}
+			}
+
+			targets := []v1beta1.Target{{Identifier: name}}
+			if splitByPrefix {
+				prefixes, objects, err := listS3TopLevel(ctx, client, name, withBucketRegion)
+				if err != nil {
+					l.Error(err, "error listing top-level prefixes of bucket", "bucket", name)
+					merr = multierror.Append(merr, fmt.Errorf("bucket %s: %w", name, err))
+					continue
+				}
+				targets = bucketPrefixTargets(name, prefixes, objects)
+			}
+
+			for _, target := range targets {
+				l.Info("queuing target", "identifier", target.Identifier)
+				queue <- target
+			}
+		}
+	}
+
+	return merr.ErrorOrNil()
+}
+
+func getS3BucketTags(
+	ctx context.Context,
+	client *s3Service.Client,
+	bucket string,
+	optFns ...func(*s3Service.Options),
+) (map[string]string, error) {
+	output, err := client.GetBucketTagging(ctx, &s3Service.GetBucketTaggingInput{
+		Bucket: &bucket,
+	}, optFns...)
+	if err != nil {
+		var apiErr smithy.APIError
+		if errors.As(err, &apiErr) && apiErr.ErrorCode() == "NoSuchTagSet" {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	tags := make(map[string]string, len(output.TagSet))
+	for _, tag := range output.TagSet {
+		tags[ptr.Deref(tag.Key, "")] = ptr.Deref(tag.Value, "")
+	}
+	return tags, nil
+}
+
+// listS3TopLevel returns the top-level prefixes and the keys of
+// objects outside of any prefix in the bucket.
+func listS3TopLevel(
+	ctx context.Context,
+	client *s3Service.Client,
+	bucket string,
+	optFns ...func(*s3Service.Options),
+) (prefixes, objects []string, err error) {
+	paginator := s3Service.NewListObjectsV2Paginator(client, &s3Service.ListObjectsV2Input{
+		Bucket:    &bucket,
+		Delimiter: ptr.To("/"),
+	})
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx, optFns...)
+		if err != nil {
+			return nil, nil, err
+		}
+		for _, prefix := range page.CommonPrefixes {
+			prefixes = append(prefixes, ptr.Deref(prefix.Prefix, ""))
+		}
+		for _, obj := range page.Contents {
+			objects = append(objects, ptr.Deref(obj.Key, ""))
+		}
+	}
+	return prefixes, objects, nil
+}
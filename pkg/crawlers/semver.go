@@ -0,0 +1,139 @@
+// Copyright (C) 2025-2026 Crash Override, Inc.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the FSF, either version 3 of the License, or (at your option) any later version.
+// See the LICENSE file in the root of this repository for full license text or
+// visit: <https://www.gnu.org/licenses/gpl-3.0.html>.
+
+package crawlers
+
+import (
+	"cmp"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// IncludePrereleasesParamName is the parameter of the crawlers selecting versions
+// by semantic version, controlling whether pre-release versions are crawled.
+const IncludePrereleasesParamName = "INCLUDE_PRERELEASES"
+
+// semver is a parsed semantic version (https://semver.org). Versions
+// may have a leading 'v' and omit the minor or patch numbers (e.g. 'v1.2').
+// Build metadata is ignored for ordering, as required by the specification.
+type semver struct {
+	Major, Minor, Patch int
+	Prerelease          []string
+}
+
+// parseSemver parses a semantic version, reporting whether the version is valid.
+func parseSemver(version string) (semver, bool) {
+	version = strings.TrimPrefix(version, "v")
+	version, _, _ = strings.Cut(version, "+")
+	version, prerelease, hasPrerelease := strings.Cut(version, "-")
+
+	parts := strings.Split(version, ".")
+	if len(parts) > 3 {
+		return semver{}, false
+	}
+	var numbers [3]int
+	for i, part := range parts {
+		n, err := strconv.Atoi(part)
+		if err != nil || n < 0 || (len(part) > 1 && part[0] == '0') {
+			return semver{}, false
+		}
+		numbers[i] = n
+	}
+
+	v := semver{Major: numbers[0], Minor: numbers[1], Patch: numbers[2]}
+	if hasPrerelease {
+		if prerelease == "" {
+			return semver{}, false
+		}
+		v.Prerelease = strings.Split(prerelease, ".")
+	}
+	return v, true
+}
+
+// IsPrerelease reports whether the version has a pre-release component (e.g. '1.0.0-rc.1').
+func (v semver) IsPrerelease() bool {
+	return len(v.Prerelease) > 0
+}
+
+// Compare returns -1, 0 or 1 if v is lower, equal to or higher than other,
+// following the precedence rules of the semantic versioning specification.
+func (v semver) Compare(other semver) int {
+	if c := cmp.Compare(v.Major, other.Major); c != 0 {
+		return c
+	}
+	if c := cmp.Compare(v.Minor, other.Minor); c != 0 {
+		return c
+	}
+	if c := cmp.Compare(v.Patch, other.Patch); c != 0 {
+		return c
+	}
+
+	// a version without a pre-release has higher precedence than one with
+	switch {
+	case !v.IsPrerelease() && !other.IsPrerelease():
+		return 0
+	case !v.IsPrerelease():
+		return 1
+	case !other.IsPrerelease():
+		return -1
+	}
+
+	for i := 0; i < len(v.Prerelease) && i < len(other.Prerelease); i++ {
+		a, b := v.Prerelease[i], other.Prerelease[i]
+		aNum, aErr := strconv.Atoi(a)
+		bNum, bErr := strconv.Atoi(b)
+		var c int
+		switch {
+		case aErr == nil && bErr == nil:
+			c = cmp.Compare(aNum, bNum)
+		case aErr == nil:
+			// numeric identifiers have lower precedence than alphanumeric ones
+			c = -1
+		case bErr == nil:
+			c = 1
+		default:
+			c = strings.Compare(a, b)
+		}
+		if c != 0 {
+			return c
+		}
+	}
+	return cmp.Compare(len(v.Prerelease), len(other.Prerelease))
+}
+
+// latestSemverVersions orders versions from highest to lowest precedence and returns
+// at most limit of them (all if limit is 0 or less). Versions that are not valid
+// semantic versions are dropped, as are pre-releases unless includePrereleases is set.
+func latestSemverVersions(versions []string, includePrereleases bool, limit int) []string {
+	type parsedVersion struct {
+		raw    string
+		parsed semver
+	}
+	parsed := make([]parsedVersion, 0, len(versions))
+	for _, version := range versions {
+		v, ok := parseSemver(version)
+		if !ok || (v.IsPrerelease() && !includePrereleases) {
+			continue
+		}
+		parsed = append(parsed, parsedVersion{version, v})
+	}
+
+	slices.SortStableFunc(parsed, func(a, b parsedVersion) int {
+		return b.parsed.Compare(a.parsed)
+	})
+	if limit > 0 && len(parsed) > limit {
+		parsed = parsed[:limit]
+	}
+
+	latest := make([]string, 0, len(parsed))
+	for _, p := range parsed {
+		latest = append(latest, p.raw)
+	}
+	return latest
+}
@@ -0,0 +1,134 @@
+// Copyright (C) 2025-2026 Crash Override, Inc.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the FSF, either version 3 of the License, or (at your option) any later version.
+// See the LICENSE file in the root of this repository for full license text or
+// visit: <https://www.gnu.org/licenses/gpl-3.0.html>.
+
+package crawlers
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestParseSemver(t *testing.T) {
+	tests := []struct {
+		version string
+		want    semver
+		wantOK  bool
+	}{
+		{version: "1.2.3", want: semver{Major: 1, Minor: 2, Patch: 3}, wantOK: true},
+		{version: "v1.2.3", want: semver{Major: 1, Minor: 2, Patch: 3}, wantOK: true},
+		{version: "v1.2", want: semver{Major: 1, Minor: 2}, wantOK: true},
+		{version: "2", want: semver{Major: 2}, wantOK: true},
+		{version: "1.0.0+build.5", want: semver{Major: 1}, wantOK: true},
+		{
+			version: "1.0.0-rc.1+build.5",
+			want:    semver{Major: 1, Prerelease: []string{"rc", "1"}},
+			wantOK:  true,
+		},
+		{version: "1.0.0-", wantOK: false},
+		{version: "1.2.3.4", wantOK: false},
+		{version: "01.2.3", wantOK: false},
+		{version: "1.-2.3", wantOK: false},
+		{version: "1.x", wantOK: false},
+		{version: "latest", wantOK: false},
+		{version: "", wantOK: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.version, func(t *testing.T) {
+			got, ok := parseSemver(tt.version)
+			if ok != tt.wantOK {
+				t.Fatalf("got ok %t, want %t", ok, tt.wantOK)
+			}
+			if got.Major != tt.want.Major || got.Minor != tt.want.Minor || got.Patch != tt.want.Patch ||
+				!slices.Equal(got.Prerelease, tt.want.Prerelease) {
+				t.Errorf("got %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSemverCompare(t *testing.T) {
+	// versions in order of precedence, from the semantic versioning specification
+	ordered := []string{
+		"1.0.0-alpha",
+		"1.0.0-alpha.1",
+		"1.0.0-alpha.beta",
+		"1.0.0-beta",
+		"1.0.0-beta.2",
+		"1.0.0-beta.11",
+		"1.0.0-rc.1",
+		"1.0.0",
+		"1.0.1",
+		"1.1.0",
+		"1.10.0",
+		"2.0.0",
+	}
+
+	for i, a := range ordered {
+		for j, b := range ordered {
+			va, _ := parseSemver(a)
+			vb, _ := parseSemver(b)
+			want := 0
+			switch {
+			case i < j:
+				want = -1
+			case i > j:
+				want = 1
+			}
+			if got := va.Compare(vb); got != want {
+				t.Errorf("compare(%q, %q) = %d, want %d", a, b, got, want)
+			}
+		}
+	}
+}
+
+func TestLatestSemverVersions(t *testing.T) {
+	versions := []string{"1.0.0", "v2.0.0-rc.1", "1.10.0", "latest", "1.2.0", "2.0.0-beta", "1.2"}
+
+	tests := []struct {
+		name               string
+		includePrereleases bool
+		limit              int
+		want               []string
+	}{
+		{
+			name: "releases only",
+			want: []string{"1.10.0", "1.2.0", "1.2", "1.0.0"},
+		},
+		{
+			name:               "with pre-releases",
+			includePrereleases: true,
+			want:               []string{"v2.0.0-rc.1", "2.0.0-beta", "1.10.0", "1.2.0", "1.2", "1.0.0"},
+		},
+		{
+			name:  "limited",
+			limit: 2,
+			want:  []string{"1.10.0", "1.2.0"},
+		},
+		{
+			name:               "limited with pre-releases",
+			includePrereleases: true,
+			limit:              1,
+			want:               []string{"v2.0.0-rc.1"},
+		},
+		{
+			name:  "limit above number of versions",
+			limit: 10,
+			want:  []string{"1.10.0", "1.2.0", "1.2", "1.0.0"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := latestSemverVersions(versions, tt.includePrereleases, tt.limit)
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
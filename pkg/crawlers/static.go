@@ -11,21 +11,57 @@ package crawlers
 import (
 	"bufio"
 	"context"
+	"fmt"
+	"os"
+	"regexp"
 	"strings"
+	"unicode"
 
+	"github.com/crashappsec/ocular-default-integrations/pkg/definitions"
 	"github.com/crashappsec/ocular/api/v1beta1"
+	"github.com/hashicorp/go-multierror"
+	yamlv3 "go.yaml.in/yaml/v3"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/yaml"
 )
 
 func init() {
-	All.registerCrawler(StaticList)
+	All.Register(StaticList)
 }
 
 var StaticList = Crawler{
 	Name: "static-list",
 	Parameters: []v1beta1.ParameterDefinition{
 		{
-			Name:        StaticTargetIdentifierList,
-			Description: "New line separated list of target identifiers to crawl.",
+			Name: StaticTargetIdentifierList,
+			Description: "List of targets to crawl, in the format given by " + StaticTargetListFormat + ". " +
+				"Ignored if " + StaticTargetListFile + " or the 'static-target-list' secret is set.",
+			Default: ptr.To(""),
+		},
+		{
+			Name: StaticTargetListFormat,
+			Description: "Format of the target list, one of 'auto', 'lines', 'yaml' or 'json'. " +
+				"The 'lines' format has one target per line, either 'identifier', 'identifier@version' " +
+				"or 'identifier version'. Blank lines and lines starting with '#' are ignored. " +
+				"An '@' followed by a path or a ':' (e.g. 'git@host:repo.git') is part of the identifier, " +
+				"except for digests (e.g. 'image@sha256:<hex>'). " +
+				"The 'yaml' and 'json' formats are an array of objects with 'identifier' and 'version' keys. " +
+				"When 'auto', lists starting with '[' or '- ' are parsed as YAML, otherwise as lines.",
+			Default: ptr.To(StaticTargetListFormatAuto),
+		},
+		{
+			Name: StaticTargetListFile,
+			Description: "Path to a file to read the target list from, such as a mounted ConfigMap. " +
+				"If not set, the 'static-target-list' secret, mounted at " + StaticTargetListSecretPath +
+				", is read if it exists.",
+			Default: ptr.To(""),
+		},
+	},
+	FileSecrets: []definitions.FileSecret{
+		{
+			SecretKey: "static-target-list",
+			MountPath: StaticTargetListSecretPath,
 		},
 	},
 	Crawl: crawlStaticList,
@@ -33,19 +69,190 @@ var StaticList = Crawler{
 
 const (
 	StaticTargetIdentifierList = "TARGET_IDENTIFIERS"
+	StaticTargetListFormat     = "TARGET_LIST_FORMAT"
+	StaticTargetListFile       = "TARGET_LIST_FILE"
+
+	StaticTargetListSecretPath = "/etc/ocular/static-list/targets"
+)
+
+const (
+	StaticTargetListFormatAuto  = "auto"
+	StaticTargetListFormatLines = "lines"
+	StaticTargetListFormatYAML  = "yaml"
+	StaticTargetListFormatJSON  = "json"
 )
 
+// staticTarget is a single entry of a static target list, along with
+// its position in the list (e.g. 'line 3') for error reporting.
+type staticTarget struct {
+	v1beta1.Target
+	position string
+}
+
 func crawlStaticList(
-	_ context.Context,
+	ctx context.Context,
 	params map[string]string,
 	queue chan v1beta1.Target,
 ) error {
-	scanner := bufio.NewScanner(strings.NewReader(params[StaticTargetIdentifierList]))
+	l := log.FromContext(ctx).WithValues("crawler", "static-list")
+
+	list := params[StaticTargetIdentifierList]
+	if file := staticTargetListFile(params, StaticTargetListSecretPath); file != "" {
+		l.Info("reading target list from file", "file", file)
+		content, err := os.ReadFile(file)
+		if err != nil {
+			return fmt.Errorf("error reading target list file: %w", err)
+		}
+		list = string(content)
+	}
+
+	format := strings.ToLower(strings.TrimSpace(params[StaticTargetListFormat]))
+	if format == "" || format == StaticTargetListFormatAuto {
+		format = detectStaticListFormat(list)
+	}
+
+	var (
+		targets []staticTarget
+		err     error
+	)
+	switch format {
+	case StaticTargetListFormatLines:
+		targets = parseStaticListLines(list)
+	case StaticTargetListFormatYAML, StaticTargetListFormatJSON:
+		// YAML is a superset of JSON, so both are handled by the same parser
+		targets, err = parseStaticListYAML(list)
+	default:
+		err = fmt.Errorf("unsupported target list format %q", format)
+	}
+	if err != nil {
+		return err
+	}
+
+	var merr *multierror.Error
+	for _, t := range targets {
+		if err := validateStaticTarget(t.Target); err != nil {
+			l.Error(err, "invalid target in list", "position", t.position)
+			merr = multierror.Append(merr, fmt.Errorf("%s: %w", t.position, err))
+			continue
+		}
+
+		l.Info("queuing target", "identifier", t.Identifier, "version", t.Version)
+		queue <- t.Target
+	}
+
+	return merr.ErrorOrNil()
+}
+
+// staticTargetListFile returns the file to read the target list from, which is
+// either the one given by [StaticTargetListFile] or the mounted secret if it
+// exists, and an empty string if the list is given by [StaticTargetIdentifierList].
+func staticTargetListFile(params map[string]string, secretPath string) string {
+	if file := strings.TrimSpace(params[StaticTargetListFile]); file != "" {
+		return file
+	}
+	// the secret is optional, and mounted as an empty directory when not set
+	if info, err := os.Stat(secretPath); err == nil && info.Mode().IsRegular() {
+		return secretPath
+	}
+	return ""
+}
+
+func detectStaticListFormat(list string) string {
+	trimmed := strings.TrimSpace(list)
+	if strings.HasPrefix(trimmed, "[") || strings.HasPrefix(trimmed, "- ") {
+		return StaticTargetListFormatYAML
+	}
+	return StaticTargetListFormatLines
+}
+
+// parseStaticListLines parses a list with a target on each line,
+// in the form 'identifier', 'identifier@version' or 'identifier version'.
+func parseStaticListLines(list string) []staticTarget {
+	var (
+		targets []staticTarget
+		lineNum int
+	)
+	scanner := bufio.NewScanner(strings.NewReader(list))
 	for scanner.Scan() {
-		queue <- v1beta1.Target{
-			Identifier: scanner.Text(),
+		lineNum++
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		var target v1beta1.Target
+		if fields := strings.Fields(line); len(fields) > 1 {
+			// any extra fields are kept in the version so they are reported as invalid
+			target.Identifier, target.Version = fields[0], strings.Join(fields[1:], " ")
+		} else if idx := strings.LastIndex(line, "@"); idx > 0 && isStaticListVersion(line[idx+1:]) {
+			target.Identifier, target.Version = line[:idx], line[idx+1:]
+		} else {
+			target.Identifier = line
 		}
+		targets = append(targets, staticTarget{Target: target, position: fmt.Sprintf("line %d", lineNum)})
 	}
+	return targets
+}
 
+// digestRegex matches a content digest (e.g. 'sha256:<hex>'), as used to pin container images.
+var digestRegex = regexp.MustCompile(`^[a-z0-9]+(?:[+._-][a-z0-9]+)*:[a-fA-F0-9]{32,}$`)
+
+// isStaticListVersion reports whether the text after an '@' is a version. It
+// is not if the '@' is part of a scoped package name (e.g. '@acme/pkg'), a URL
+// with credentials or an SCP-like git address (e.g. 'git@host:repo.git').
+func isStaticListVersion(version string) bool {
+	if strings.Contains(version, "/") {
+		return false
+	}
+	return !strings.Contains(version, ":") || digestRegex.MatchString(version)
+}
+
+// parseStaticListYAML parses a YAML or JSON array of targets. Entries are
+// referred to by their index and the line they start on.
+func parseStaticListYAML(list string) ([]staticTarget, error) {
+	var entries []v1beta1.Target
+	if err := yaml.UnmarshalStrict([]byte(list), &entries); err != nil {
+		return nil, fmt.Errorf("error parsing target list: %w", err)
+	}
+	lines := staticListYAMLLines(list)
+	targets := make([]staticTarget, 0, len(entries))
+	for i, entry := range entries {
+		position := fmt.Sprintf("entry %d", i+1)
+		if len(lines) == len(entries) {
+			position += fmt.Sprintf(" on line %d", lines[i])
+		}
+		targets = append(targets, staticTarget{Target: entry, position: position})
+	}
+	return targets, nil
+}
+
+// staticListYAMLLines returns the line each entry of a YAML or JSON array starts on.
+// The positions are lost when unmarshalling into targets, so the list is parsed again
+// into YAML nodes, which keep them.
+func staticListYAMLLines(list string) []int {
+	var doc yamlv3.Node
+	if err := yamlv3.Unmarshal([]byte(list), &doc); err != nil ||
+		len(doc.Content) == 0 || doc.Content[0].Kind != yamlv3.SequenceNode {
+		return nil
+	}
+	lines := make([]int, 0, len(doc.Content[0].Content))
+	for _, entry := range doc.Content[0].Content {
+		lines = append(lines, entry.Line)
+	}
+	return lines
+}
+
+func validateStaticTarget(target v1beta1.Target) error {
+	if target.Identifier == "" {
+		return fmt.Errorf("identifier is empty")
+	}
+	if strings.ContainsFunc(target.Identifier, unicode.IsSpace) ||
+		strings.ContainsFunc(target.Identifier, unicode.IsControl) {
+		return fmt.Errorf("identifier %q contains whitespace or control characters", target.Identifier)
+	}
+	if strings.ContainsFunc(target.Version, unicode.IsSpace) ||
+		strings.ContainsFunc(target.Version, unicode.IsControl) {
+		return fmt.Errorf("version %q contains whitespace or control characters", target.Version)
+	}
 	return nil
 }
@@ -0,0 +1,246 @@
+// Copyright (C) 2025-2026 Crash Override, Inc.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the FSF, either version 3 of the License, or (at your option) any later version.
+// See the LICENSE file in the root of this repository for full license text or
+// visit: <https://www.gnu.org/licenses/gpl-3.0.html>.
+
+package crawlers
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/crashappsec/ocular-default-integrations/pkg/input"
+	"github.com/crashappsec/ocular/api/v1beta1"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+const (
+	TagIncludePatternParamName = "TAG_INCLUDE_PATTERN"
+	TagExcludePatternParamName = "TAG_EXCLUDE_PATTERN"
+	TagOrderParamName          = "TAG_ORDER"
+	TagLatestPerParamName      = "TAG_LATEST_PER"
+	TagMaxAgeParamName         = "TAG_MAX_AGE"
+)
+
+const (
+	TagOrderPushed = "pushed"
+	TagOrderSemver = "semver"
+)
+
+const (
+	TagLatestPerMajor = "major"
+	TagLatestPerMinor = "minor"
+)
+
+// tagSelectionParameters are the parameters shared by all container registry
+// crawlers to select which tags of an image are crawled.
+func tagSelectionParameters(registry string) []v1beta1.ParameterDefinition {
+	return []v1beta1.ParameterDefinition{
+		{
+			Name: RecentTagLimitParam,
+			Description: "Maximum number of tags (versions) to retrieve per image. " +
+				"Will retrieve the first N tags for each " + registry + " image, in the order of " + TagOrderParamName +
+				" after all other tag filters are applied, and start a new pipeline for each. " +
+				"Set to 0 to retrieve all versions. Defaults to 1.",
+			Default: ptr.To("1"),
+		},
+		{
+			Name: TagIncludePatternParamName,
+			Description: "Regular expression tags must match to be crawled (e.g. '^v?[0-9]+\\.[0-9]+\\.[0-9]+$'). " +
+				"If empty, all tags are included.",
+			Default: ptr.To(""),
+		},
+		{
+			Name: TagExcludePatternParamName,
+			Description: "Regular expression of tags to skip (e.g. '-rc|^sha-|^latest$'). " +
+				"If empty, no tags are excluded.",
+			Default: ptr.To(""),
+		},
+		{
+			Name: TagOrderParamName,
+			Description: "Order in which tags are selected, either 'pushed' for the most recently pushed first, " +
+				"or 'semver' for the highest semantic version first. With 'semver', tags that are not " +
+				"semantic versions (optionally prefixed with 'v') are skipped.",
+			Default: ptr.To(TagOrderPushed),
+		},
+		{
+			Name: TagLatestPerParamName,
+			Description: "Select only the first tag, in the order of " + TagOrderParamName + ", of each 'major' " +
+				"or 'minor' version (e.g. 'minor' with the 'semver' order selects the latest patch of each minor " +
+				"version). Tags that are not semantic versions are skipped. If empty, tags are not grouped.",
+			Default: ptr.To(""),
+		},
+		{
+			Name: TagMaxAgeParamName,
+			Description: "Only select tags pushed within the given duration (e.g. '720h'). " +
+				"Tags whose push time is unknown are kept. If empty, tags of any age are selected.",
+			Default: ptr.To(""),
+		},
+		{
+			Name: IncludePrereleasesParamName,
+			Description: "If true, tags that are semantic version pre-releases (e.g. '1.2.0-rc.1') are selected " +
+				"when ordering or grouping by semantic version.",
+			Default: ptr.To("false"),
+		},
+	}
+}
+
+// registryTag is a tag of an image found by a registry crawler.
+type registryTag struct {
+	Name string
+	// Digest of the manifest the tag points to, if known. Only the first
+	// selected tag of each digest is kept, since the others are the same image.
+	Digest string
+	// PushedAt is when the tag was pushed, the zero time if unknown.
+	PushedAt time.Time
+}
+
+// tagSelector selects the tags of an image to crawl, so that all
+// registry crawlers apply the same filters, ordering and limit.
+type tagSelector struct {
+	include, exclude   *regexp.Regexp
+	order              string
+	latestPer          string
+	includePrereleases bool
+	maxAge             time.Duration
+	limit              int
+}
+
+// newTagSelector parses the tag selection parameters.
+func newTagSelector(ctx context.Context, params map[string]string) (*tagSelector, error) {
+	l := log.FromContext(ctx)
+	s := &tagSelector{
+		order:     strings.ToLower(strings.TrimSpace(params[TagOrderParamName])),
+		latestPer: strings.ToLower(strings.TrimSpace(params[TagLatestPerParamName])),
+	}
+
+	var err error
+	if s.includePrereleases, err = input.BoolParam(params, IncludePrereleasesParamName, false); err != nil {
+		return nil, err
+	}
+
+	limit, err := strconv.Atoi(params[RecentTagLimitParam])
+	if err != nil {
+		l.Error(err, "invalid value for limit, defaulting to 1", "limit", RecentTagLimitParam)
+		limit = 1
+	}
+	s.limit = limit
+
+	if pattern := params[TagIncludePatternParamName]; pattern != "" {
+		if s.include, err = regexp.Compile(pattern); err != nil {
+			return nil, fmt.Errorf("invalid value for %s: %w", TagIncludePatternParamName, err)
+		}
+	}
+	if pattern := params[TagExcludePatternParamName]; pattern != "" {
+		if s.exclude, err = regexp.Compile(pattern); err != nil {
+			return nil, fmt.Errorf("invalid value for %s: %w", TagExcludePatternParamName, err)
+		}
+	}
+
+	switch s.order {
+	case "":
+		s.order = TagOrderPushed
+	case TagOrderPushed, TagOrderSemver:
+	default:
+		return nil, fmt.Errorf("unsupported tag order %q", s.order)
+	}
+	switch s.latestPer {
+	case "", TagLatestPerMajor, TagLatestPerMinor:
+	default:
+		return nil, fmt.Errorf("invalid value for %s: %q is not 'major' or 'minor'", TagLatestPerParamName, s.latestPer)
+	}
+
+	if maxAge := strings.TrimSpace(params[TagMaxAgeParamName]); maxAge != "" {
+		if s.maxAge, err = time.ParseDuration(maxAge); err != nil {
+			return nil, fmt.Errorf("invalid value for %s: %w", TagMaxAgeParamName, err)
+		}
+	}
+	return s, nil
+}
+
+// usesSemver reports whether tags must be semantic versions to be selected.
+func (s *tagSelector) usesSemver() bool {
+	return s.order == TagOrderSemver || s.latestPer != ""
+}
+
+// selectTags returns the names of the selected tags, in order.
+func (s *tagSelector) selectTags(tags []registryTag) []string {
+	type candidate struct {
+		registryTag
+		version semver
+	}
+
+	var cutoff time.Time
+	if s.maxAge > 0 {
+		cutoff = time.Now().Add(-s.maxAge)
+	}
+
+	candidates := make([]candidate, 0, len(tags))
+	for _, tag := range tags {
+		if s.include != nil && !s.include.MatchString(tag.Name) {
+			continue
+		}
+		if s.exclude != nil && s.exclude.MatchString(tag.Name) {
+			continue
+		}
+		if !cutoff.IsZero() && !tag.PushedAt.IsZero() && tag.PushedAt.Before(cutoff) {
+			continue
+		}
+		c := candidate{registryTag: tag}
+		if s.usesSemver() {
+			v, ok := parseSemver(tag.Name)
+			if !ok || (v.IsPrerelease() && !s.includePrereleases) {
+				continue
+			}
+			c.version = v
+		}
+		candidates = append(candidates, c)
+	}
+
+	slices.SortStableFunc(candidates, func(a, b candidate) int {
+		if s.order == TagOrderSemver {
+			if c := b.version.Compare(a.version); c != 0 {
+				return c
+			}
+		}
+		return b.PushedAt.Compare(a.PushedAt)
+	})
+
+	var (
+		selected = make([]string, 0, len(candidates))
+		digests  = make(map[string]struct{})
+		groups   = make(map[string]struct{})
+	)
+	for _, c := range candidates {
+		if s.limit > 0 && len(selected) >= s.limit {
+			break
+		}
+		if c.Digest != "" {
+			if _, ok := digests[c.Digest]; ok {
+				continue
+			}
+			digests[c.Digest] = struct{}{}
+		}
+		if s.latestPer != "" {
+			group := strconv.Itoa(c.version.Major)
+			if s.latestPer == TagLatestPerMinor {
+				group += "." + strconv.Itoa(c.version.Minor)
+			}
+			if _, ok := groups[group]; ok {
+				continue
+			}
+			groups[group] = struct{}{}
+		}
+		selected = append(selected, c.Name)
+	}
+	return selected
+}
@@ -0,0 +1,195 @@
+// Copyright (C) 2025-2026 Crash Override, Inc.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the FSF, either version 3 of the License, or (at your option) any later version.
+// See the LICENSE file in the root of this repository for full license text or
+// visit: <https://www.gnu.org/licenses/gpl-3.0.html>.
+
+package crawlers
+
+import (
+	"context"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestNewTagSelector(t *testing.T) {
+	tests := []struct {
+		name    string
+		params  map[string]string
+		want    tagSelector
+		wantErr bool
+	}{
+		{
+			name:   "defaults",
+			params: map[string]string{RecentTagLimitParam: "5"},
+			want:   tagSelector{order: TagOrderPushed, limit: 5},
+		},
+		{
+			name:   "invalid limit defaults to 1",
+			params: map[string]string{RecentTagLimitParam: "many"},
+			want:   tagSelector{order: TagOrderPushed, limit: 1},
+		},
+		{
+			name: "all parameters",
+			params: map[string]string{
+				RecentTagLimitParam:         "0",
+				TagOrderParamName:           " Semver ",
+				TagLatestPerParamName:       "MINOR",
+				IncludePrereleasesParamName: "true",
+				TagMaxAgeParamName:          "720h",
+			},
+			want: tagSelector{
+				order:              TagOrderSemver,
+				latestPer:          TagLatestPerMinor,
+				includePrereleases: true,
+				maxAge:             720 * time.Hour,
+			},
+		},
+		{
+			name:    "invalid include pre-releases",
+			params:  map[string]string{IncludePrereleasesParamName: "yes"},
+			wantErr: true,
+		},
+		{
+			name:    "unsupported order",
+			params:  map[string]string{TagOrderParamName: "alphabetical"},
+			wantErr: true,
+		},
+		{
+			name:    "invalid latest per",
+			params:  map[string]string{TagLatestPerParamName: "patch"},
+			wantErr: true,
+		},
+		{
+			name:    "invalid include pattern",
+			params:  map[string]string{TagIncludePatternParamName: "("},
+			wantErr: true,
+		},
+		{
+			name:    "invalid exclude pattern",
+			params:  map[string]string{TagExcludePatternParamName: "["},
+			wantErr: true,
+		},
+		{
+			name:    "invalid max age",
+			params:  map[string]string{TagMaxAgeParamName: "30 days"},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := newTagSelector(context.Background(), tt.params)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("expected an error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			got.include, got.exclude = nil, nil
+			if *got != tt.want {
+				t.Errorf("got %+v, want %+v", *got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTagSelectorSelectTags(t *testing.T) {
+	now := time.Now()
+	daysAgo := func(days int) time.Time {
+		return now.Add(-time.Duration(days) * 24 * time.Hour)
+	}
+	tags := []registryTag{
+		{Name: "latest", Digest: "sha256:c", PushedAt: daysAgo(1)},
+		{Name: "v2.1.0", Digest: "sha256:c", PushedAt: daysAgo(1)},
+		{Name: "v2.1.0-rc.1", Digest: "sha256:b", PushedAt: daysAgo(5)},
+		{Name: "v2.0.1", Digest: "sha256:a", PushedAt: daysAgo(3)},
+		{Name: "v2.0.0", Digest: "sha256:9", PushedAt: daysAgo(20)},
+		{Name: "v1.9.0", Digest: "sha256:8", PushedAt: daysAgo(40)},
+		{Name: "v1.10.0", Digest: "sha256:7", PushedAt: daysAgo(30)},
+		{Name: "dev", PushedAt: daysAgo(2)},
+		{Name: "v0.1.0"},
+	}
+
+	tests := []struct {
+		name     string
+		selector tagSelector
+		want     []string
+	}{
+		{
+			name:     "pushed order keeps first tag of each digest",
+			selector: tagSelector{order: TagOrderPushed},
+			want:     []string{"latest", "dev", "v2.0.1", "v2.1.0-rc.1", "v2.0.0", "v1.10.0", "v1.9.0", "v0.1.0"},
+		},
+		{
+			name:     "pushed order with limit",
+			selector: tagSelector{order: TagOrderPushed, limit: 3},
+			want:     []string{"latest", "dev", "v2.0.1"},
+		},
+		{
+			name:     "semver order drops other tags and pre-releases",
+			selector: tagSelector{order: TagOrderSemver},
+			want:     []string{"v2.1.0", "v2.0.1", "v2.0.0", "v1.10.0", "v1.9.0", "v0.1.0"},
+		},
+		{
+			name:     "semver order with pre-releases",
+			selector: tagSelector{order: TagOrderSemver, includePrereleases: true, limit: 2},
+			want:     []string{"v2.1.0", "v2.1.0-rc.1"},
+		},
+		{
+			name:     "latest per major",
+			selector: tagSelector{order: TagOrderSemver, latestPer: TagLatestPerMajor},
+			want:     []string{"v2.1.0", "v1.10.0", "v0.1.0"},
+		},
+		{
+			name:     "latest per minor",
+			selector: tagSelector{order: TagOrderSemver, latestPer: TagLatestPerMinor, limit: 4},
+			want:     []string{"v2.1.0", "v2.0.1", "v1.10.0", "v1.9.0"},
+		},
+		{
+			name:     "latest per major in pushed order",
+			selector: tagSelector{order: TagOrderPushed, latestPer: TagLatestPerMajor},
+			want:     []string{"v2.1.0", "v1.10.0", "v0.1.0"},
+		},
+		{
+			name:     "max age keeps tags without push time",
+			selector: tagSelector{order: TagOrderPushed, maxAge: 10 * 24 * time.Hour},
+			want:     []string{"latest", "dev", "v2.0.1", "v2.1.0-rc.1", "v0.1.0"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := tt.selector.selectTags(tags)
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTagSelectorPatterns(t *testing.T) {
+	s, err := newTagSelector(context.Background(), map[string]string{
+		RecentTagLimitParam:        "0",
+		TagIncludePatternParamName: `^v\d`,
+		TagExcludePatternParamName: `-rc`,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	got := s.selectTags([]registryTag{
+		{Name: "v1.1.0-rc.1"},
+		{Name: "v1.0.0"},
+		{Name: "latest"},
+		{Name: "release-v1"},
+	})
+	if want := []string{"v1.0.0"}; !slices.Equal(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+}
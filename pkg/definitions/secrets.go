@@ -13,11 +13,13 @@ import (
 	v1 "k8s.io/api/core/v1"
 )
 
+// FileSecret is a key of the integration secret mounted as a file at MountPath.
 type FileSecret struct {
 	SecretKey string
 	MountPath string
 }
 
+// EnvironmentSecret is a key of the integration secret set as the environment variable EnvVarName.
 type EnvironmentSecret struct {
 	SecretKey  string
 	EnvVarName string
@@ -9,27 +9,27 @@
 package downloaders
 
 import (
-	"archive/tar"
 	"context"
 	"fmt"
-	"io"
 	"os"
 	"path/filepath"
 	"regexp"
+	"slices"
+	"strings"
 
-	"github.com/crashappsec/ocular-default-integrations/internal/definitions"
+	"github.com/crashappsec/ocular-default-integrations/pkg/definitions"
+	"github.com/crashappsec/ocular-default-integrations/pkg/input"
 	"github.com/crashappsec/ocular/api/v1beta1"
 	"github.com/google/go-containerregistry/pkg/authn"
 	"github.com/google/go-containerregistry/pkg/name"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
 	"github.com/google/go-containerregistry/pkg/v1/remote"
-	"github.com/google/go-containerregistry/pkg/v1/tarball"
 	corev1 "k8s.io/api/core/v1"
-	"k8s.io/utils/ptr"
 	"sigs.k8s.io/controller-runtime/pkg/log"
 )
 
 func init() {
-	All.registerDownloader(Docker)
+	All.Register(Docker)
 }
 
 const (
@@ -44,34 +44,66 @@ var Docker = Downloader{
 			Value: DockerConfigFolder,
 		},
 	},
-	FileSecrets: []definitions.FileSecret{
+	FileSecrets: append([]definitions.FileSecret{
 		{
 			SecretKey: "dockerconfig",
 			MountPath: DockerConfigFolder + "/config.json",
 		},
+	}, dockerAttachmentFileSecrets...),
+	Parameters: slices.Concat(
+		dockerOutputParameters,
+		dockerPlatformParameters,
+		dockerChalkParameters,
+		dockerAttachmentParameters,
+	),
+	MetadataFiles: []string{
+		DockerMetadataPath,
+		DockerChalkMetadataPath,
+		DockerSignaturesMetadataPath,
+		DockerAttestationsMetadataPath,
+		DockerSBOMsMetadataPath,
 	},
-	Parameters: []v1beta1.ParameterDefinition{
-		{
-			Name:    DockerTarOutputParamName,
-			Default: ptr.To("target.tar"),
-		},
-	},
-	MetadataFiles: []string{DockerMetadataPath, DockerChalkMetadataPath},
-	Download:      downloadDocker,
+	Download: downloadDocker,
 }
 
 const DockerConfigFolder = "/ocular/docker"
 
-var shaRegex = regexp.MustCompile(`^sha256:[a-f0-9]{40}$`)
+var shaRegex = regexp.MustCompile(`^sha256:[a-f0-9]{64}$`)
 
-func downloadDocker(ctx context.Context, params map[string]string, dockerImage, tag, targetDir string) error {
-	if tag == "" {
-		tag = "latest"
+// splitDockerVersion splits the version of a target into its tag and digest. The version
+// is either a tag, a digest or a tag pinned to a digest ('<tag>@sha256:<digest>'),
+// in which case the image is pulled by digest and the tag is only recorded.
+func splitDockerVersion(version string) (tag, digest string) {
+	if shaRegex.MatchString(version) {
+		return "", version
+	}
+	if i := strings.LastIndex(version, "@"); i > 0 && shaRegex.MatchString(version[i+1:]) {
+		return version[:i], version[i+1:]
 	}
+	if version == "" {
+		version = "latest"
+	}
+	return version, ""
+}
+
+func downloadDocker(ctx context.Context, params map[string]string, dockerImage, version, targetDir string) error {
 	l := log.FromContext(ctx)
+	tag, digest := splitDockerVersion(version)
+	format, output, err := parseDockerOutput(params)
+	if err != nil {
+		return err
+	}
+	fetchAttachments, err := input.BoolParam(params, DockerAttachmentsParamName, true)
+	if err != nil {
+		return err
+	}
+	publicKey, err := loadDockerCosignPublicKey()
+	if err != nil {
+		return err
+	}
 	var fullImage string
-	if shaRegex.MatchString(tag) {
-		fullImage = dockerImage + "@" + tag
+	if digest != "" {
+		fullImage = dockerImage + "@" + digest
 	} else {
 		fullImage = dockerImage + ":" + tag
 	}
@@ -83,131 +115,133 @@ func downloadDocker(ctx context.Context, params map[string]string, dockerImage,
 	}
 
 	l.Info("fetching image from remote", "ref", ref.String())
-	img, err := remote.Image(
-		ref,
+	remoteOpts := []remote.Option{
 		remote.WithContext(ctx),
 		remote.WithAuthFromKeychain(authn.DefaultKeychain),
-	)
+	}
+	desc, err := remote.Get(ref, remoteOpts...)
 	if err != nil {
 		l.Error(err, "Failed to get remote manifest", "image", fullImage)
 		return err
 	}
 
-	tarFileName := params[DockerTarOutputParamName]
-
-	tarFile, err := os.Create(filepath.Clean(filepath.Join(targetDir, tarFileName)))
+	images, err := selectDockerPlatforms(desc, params[DockerPlatformParamName])
 	if err != nil {
-		return fmt.Errorf("unable to create tar file: %w", err)
-	}
-	defer func() {
-		if err := tarFile.Close(); err != nil {
-			l.Error(err, "failed to close tar reader")
-		}
-	}()
-
-	l.Info("downloading image", "image", fullImage)
-	if err = tarball.Write(ref, img, tarFile); err != nil {
-		l.Error(err, "Failed to write tarball", "image", fullImage)
+		l.Error(err, "Failed to select image platform", "image", fullImage)
 		return err
 	}
-	l.Info("Downloaded image successfully", "image", fullImage)
 
 	metadata := DockerMetadata{
-		Image: dockerImage,
-		Tag:   tag,
-	}
-
-	sha, err := img.Digest()
-	if err != nil {
-		l.Error(err, "Failed to get image digest", "image", fullImage)
-	} else {
-		metadata.SHA = sha.String()
-	}
-
-	if err = writeJSONStruct(DockerMetadataPath, metadata); err != nil {
-		l.Error(err, "Failed to write docker metadata", "path", DockerMetadataPath)
-	}
-
-	l.Info("beginning chalk extraction", "image", fullImage)
-
-	layers, err := img.Layers()
-	if err != nil {
-		l.Error(err, "failed to inspect image layers", "image", fullImage)
-		return nil
-	}
-
-	if len(layers) == 0 {
-		l.Info("image has no layers", "image", fullImage)
-		return nil
-	}
-
-	if lastLayer := layers[len(layers)-1]; lastLayer != nil {
-		l.Info("attempting to extract chalk metadata from last layer", "image", fullImage)
-		mediaType, err := lastLayer.MediaType()
+		Image:  dockerImage,
+		Tag:    tag,
+		Digest: digest,
+		Format: format,
+		Output: output,
+	}
+	if desc.MediaType.IsIndex() {
+		metadata.IndexDigest = desc.Digest.String()
+	}
+
+	for _, image := range images {
+		imageOutput := filepath.Join(image.dir, output)
+		l.Info("downloading image", "image", fullImage, "platform", image.platform,
+			"format", format, "output", imageOutput)
+		if err = os.MkdirAll(filepath.Join(targetDir, image.dir), 0o755); err != nil {
+			return err
+		}
+		err = writeDockerOutput(ctx, ref, image.img, tag, format, filepath.Join(targetDir, imageOutput))
 		if err != nil {
-			l.Error(err, "failed to get last layer media type", "image", fullImage)
-			return nil
+			l.Error(err, "Failed to write image", "image", fullImage, "platform", image.platform)
+			return err
 		}
 
-		if !mediaType.IsLayer() {
-			l.Info("last layer media type is not a layer, skipping chalk extraction", "mediaType", mediaType)
-			return nil
+		var sha string
+		if h, err := image.img.Digest(); err != nil {
+			l.Error(err, "Failed to get image digest", "image", fullImage, "platform", image.platform)
+		} else {
+			sha = h.String()
 		}
-
-		rc, err := lastLayer.Uncompressed()
-		if err != nil {
-			l.Error(err, "failed to get uncompressed last layer", "image", fullImage)
-			return nil
+		if image.dir == "" {
+			metadata.Platform = image.platform
+			metadata.SHA = sha
+		} else {
+			metadata.Platforms = append(metadata.Platforms, DockerPlatformMetadata{
+				Platform: image.platform,
+				SHA:      sha,
+				Output:   imageOutput,
+			})
 		}
-
-		defer func() {
-			if err = rc.Close(); err != nil {
-				l.Error(err, "failed to close last layer reader")
+	}
+	l.Info("Downloaded image successfully", "image", fullImage, "platforms", len(images))
+
+	if fetchAttachments {
+		var (
+			subjects []v1.Hash
+			idx      v1.ImageIndex
+		)
+		if desc.MediaType.IsIndex() {
+			subjects = append(subjects, desc.Digest)
+			if idx, err = desc.ImageIndex(); err != nil {
+				l.Error(err, "Failed to read image index", "image", fullImage)
 			}
-		}()
-
-		if err = extractChalk(ctx, rc, DockerChalkMetadataPath); err != nil {
-			l.Error(err, "failed to extract chalk metadata", "image", fullImage)
 		}
+		for _, image := range images {
+			if h, err := image.img.Digest(); err == nil {
+				subjects = append(subjects, h)
+			}
+		}
+		attachments := fetchDockerAttachments(ctx, ref.Context(), subjects, idx, remoteOpts...)
+		metadata.Attachments = attachments.metadata()
+		if publicKey != nil {
+			metadata.Attachments.Verification = attachments.verify(publicKey)
+		}
+		attachments.write(ctx)
+		l.Info("fetched image attachments", "image", fullImage, "signatures", metadata.Attachments.Signatures,
+			"attestations", metadata.Attachments.Attestations, "sboms", metadata.Attachments.SBOMs)
 	}
 
-	return nil
-}
-
-func extractChalk(ctx context.Context, tr io.Reader, chalkPath string) error {
-	l := log.FromContext(ctx)
-	tarReader := tar.NewReader(tr)
-	chalk, err := tarReader.Next()
-	if err != nil {
-		return fmt.Errorf("reading last layer tar: %w", err)
-	}
-
-	if chalk.Name != chalkFileName {
-		l.Info("chalk metadata file not found in last layer", "expected", chalkFileName, "found", chalk.Name)
-		return nil
+	// chalk metadata is extracted from the first platform if all are pulled
+	l.Info("beginning chalk extraction", "image", fullImage)
+	chalkPaths := parseDockerChalkPaths(params)
+	metadata.Chalk, err = extractChalk(ctx, ref, images[0].img, format,
+		filepath.Join(targetDir, images[0].dir, output), chalkPaths, DockerChalkMetadataPath, remoteOpts...)
+	switch {
+	case err != nil:
+		l.Error(err, "failed to extract chalk metadata", "image", fullImage)
+	case metadata.Chalk == nil:
+		l.Info("no chalk mark found in image", "image", fullImage, "paths", chalkPaths)
+	default:
+		l.Info("extracted chalk metadata", "image", fullImage, "source", metadata.Chalk.Source)
 	}
 
-	chalkFile, err := os.Create(filepath.Clean(chalkPath))
-	if err != nil {
-		return fmt.Errorf("creating chalk metadata file: %w", err)
+	if err = writeJSONStruct(DockerMetadataPath, metadata); err != nil {
+		l.Error(err, "Failed to write docker metadata", "path", DockerMetadataPath)
 	}
-	defer func() {
-		if err := chalkFile.Close(); err != nil {
-			l.Error(err, "failed to close chalk metadata file")
-		}
-	}()
 
-	_, err = io.Copy(chalkFile, tarReader)
-	if err != nil {
-		return fmt.Errorf("writing chalk metadata file: %w", err)
-	}
 	return nil
 }
 
 type DockerMetadata struct {
 	Image string `json:"image,omitempty"`
 	Tag   string `json:"tag,omitempty"`
-	SHA   string `json:"sha,omitempty"`
+	// Digest is the digest the image was pulled by, if the target version was pinned to one.
+	// It differs from SHA if the digest is of a multi-platform index.
+	Digest string `json:"digest,omitempty"`
+	// SHA is the digest of the image manifest, unset if every platform of an index was pulled.
+	SHA string `json:"sha,omitempty"`
+	// IndexDigest is the digest of the multi-platform index, if the image is one.
+	IndexDigest string `json:"index_digest,omitempty"`
+	// Platform is the platform of the image, if a single one was pulled.
+	Platform string `json:"platform,omitempty"`
+	// Platforms are the images written for each platform, if all were pulled.
+	Platforms []DockerPlatformMetadata `json:"platforms,omitempty"`
+	// Chalk records where the chalk mark of the image was found, if any.
+	Chalk *DockerChalkMetadata `json:"chalk,omitempty"`
+	// Attachments records the signatures, attestations and SBOMs found, if fetched.
+	Attachments *DockerAttachmentsMetadata `json:"attachments,omitempty"`
+	// Format is the output format, and Output the name of the output in the target directory.
+	Format string `json:"format,omitempty"`
+	Output string `json:"output,omitempty"`
 }
 
 const (
@@ -0,0 +1,515 @@
+// Copyright (C) 2025-2026 Crash Override, Inc.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the FSF, either version 3 of the License, or (at your option) any later version.
+// See the LICENSE file in the root of this repository for full license text or
+// visit: <https://www.gnu.org/licenses/gpl-3.0.html>.
+
+package downloaders
+
+import (
+	"bytes"
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/crashappsec/ocular-default-integrations/pkg/definitions"
+	"github.com/crashappsec/ocular/api/v1beta1"
+	"github.com/google/go-containerregistry/pkg/name"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+	"github.com/google/go-containerregistry/pkg/v1/remote/transport"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+const (
+	DockerAttachmentsParamName = "FETCH_ATTACHMENTS"
+)
+
+const (
+	DockerCosignPublicKeyPath = "/etc/ocular/docker/cosign.pub"
+
+	DockerSignaturesMetadataPath   = v1beta1.PipelineMetadataDirectory + "/docker-signatures.json"
+	DockerAttestationsMetadataPath = v1beta1.PipelineMetadataDirectory + "/docker-attestations.json"
+	DockerSBOMsMetadataPath        = v1beta1.PipelineMetadataDirectory + "/docker-sboms.json"
+)
+
+const (
+	// DockerAttachmentSourceTag, DockerAttachmentSourceReferrer and DockerAttachmentSourceIndex
+	// are the ways an attachment is found: the cosign tag convention ('sha256-<digest>.sig'),
+	// the OCI referrers API, and the attestation manifests buildkit adds to an index.
+	DockerAttachmentSourceTag      = "tag"
+	DockerAttachmentSourceReferrer = "referrer"
+	DockerAttachmentSourceIndex    = "index"
+)
+
+// dockerAttachmentMaxSize is the maximum size of a blob of an attachment that is saved.
+const dockerAttachmentMaxSize = 128 << 20
+
+// dockerCosignTagSuffixes are the suffixes of the cosign tags of the attachments of an image.
+var dockerCosignTagSuffixes = []string{".sig", ".att", ".sbom"}
+
+const (
+	cosignSimpleSigningMediaType = "application/vnd.dev.cosign.simplesigning.v1+json"
+	cosignSignatureAnnotation    = "dev.cosignproject.cosign/signature"
+	dsseEnvelopeMediaType        = "application/vnd.dsse.envelope.v1+json"
+	inTotoMediaType              = "application/vnd.in-toto+json"
+	sigstoreBundleMediaType      = "application/vnd.dev.sigstore.bundle"
+)
+
+// dockerSBOMMediaTypes are the media types of SBOM blobs and artifacts.
+var dockerSBOMMediaTypes = []string{
+	"text/spdx",
+	"text/spdx+json",
+	"application/spdx+json",
+	"application/vnd.cyclonedx",
+	"application/vnd.cyclonedx+json",
+	"application/vnd.cyclonedx+xml",
+	"application/vnd.syft+json",
+}
+
+// dockerPredicateTypeAnnotations are the layer annotations giving the predicate type of an attestation.
+var dockerPredicateTypeAnnotations = []string{"predicateType", "in-toto.io/predicate-type"}
+
+var dockerAttachmentParameters = []v1beta1.ParameterDefinition{
+	{
+		Name: DockerAttachmentsParamName,
+		Description: "If true, the cosign signatures, in-toto attestations and SBOMs attached to the image with " +
+			"the cosign tag convention ('sha256-<digest>.sig', '.att' and '.sbom'), the OCI referrers API or " +
+			"the attestation manifests of the index are saved to " + DockerSignaturesMetadataPath + ", " +
+			DockerAttestationsMetadataPath + " and " + DockerSBOMsMetadataPath + ". If the 'cosign-public-key' " +
+			"secret is set, signatures and attestations are verified against it.",
+		Default: ptr.To("true"),
+	},
+}
+
+var dockerAttachmentFileSecrets = []definitions.FileSecret{
+	{
+		SecretKey: "cosign-public-key",
+		MountPath: DockerCosignPublicKeyPath,
+	},
+}
+
+// DockerAttachment is a signature, attestation or SBOM attached to the image.
+type DockerAttachment struct {
+	// Subject is the digest of the image or index the attachment refers to.
+	Subject string `json:"subject"`
+	// Source is one of 'tag', 'referrer' or 'index'.
+	Source string `json:"source"`
+	// Manifest is the digest of the manifest of the attachment, and Digest of its blob.
+	Manifest      string            `json:"manifest"`
+	Digest        string            `json:"digest"`
+	MediaType     string            `json:"media_type"`
+	PredicateType string            `json:"predicate_type,omitempty"`
+	Annotations   map[string]string `json:"annotations,omitempty"`
+	// Content is the base64 encoded blob, kept as is so that signatures can be verified again.
+	Content []byte `json:"content"`
+	// Verified is whether the signature is valid for the public key. It is unset if no key is set,
+	// or for signatures that are not verified, which are the message signatures of sigstore bundles.
+	Verified *bool `json:"verified,omitempty"`
+}
+
+// DockerAttachmentsMetadata records the number of attachments found, and the result of their verification.
+type DockerAttachmentsMetadata struct {
+	Signatures   int `json:"signatures"`
+	Attestations int `json:"attestations"`
+	SBOMs        int `json:"sboms"`
+	// Verification is unset if no public key is set.
+	Verification *DockerVerificationMetadata `json:"verification,omitempty"`
+}
+
+// DockerVerificationMetadata is the result of verifying the attachments against the public key.
+type DockerVerificationMetadata struct {
+	// Verified is true if at least one signature or attestation is valid for the public key.
+	Verified             bool `json:"verified"`
+	VerifiedSignatures   int  `json:"verified_signatures"`
+	VerifiedAttestations int  `json:"verified_attestations"`
+}
+
+// dockerAttachments are the attachments found for the subjects, by kind.
+type dockerAttachments struct {
+	signatures   []DockerAttachment
+	attestations []DockerAttachment
+	sboms        []DockerAttachment
+	// manifests are the digests of the attachment manifests already read, since the
+	// same manifest can be found with both the cosign tag convention and referrers.
+	manifests map[v1.Hash]bool
+}
+
+// loadDockerCosignPublicKey returns the public key of the secret, or nil if it is not set.
+func loadDockerCosignPublicKey() (crypto.PublicKey, error) {
+	content, ok, err := readSecretFile(DockerCosignPublicKeyPath)
+	if err != nil || !ok {
+		return nil, err
+	}
+	block, _ := pem.Decode(content)
+	if block == nil {
+		return nil, errors.New("cosign public key is not PEM encoded")
+	}
+	key, err := x509.ParsePKIXPublicKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse cosign public key: %w", err)
+	}
+	return key, nil
+}
+
+// fetchDockerAttachments finds the attachments of each subject, the digests of the index and
+// images pulled. The attestation manifests of the index are read if it is set.
+func fetchDockerAttachments(
+	ctx context.Context,
+	repo name.Repository,
+	subjects []v1.Hash,
+	idx v1.ImageIndex,
+	opts ...remote.Option,
+) *dockerAttachments {
+	l := log.FromContext(ctx)
+	attachments := &dockerAttachments{manifests: map[v1.Hash]bool{}}
+
+	for _, subject := range subjects {
+		tagPrefix := strings.Replace(subject.String(), ":", "-", 1)
+		for _, suffix := range dockerCosignTagSuffixes {
+			img, err := remote.Image(repo.Tag(tagPrefix+suffix), opts...)
+			var terr *transport.Error
+			if errors.As(err, &terr) && terr.StatusCode == http.StatusNotFound {
+				continue
+			}
+			if err != nil {
+				l.Error(err, "failed to read cosign attachment", "tag", tagPrefix+suffix)
+				continue
+			}
+			attachments.add(ctx, img, subject, DockerAttachmentSourceTag, "")
+		}
+
+		referrers, err := remote.Referrers(repo.Digest(subject.String()), opts...)
+		if err != nil {
+			l.Error(err, "failed to list referrers", "subject", subject.String())
+		} else if manifest, err := referrers.IndexManifest(); err != nil {
+			l.Error(err, "failed to read referrers index", "subject", subject.String())
+		} else {
+			for _, desc := range manifest.Manifests {
+				img, err := remote.Image(repo.Digest(desc.Digest.String()), opts...)
+				if err != nil {
+					l.Error(err, "failed to read referrer", "digest", desc.Digest.String())
+					continue
+				}
+				attachments.add(ctx, img, subject, DockerAttachmentSourceReferrer, desc.ArtifactType)
+			}
+		}
+
+		if idx == nil {
+			continue
+		}
+		manifest, err := idx.IndexManifest()
+		if err != nil {
+			l.Error(err, "failed to read index manifest")
+			continue
+		}
+		for _, desc := range manifest.Manifests {
+			if desc.Annotations["vnd.docker.reference.type"] != "attestation-manifest" ||
+				desc.Annotations["vnd.docker.reference.digest"] != subject.String() {
+				continue
+			}
+			img, err := idx.Image(desc.Digest)
+			if err != nil {
+				l.Error(err, "failed to read attestation manifest", "digest", desc.Digest.String())
+				continue
+			}
+			attachments.add(ctx, img, subject, DockerAttachmentSourceIndex, "")
+		}
+	}
+	return attachments
+}
+
+// add reads the blobs of the attachment manifest, classifying each as a signature,
+// attestation or SBOM by its media type or the artifact type of the manifest.
+func (a *dockerAttachments) add(ctx context.Context, img v1.Image, subject v1.Hash, source, artifactType string) {
+	l := log.FromContext(ctx)
+	digest, err := img.Digest()
+	if err != nil || a.manifests[digest] {
+		return
+	}
+	a.manifests[digest] = true
+
+	manifest, err := img.Manifest()
+	if err != nil {
+		l.Error(err, "failed to read attachment manifest", "digest", digest.String())
+		return
+	}
+	if artifactType == "" {
+		// the artifact type of a manifest without one is the media type of its config
+		artifactType = string(manifest.Config.MediaType)
+	}
+	for _, desc := range manifest.Layers {
+		mediaType := string(desc.MediaType)
+		kind := a.classify(mediaType, artifactType)
+		if kind == nil {
+			continue
+		}
+		if desc.Size > dockerAttachmentMaxSize {
+			l.Info("skipping attachment larger than the maximum size",
+				"digest", desc.Digest.String(), "size", desc.Size)
+			continue
+		}
+		blob, err := readDockerBlob(img, desc.Digest)
+		if err != nil {
+			l.Error(err, "failed to read attachment", "digest", desc.Digest.String())
+			continue
+		}
+
+		attachment := DockerAttachment{
+			Subject:     subject.String(),
+			Source:      source,
+			Manifest:    digest.String(),
+			Digest:      desc.Digest.String(),
+			MediaType:   mediaType,
+			Annotations: desc.Annotations,
+			Content:     blob,
+		}
+		// sigstore bundles hold either a DSSE envelope or the signature of a message
+		if strings.HasPrefix(mediaType, sigstoreBundleMediaType) && !bytes.Contains(blob, []byte(`"dsseEnvelope"`)) {
+			kind = &a.signatures
+		}
+		if kind == &a.attestations {
+			attachment.PredicateType = dockerPredicateType(desc.Annotations, blob)
+		}
+		*kind = append(*kind, attachment)
+	}
+}
+
+// classify returns the list of attachments a blob
+// of the media type belongs to, or nil if it is not an attachment.
+func (a *dockerAttachments) classify(mediaType, artifactType string) *[]DockerAttachment {
+	switch {
+	case mediaType == cosignSimpleSigningMediaType:
+		return &a.signatures
+	case mediaType == dsseEnvelopeMediaType, mediaType == inTotoMediaType,
+		strings.HasPrefix(mediaType, sigstoreBundleMediaType):
+		return &a.attestations
+	case isDockerSBOMMediaType(mediaType), isDockerSBOMMediaType(artifactType):
+		return &a.sboms
+	}
+	return nil
+}
+
+func isDockerSBOMMediaType(mediaType string) bool {
+	mediaType, _, _ = strings.Cut(mediaType, ";")
+	for _, sbomType := range dockerSBOMMediaTypes {
+		if strings.EqualFold(mediaType, sbomType) {
+			return true
+		}
+	}
+	return false
+}
+
+// readDockerBlob returns the content of the blob of the attachment, as stored in the registry.
+func readDockerBlob(img v1.Image, digest v1.Hash) ([]byte, error) {
+	layer, err := img.LayerByDigest(digest)
+	if err != nil {
+		return nil, err
+	}
+	rc, err := layer.Compressed()
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = rc.Close()
+	}()
+	return io.ReadAll(io.LimitReader(rc, dockerAttachmentMaxSize))
+}
+
+// dockerPredicateType returns the predicate type of an attestation, from
+// its annotations or else from the in-toto statement of the blob.
+func dockerPredicateType(annotations map[string]string, blob []byte) string {
+	for _, key := range dockerPredicateTypeAnnotations {
+		if predicateType := annotations[key]; predicateType != "" {
+			return predicateType
+		}
+	}
+	statement, _, _ := decodeDSSEEnvelope(blob)
+	var decoded struct {
+		PredicateType string `json:"predicateType"`
+	}
+	if err := json.Unmarshal(statement, &decoded); err != nil {
+		return ""
+	}
+	return decoded.PredicateType
+}
+
+// decodeDSSEEnvelope returns the payload, payload type and signatures of a DSSE envelope,
+// either as is or in a sigstore bundle. A blob that is not an envelope is returned as is.
+func decodeDSSEEnvelope(blob []byte) (payload []byte, payloadType string, signatures [][]byte) {
+	var envelope struct {
+		PayloadType string `json:"payloadType"`
+		Payload     string `json:"payload"`
+		Signatures  []struct {
+			Sig string `json:"sig"`
+		} `json:"signatures"`
+		DSSEEnvelope json.RawMessage `json:"dsseEnvelope"`
+	}
+	if err := json.Unmarshal(blob, &envelope); err != nil {
+		return blob, "", nil
+	}
+	if len(envelope.DSSEEnvelope) > 0 {
+		return decodeDSSEEnvelope(envelope.DSSEEnvelope)
+	}
+	if envelope.Payload == "" {
+		return blob, "", nil
+	}
+	payload, err := base64.StdEncoding.DecodeString(envelope.Payload)
+	if err != nil {
+		return blob, "", nil
+	}
+	for _, s := range envelope.Signatures {
+		if sig, err := base64.StdEncoding.DecodeString(s.Sig); err == nil {
+			signatures = append(signatures, sig)
+		}
+	}
+	return payload, envelope.PayloadType, signatures
+}
+
+// verify checks the signatures and attestations against the public key. Cosign signatures
+// must sign a payload naming their subject, and attestations an in-toto statement with
+// their subject, so that a valid signature of another image is not accepted. The message
+// signatures of sigstore bundles are left unverified, since only cosign payloads are supported.
+func (a *dockerAttachments) verify(key crypto.PublicKey) *DockerVerificationMetadata {
+	verification := &DockerVerificationMetadata{}
+	for i := range a.signatures {
+		s := &a.signatures[i]
+		if s.MediaType != cosignSimpleSigningMediaType {
+			// the message signatures of sigstore bundles are not verified
+			continue
+		}
+		sig, err := base64.StdEncoding.DecodeString(s.Annotations[cosignSignatureAnnotation])
+		payload := s.Content
+		valid := err == nil && len(sig) > 0 &&
+			cosignPayloadDigest(payload) == s.Subject && verifyDockerSignature(key, payload, sig)
+		s.Verified = ptr.To(valid)
+		if valid {
+			verification.VerifiedSignatures++
+		}
+	}
+	for i := range a.attestations {
+		s := &a.attestations[i]
+		payload, payloadType, sigs := decodeDSSEEnvelope(s.Content)
+		valid := false
+		if len(sigs) > 0 && inTotoStatementHasSubject(payload, s.Subject) {
+			pae := dssePreAuthEncoding(payloadType, payload)
+			for _, sig := range sigs {
+				if verifyDockerSignature(key, pae, sig) {
+					valid = true
+					break
+				}
+			}
+		}
+		s.Verified = ptr.To(valid)
+		if valid {
+			verification.VerifiedAttestations++
+		}
+	}
+	verification.Verified = verification.VerifiedSignatures+verification.VerifiedAttestations > 0
+	return verification
+}
+
+// cosignPayloadDigest returns the image digest a cosign simple signing payload is for.
+func cosignPayloadDigest(payload []byte) string {
+	var simpleSigning struct {
+		Critical struct {
+			Image struct {
+				DockerManifestDigest string `json:"docker-manifest-digest"`
+			} `json:"image"`
+		} `json:"critical"`
+	}
+	if err := json.Unmarshal(payload, &simpleSigning); err != nil {
+		return ""
+	}
+	return simpleSigning.Critical.Image.DockerManifestDigest
+}
+
+// inTotoStatementHasSubject reports whether the in-toto statement has the digest as a subject.
+func inTotoStatementHasSubject(statement []byte, digest string) bool {
+	var decoded struct {
+		Subject []struct {
+			Digest map[string]string `json:"digest"`
+		} `json:"subject"`
+	}
+	if err := json.Unmarshal(statement, &decoded); err != nil {
+		return false
+	}
+	algorithm, hex, _ := strings.Cut(digest, ":")
+	for _, subject := range decoded.Subject {
+		if subject.Digest[algorithm] == hex {
+			return true
+		}
+	}
+	return false
+}
+
+// dssePreAuthEncoding returns the message signed for a DSSE envelope.
+func dssePreAuthEncoding(payloadType string, payload []byte) []byte {
+	return fmt.Appendf(nil, "DSSEv1 %d %s %d %s", len(payloadType), payloadType, len(payload), payload)
+}
+
+// verifyDockerSignature reports whether the signature of the message is valid for the key,
+// hashed as sigstore does for the type and size of the key.
+func verifyDockerSignature(key crypto.PublicKey, message, sig []byte) bool {
+	switch k := key.(type) {
+	case *ecdsa.PublicKey:
+		hash := crypto.SHA256
+		switch k.Curve {
+		case elliptic.P384():
+			hash = crypto.SHA384
+		case elliptic.P521():
+			hash = crypto.SHA512
+		}
+		h := hash.New()
+		h.Write(message)
+		return ecdsa.VerifyASN1(k, h.Sum(nil), sig)
+	case *rsa.PublicKey:
+		h := crypto.SHA256.New()
+		h.Write(message)
+		return rsa.VerifyPKCS1v15(k, crypto.SHA256, h.Sum(nil), sig) == nil
+	case ed25519.PublicKey:
+		return ed25519.Verify(k, message, sig)
+	}
+	return false
+}
+
+// write saves the attachments of each kind to their metadata file, if any were found.
+func (a *dockerAttachments) write(ctx context.Context) {
+	l := log.FromContext(ctx)
+	for metadataPath, list := range map[string][]DockerAttachment{
+		DockerSignaturesMetadataPath:   a.signatures,
+		DockerAttestationsMetadataPath: a.attestations,
+		DockerSBOMsMetadataPath:        a.sboms,
+	} {
+		if len(list) == 0 {
+			continue
+		}
+		if err := writeJSONStruct(metadataPath, list); err != nil {
+			l.Error(err, "failed to write attachments", "path", metadataPath)
+		}
+	}
+}
+
+// metadata returns the number of attachments of each kind.
+func (a *dockerAttachments) metadata() *DockerAttachmentsMetadata {
+	return &DockerAttachmentsMetadata{
+		Signatures:   len(a.signatures),
+		Attestations: len(a.attestations),
+		SBOMs:        len(a.sboms),
+	}
+}
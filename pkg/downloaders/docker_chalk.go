@@ -0,0 +1,456 @@
+// Copyright (C) 2025-2026 Crash Override, Inc.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the FSF, either version 3 of the License, or (at your option) any later version.
+// See the LICENSE file in the root of this repository for full license text or
+// visit: <https://www.gnu.org/licenses/gpl-3.0.html>.
+
+package downloaders
+
+import (
+	"archive/tar"
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/crashappsec/ocular/api/v1beta1"
+	securejoin "github.com/cyphar/filepath-securejoin"
+	"github.com/google/go-containerregistry/pkg/name"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/layout"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+	"github.com/google/go-containerregistry/pkg/v1/tarball"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+const (
+	DockerChalkPathsParamName = "CHALK_PATHS"
+)
+
+const (
+	// DockerChalkSourceLayer, DockerChalkSourceLabel and DockerChalkSourceReferrer
+	// are the places of an image a chalk mark can be found in.
+	DockerChalkSourceLayer    = "layer"
+	DockerChalkSourceLabel    = "label"
+	DockerChalkSourceReferrer = "referrer"
+)
+
+// chalkMagic is the value of the 'MAGIC' key of every chalk mark.
+const chalkMagic = "dadfedabbadabbed"
+
+// dockerChalkMaxSize is the maximum size of a file or blob read as a chalk mark.
+const dockerChalkMaxSize = 16 << 20
+
+var dockerChalkParameters = []v1beta1.ParameterDefinition{
+	{
+		Name: DockerChalkPathsParamName,
+		Description: "Comma separated list of paths of the image filesystem a chalk mark is read from, " +
+			"in order of preference. The layers are scanned from the top, and paths removed by a whiteout " +
+			"in an upper layer are ignored in the layers below. If no layer has a mark, it is read from " +
+			"the image labels, then from the attestations of the image found with the OCI referrers API. " +
+			"The layers are read from the output written rather than pulled again, and with the 'rootfs' " +
+			"format, the paths are read from the root filesystem instead.",
+		Default: ptr.To(chalkFileName),
+	},
+}
+
+// DockerChalkMetadata records where the chalk mark of the image was found.
+type DockerChalkMetadata struct {
+	// Source is one of 'layer', 'label' or 'referrer'.
+	Source string `json:"source"`
+	// Layer is the digest of the layer the mark was read from, and Path its path in the layer.
+	// Layer is not set for the 'rootfs' format, since the layers are applied already.
+	Layer string `json:"layer,omitempty"`
+	Path  string `json:"path,omitempty"`
+	// Label is the key of the image label the mark was read from.
+	Label string `json:"label,omitempty"`
+	// Referrer is the digest of the manifest of the referrer the mark was read from.
+	Referrer string `json:"referrer,omitempty"`
+}
+
+// parseDockerChalkPaths returns the cleaned chalk mark paths of the parameter, relative to the root.
+func parseDockerChalkPaths(params map[string]string) []string {
+	var paths []string
+	for _, p := range strings.Split(params[DockerChalkPathsParamName], ",") {
+		if p = strings.TrimSpace(p); p == "" {
+			continue
+		}
+		p = strings.TrimPrefix(path.Clean("/"+p), "/")
+		if p != "" && !slices.Contains(paths, p) {
+			paths = append(paths, p)
+		}
+	}
+	if len(paths) == 0 {
+		paths = []string{chalkFileName}
+	}
+	return paths
+}
+
+// extractChalk finds the chalk mark of the image and writes it to the chalk path. The layers of
+// the image written to the output in the format are scanned first, then the labels and the
+// referrers of the image. Nil is returned if the image has no chalk mark.
+func extractChalk(
+	ctx context.Context,
+	ref name.Reference,
+	img v1.Image,
+	format, output string,
+	paths []string,
+	chalkPath string,
+	opts ...remote.Option,
+) (*DockerChalkMetadata, error) {
+	l := log.FromContext(ctx)
+
+	mark, metadata, err := findOutputChalkMark(ctx, img, format, output, paths)
+	if err != nil {
+		l.Error(err, "failed to scan image layers for chalk mark")
+	}
+	if mark == nil {
+		if mark, metadata, err = findLabelChalkMark(img); err != nil {
+			l.Error(err, "failed to read image labels for chalk mark")
+		}
+	}
+	if mark == nil {
+		if mark, metadata, err = findReferrerChalkMark(ctx, ref, img, opts...); err != nil {
+			l.Error(err, "failed to read image referrers for chalk mark")
+		}
+	}
+	if mark == nil {
+		return nil, nil
+	}
+
+	if err = os.WriteFile(filepath.Clean(chalkPath), mark, 0o644); err != nil {
+		return nil, fmt.Errorf("writing chalk metadata file: %w", err)
+	}
+	return metadata, nil
+}
+
+// findOutputChalkMark finds a chalk mark at one of the paths of the image written to the output
+// in the format, so that its layers are read from the output rather than pulled again.
+func findOutputChalkMark(
+	ctx context.Context,
+	img v1.Image,
+	format, output string,
+	paths []string,
+) ([]byte, *DockerChalkMetadata, error) {
+	var (
+		written v1.Image
+		err     error
+	)
+	switch format {
+	case DockerOutputFormatRootfs:
+		return findRootfsChalkMark(ctx, output, paths)
+	case DockerOutputFormatOCILayout:
+		var digest v1.Hash
+		if digest, err = img.Digest(); err == nil {
+			written, err = layout.Path(output).Image(digest)
+		}
+	default:
+		written, err = tarball.ImageFromPath(output, nil)
+	}
+	if err != nil {
+		return nil, nil, fmt.Errorf("reading written image: %w", err)
+	}
+	return findLayerChalkMark(ctx, written, paths)
+}
+
+// findRootfsChalkMark returns the first file at one of the paths of the root filesystem
+// that is a chalk mark. As in the layers, a symbolic link at a path is not followed.
+func findRootfsChalkMark(ctx context.Context, root string, paths []string) ([]byte, *DockerChalkMetadata, error) {
+	l := log.FromContext(ctx)
+	for _, p := range paths {
+		dir, base := path.Split(p)
+		parent, err := securejoin.SecureJoin(root, dir)
+		if err != nil {
+			return nil, nil, fmt.Errorf("resolving %q: %w", p, err)
+		}
+		file := filepath.Join(parent, base)
+		fi, err := os.Lstat(file)
+		if errors.Is(err, os.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return nil, nil, err
+		}
+		if !fi.Mode().IsRegular() {
+			continue
+		}
+
+		chalkFile := dockerChalkFile{tooLarge: fi.Size() > dockerChalkMaxSize}
+		if !chalkFile.tooLarge {
+			if chalkFile.content, err = os.ReadFile(filepath.Clean(file)); err != nil {
+				return nil, nil, fmt.Errorf("reading %q: %w", p, err)
+			}
+		}
+		mark, err := chalkFile.mark()
+		if err != nil {
+			l.Info("ignoring invalid chalk mark", "path", p, "reason", err.Error())
+			continue
+		}
+		return mark, &DockerChalkMetadata{Source: DockerChalkSourceLayer, Path: "/" + p}, nil
+	}
+	return nil, nil, nil
+}
+
+// findLayerChalkMark scans the layers of the image from the top for a file at one of the paths.
+// A path is no longer looked for in the lower layers once an upper layer has an entry at it,
+// even if not a valid chalk mark, or removes it or one of its parents with a whiteout.
+func findLayerChalkMark(ctx context.Context, img v1.Image, paths []string) ([]byte, *DockerChalkMetadata, error) {
+	l := log.FromContext(ctx)
+	layers, err := img.Layers()
+	if err != nil {
+		return nil, nil, fmt.Errorf("reading image layers: %w", err)
+	}
+
+	remaining := slices.Clone(paths)
+	for i := len(layers) - 1; i >= 0 && len(remaining) > 0; i-- {
+		layer := layers[i]
+		mediaType, err := layer.MediaType()
+		if err != nil {
+			return nil, nil, fmt.Errorf("reading media type of layer %d: %w", i, err)
+		}
+		if !mediaType.IsLayer() {
+			continue
+		}
+		rc, err := layer.Uncompressed()
+		if err != nil {
+			return nil, nil, fmt.Errorf("reading layer %d: %w", i, err)
+		}
+		found, shadowed, err := scanChalkLayer(rc, remaining)
+		if closeErr := rc.Close(); closeErr != nil {
+			l.Error(closeErr, "failed to close layer reader", "layer", i)
+		}
+		if err != nil {
+			return nil, nil, fmt.Errorf("reading layer %d: %w", i, err)
+		}
+
+		// the paths are in order of preference, so the first one found in the layer is used
+		for _, p := range remaining {
+			file, ok := found[p]
+			if !ok {
+				continue
+			}
+			mark, err := file.mark()
+			if err != nil {
+				l.Info("ignoring invalid chalk mark", "layer", i, "path", p, "reason", err.Error())
+				continue
+			}
+			metadata := &DockerChalkMetadata{Source: DockerChalkSourceLayer, Path: "/" + p}
+			if digest, err := layer.Digest(); err == nil {
+				metadata.Layer = digest.String()
+			}
+			return mark, metadata, nil
+		}
+		remaining = slices.DeleteFunc(remaining, func(p string) bool {
+			_, ok := shadowed[p]
+			return ok
+		})
+	}
+	return nil, nil, nil
+}
+
+// dockerChalkFile is a regular file found at one of the chalk mark paths.
+type dockerChalkFile struct {
+	content []byte
+	// tooLarge is set instead of the content if the file is larger than [dockerChalkMaxSize].
+	tooLarge bool
+}
+
+// mark returns the content of the file if it is a valid chalk mark.
+func (f dockerChalkFile) mark() ([]byte, error) {
+	if f.tooLarge {
+		return nil, fmt.Errorf("larger than %d bytes", dockerChalkMaxSize)
+	}
+	if err := validateChalkMark(f.content); err != nil {
+		return nil, err
+	}
+	return f.content, nil
+}
+
+// scanChalkLayer reads the layer tarball, returning the regular files at the paths, along
+// with the paths the layer has an entry at or removes with a whiteout, which are shadowed
+// in the lower layers.
+func scanChalkLayer(
+	r io.Reader,
+	paths []string,
+) (found map[string]dockerChalkFile, shadowed map[string]bool, err error) {
+	found = map[string]dockerChalkFile{}
+	shadowed = map[string]bool{}
+	tr := tar.NewReader(r)
+	for {
+		hdr, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			return found, shadowed, nil
+		}
+		if err != nil {
+			return nil, nil, err
+		}
+
+		entry := strings.TrimPrefix(path.Clean("/"+hdr.Name), "/")
+		dir, base := path.Split(entry)
+		switch {
+		case base == dockerOpaqueWhiteout:
+			for _, p := range paths {
+				if dir == "" || strings.HasPrefix(p, dir) {
+					shadowed[p] = true
+				}
+			}
+			continue
+		case strings.HasPrefix(base, dockerWhiteoutPrefix):
+			removed := dir + strings.TrimPrefix(base, dockerWhiteoutPrefix)
+			for _, p := range paths {
+				if p == removed || strings.HasPrefix(p, removed+"/") {
+					shadowed[p] = true
+				}
+			}
+			continue
+		case !slices.Contains(paths, entry):
+			continue
+		}
+
+		shadowed[entry] = true
+		if hdr.Typeflag != tar.TypeReg {
+			delete(found, entry)
+			continue
+		}
+		if hdr.Size > dockerChalkMaxSize {
+			found[entry] = dockerChalkFile{tooLarge: true}
+			continue
+		}
+		content, err := io.ReadAll(tr)
+		if err != nil {
+			return nil, nil, fmt.Errorf("reading %q: %w", hdr.Name, err)
+		}
+		found[entry] = dockerChalkFile{content: content}
+	}
+}
+
+// findLabelChalkMark returns the first image label, in order of key, whose value is a chalk mark.
+func findLabelChalkMark(img v1.Image) ([]byte, *DockerChalkMetadata, error) {
+	cf, err := img.ConfigFile()
+	if err != nil {
+		return nil, nil, fmt.Errorf("reading image config: %w", err)
+	}
+	labels := cf.Config.Labels
+	keys := make([]string, 0, len(labels))
+	for key := range labels {
+		keys = append(keys, key)
+	}
+	slices.Sort(keys)
+	for _, key := range keys {
+		mark := []byte(strings.TrimSpace(labels[key]))
+		if validateChalkMark(mark) == nil {
+			return mark, &DockerChalkMetadata{Source: DockerChalkSourceLabel, Label: key}, nil
+		}
+	}
+	return nil, nil, nil
+}
+
+// findReferrerChalkMark returns the first chalk mark found in the blobs of the referrers
+// of the image, either as is or as the predicate of an in-toto statement, which may be
+// wrapped in a DSSE envelope as attached by cosign.
+func findReferrerChalkMark(
+	ctx context.Context,
+	ref name.Reference,
+	img v1.Image,
+	opts ...remote.Option,
+) ([]byte, *DockerChalkMetadata, error) {
+	l := log.FromContext(ctx)
+	digest, err := img.Digest()
+	if err != nil {
+		return nil, nil, err
+	}
+	repo := ref.Context()
+	idx, err := remote.Referrers(repo.Digest(digest.String()), opts...)
+	if err != nil {
+		return nil, nil, fmt.Errorf("listing referrers: %w", err)
+	}
+	manifest, err := idx.IndexManifest()
+	if err != nil {
+		return nil, nil, fmt.Errorf("reading referrers index: %w", err)
+	}
+
+	for _, desc := range manifest.Manifests {
+		referrer, err := remote.Image(repo.Digest(desc.Digest.String()), opts...)
+		if err != nil {
+			l.Error(err, "failed to read referrer", "digest", desc.Digest.String())
+			continue
+		}
+		layers, err := referrer.Layers()
+		if err != nil {
+			l.Error(err, "failed to read referrer blobs", "digest", desc.Digest.String())
+			continue
+		}
+		for _, layer := range layers {
+			if size, err := layer.Size(); err != nil || size > dockerChalkMaxSize {
+				continue
+			}
+			rc, err := layer.Compressed()
+			if err != nil {
+				continue
+			}
+			blob, err := io.ReadAll(rc)
+			_ = rc.Close()
+			if err != nil {
+				continue
+			}
+			if mark := chalkMarkFromAttestation(blob); mark != nil {
+				return mark, &DockerChalkMetadata{
+					Source:   DockerChalkSourceReferrer,
+					Referrer: desc.Digest.String(),
+				}, nil
+			}
+		}
+	}
+	return nil, nil, nil
+}
+
+// chalkMarkFromAttestation returns the chalk mark of the blob, which is either the mark
+// itself, or an in-toto statement with the mark as predicate, optionally in a DSSE envelope.
+func chalkMarkFromAttestation(blob []byte) []byte {
+	blob = bytes.TrimSpace(blob)
+	if validateChalkMark(blob) == nil {
+		return blob
+	}
+
+	var envelope struct {
+		Payload string `json:"payload"`
+	}
+	if err := json.Unmarshal(blob, &envelope); err == nil && envelope.Payload != "" {
+		if payload, err := base64.StdEncoding.DecodeString(envelope.Payload); err == nil {
+			blob = payload
+		}
+	}
+	var statement struct {
+		Predicate json.RawMessage `json:"predicate"`
+	}
+	if err := json.Unmarshal(blob, &statement); err != nil || len(statement.Predicate) == 0 {
+		return nil
+	}
+	if validateChalkMark(statement.Predicate) == nil {
+		return statement.Predicate
+	}
+	return nil
+}
+
+// validateChalkMark returns an error if the content is not a JSON object with the chalk magic.
+func validateChalkMark(content []byte) error {
+	var mark map[string]any
+	if err := json.Unmarshal(content, &mark); err != nil {
+		return fmt.Errorf("not a JSON object: %w", err)
+	}
+	if magic, _ := mark["MAGIC"].(string); magic != chalkMagic {
+		return errors.New("missing chalk magic")
+	}
+	return nil
+}
@@ -0,0 +1,343 @@
+// Copyright (C) 2025-2026 Crash Override, Inc.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the FSF, either version 3 of the License, or (at your option) any later version.
+// See the LICENSE file in the root of this repository for full license text or
+// visit: <https://www.gnu.org/licenses/gpl-3.0.html>.
+
+package downloaders
+
+import (
+	"archive/tar"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/crashappsec/ocular/api/v1beta1"
+	securejoin "github.com/cyphar/filepath-securejoin"
+	"github.com/google/go-containerregistry/pkg/name"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/empty"
+	"github.com/google/go-containerregistry/pkg/v1/layout"
+	"github.com/google/go-containerregistry/pkg/v1/tarball"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+const (
+	DockerOutputFormatParamName = "OUTPUT_FORMAT"
+)
+
+const (
+	DockerOutputFormatArchive   = "docker-archive"
+	DockerOutputFormatOCILayout = "oci-layout"
+	DockerOutputFormatRootfs    = "rootfs"
+)
+
+const (
+	// DockerManifestFileName and DockerConfigFileName are the files the
+	// manifest and config of the image are written to, next to the output.
+	DockerManifestFileName = "image-manifest.json"
+	DockerConfigFileName   = "image-config.json"
+)
+
+// dockerDefaultOutputs are the default names of the output of each format.
+var dockerDefaultOutputs = map[string]string{
+	DockerOutputFormatArchive:   "target.tar",
+	DockerOutputFormatOCILayout: "oci",
+	DockerOutputFormatRootfs:    "rootfs",
+}
+
+var dockerOutputParameters = []v1beta1.ParameterDefinition{
+	{
+		Name: DockerOutputFormatParamName,
+		Description: "Format the image is written in, one of 'docker-archive', 'oci-layout' or 'rootfs'. " +
+			"'docker-archive' writes a tarball as created by 'docker save', 'oci-layout' writes an OCI image " +
+			"layout directory, and 'rootfs' writes the root filesystem of the image, with its layers applied " +
+			"in order and whiteout files removing the paths they hide. The manifest and config of the image " +
+			"are written next to the output, as " + DockerManifestFileName + " and " + DockerConfigFileName + ".",
+		Default: ptr.To(DockerOutputFormatArchive),
+	},
+	{
+		Name: DockerTarOutputParamName,
+		Description: "Name of the output in the target directory, the tarball for 'docker-archive' or the " +
+			"directory for 'oci-layout' and 'rootfs'. Defaults to 'target.tar', 'oci' and 'rootfs' respectively.",
+		Default: ptr.To(""),
+	},
+}
+
+// dockerOCIRefNameAnnotation is the annotation of the tag of an image in the index of an OCI image layout.
+const dockerOCIRefNameAnnotation = "org.opencontainers.image.ref.name"
+
+// dockerWhiteoutPrefix marks a file of a layer that removes the path of the same name
+// from the lower layers, and dockerOpaqueWhiteout a file that removes all the contents
+// of its directory from the lower layers.
+const (
+	dockerWhiteoutPrefix = ".wh."
+	dockerOpaqueWhiteout = dockerWhiteoutPrefix + dockerWhiteoutPrefix + ".opq"
+)
+
+// parseDockerOutput returns the output format, and the name of the output in the target directory.
+func parseDockerOutput(params map[string]string) (string, string, error) {
+	format := strings.ToLower(strings.TrimSpace(params[DockerOutputFormatParamName]))
+	if format == "" {
+		format = DockerOutputFormatArchive
+	}
+	output, ok := dockerDefaultOutputs[format]
+	if !ok {
+		return "", "", fmt.Errorf("unsupported output format %q", format)
+	}
+	if o := strings.TrimSpace(params[DockerTarOutputParamName]); o != "" {
+		output = o
+	}
+	if !filepath.IsLocal(output) {
+		return "", "", fmt.Errorf("invalid output %q, must be a path in the target directory", output)
+	}
+	return format, output, nil
+}
+
+// writeDockerOutput writes the image in the format to the output path,
+// along with its manifest and config in the directory of the output.
+func writeDockerOutput(ctx context.Context, ref name.Reference, img v1.Image, tag, format, output string) error {
+	l := log.FromContext(ctx)
+
+	switch format {
+	case DockerOutputFormatArchive:
+		tarFile, err := os.Create(filepath.Clean(output))
+		if err != nil {
+			return fmt.Errorf("unable to create tar file: %w", err)
+		}
+		defer func() {
+			if err := tarFile.Close(); err != nil {
+				l.Error(err, "failed to close tar file")
+			}
+		}()
+		if err = tarball.Write(ref, img, tarFile); err != nil {
+			return fmt.Errorf("writing tarball: %w", err)
+		}
+	case DockerOutputFormatOCILayout:
+		p, err := layout.Write(output, empty.Index)
+		if err != nil {
+			return fmt.Errorf("creating OCI image layout: %w", err)
+		}
+		var opts []layout.Option
+		if tag != "" {
+			opts = append(opts, layout.WithAnnotations(map[string]string{dockerOCIRefNameAnnotation: tag}))
+		}
+		if err = p.AppendImage(img, opts...); err != nil {
+			return fmt.Errorf("writing OCI image layout: %w", err)
+		}
+	case DockerOutputFormatRootfs:
+		if err := extractDockerRootfs(ctx, img, output); err != nil {
+			return fmt.Errorf("extracting root filesystem: %w", err)
+		}
+	}
+
+	dir := filepath.Dir(output)
+	manifest, err := img.RawManifest()
+	if err != nil {
+		return fmt.Errorf("reading image manifest: %w", err)
+	}
+	if err = os.WriteFile(filepath.Join(dir, DockerManifestFileName), manifest, 0o644); err != nil {
+		return fmt.Errorf("writing image manifest: %w", err)
+	}
+	config, err := img.RawConfigFile()
+	if err != nil {
+		return fmt.Errorf("reading image config: %w", err)
+	}
+	if err = os.WriteFile(filepath.Join(dir, DockerConfigFileName), config, 0o644); err != nil {
+		return fmt.Errorf("writing image config: %w", err)
+	}
+	return nil
+}
+
+// extractDockerRootfs applies the layers of the image in order to the directory.
+func extractDockerRootfs(ctx context.Context, img v1.Image, dir string) error {
+	l := log.FromContext(ctx)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return err
+	}
+
+	layers, err := img.Layers()
+	if err != nil {
+		return fmt.Errorf("reading image layers: %w", err)
+	}
+	for i, layer := range layers {
+		rc, err := layer.Uncompressed()
+		if err != nil {
+			return fmt.Errorf("reading layer %d: %w", i, err)
+		}
+		skipped, err := applyDockerLayer(rc, dir)
+		if closeErr := rc.Close(); closeErr != nil {
+			l.Error(closeErr, "failed to close layer reader", "layer", i)
+		}
+		if err != nil {
+			return fmt.Errorf("applying layer %d: %w", i, err)
+		}
+		if skipped > 0 {
+			l.Info("skipped device and named pipe files of layer", "layer", i, "count", skipped)
+		}
+	}
+	return nil
+}
+
+// applyDockerLayer writes the entries of the layer tarball to the root filesystem in the directory,
+// returning the number of entries skipped. Paths are resolved as if the directory was the root of
+// the filesystem, so that neither the entries nor the symbolic links they go through can refer to
+// files outside of it. Whiteouts only remove the paths of the lower layers, whatever their position
+// in the tarball. Device files and named pipes are skipped, and owners are not preserved.
+func applyDockerLayer(r io.Reader, dir string) (int, error) {
+	var (
+		skipped int
+		written = newDockerLayerPaths()
+	)
+	tr := tar.NewReader(r)
+	for {
+		hdr, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			return skipped, nil
+		}
+		if err != nil {
+			return skipped, err
+		}
+
+		entry := strings.TrimPrefix(path.Clean("/"+hdr.Name), "/")
+		if entry == "" {
+			continue
+		}
+		parentPath, base := path.Split(entry)
+		// the parent is resolved through any symbolic links, but not the entry itself,
+		// which replaces a symbolic link of a lower layer rather than its target
+		parent, err := securejoin.SecureJoin(dir, parentPath)
+		if err != nil {
+			return skipped, fmt.Errorf("resolving %q: %w", hdr.Name, err)
+		}
+
+		if base == dockerOpaqueWhiteout {
+			if err = written.removeLowerContents(parent, strings.TrimSuffix(parentPath, "/")); err != nil {
+				return skipped, fmt.Errorf("applying opaque whiteout %q: %w", hdr.Name, err)
+			}
+			continue
+		}
+		if hidden, ok := strings.CutPrefix(base, dockerWhiteoutPrefix); ok {
+			if hidden == "" || hidden == "." || hidden == ".." || strings.ContainsRune(hidden, filepath.Separator) {
+				return skipped, fmt.Errorf("invalid whiteout %q", hdr.Name)
+			}
+			if err = written.removeLower(filepath.Join(parent, hidden), parentPath+hidden); err != nil {
+				return skipped, fmt.Errorf("applying whiteout %q: %w", hdr.Name, err)
+			}
+			continue
+		}
+
+		if err = os.MkdirAll(parent, 0o755); err != nil {
+			return skipped, err
+		}
+		target := filepath.Join(parent, base)
+		mode := hdr.FileInfo().Mode().Perm()
+
+		// a directory is merged with the directory of a lower layer, any other entry replaces it
+		if fi, err := os.Lstat(target); err == nil && !(fi.IsDir() && hdr.Typeflag == tar.TypeDir) {
+			if err = os.RemoveAll(target); err != nil {
+				return skipped, err
+			}
+		}
+
+		switch hdr.Typeflag {
+		case tar.TypeDir:
+			if err = os.Mkdir(target, 0o755); err != nil && !errors.Is(err, os.ErrExist) {
+				return skipped, err
+			}
+			// directories remain writable, so that the entries of the upper layers can be written
+			err = os.Chmod(target, mode|0o700)
+		case tar.TypeReg:
+			err = writeDockerLayerFile(tr, target, mode)
+		case tar.TypeSymlink:
+			err = os.Symlink(hdr.Linkname, target)
+		case tar.TypeLink:
+			linkParent, linkBase := path.Split(strings.TrimPrefix(path.Clean("/"+hdr.Linkname), "/"))
+			var source string
+			source, err = securejoin.SecureJoin(dir, linkParent)
+			if err == nil {
+				err = os.Link(filepath.Join(source, linkBase), target)
+			}
+		default:
+			skipped++
+			continue
+		}
+		if err != nil {
+			return skipped, fmt.Errorf("writing %q: %w", hdr.Name, err)
+		}
+		written.add(entry)
+	}
+}
+
+// dockerLayerPaths are the paths a layer has written entries at, so that its
+// whiteouts remove the paths of the lower layers only, along with their parents.
+type dockerLayerPaths struct {
+	entries map[string]bool
+	parents map[string]bool
+}
+
+func newDockerLayerPaths() dockerLayerPaths {
+	return dockerLayerPaths{entries: map[string]bool{}, parents: map[string]bool{}}
+}
+
+func (p dockerLayerPaths) add(entry string) {
+	p.entries[entry] = true
+	for parent := path.Dir(entry); parent != "."; parent = path.Dir(parent) {
+		p.parents[parent] = true
+	}
+}
+
+// removeLower removes the file at the entry of the layer, unless the layer
+// wrote it, keeping any entries the layer wrote under it.
+func (p dockerLayerPaths) removeLower(file, entry string) error {
+	if p.entries[entry] {
+		return nil
+	}
+	if fi, err := os.Lstat(file); err == nil && fi.IsDir() && p.parents[entry] {
+		return p.removeLowerContents(file, entry)
+	}
+	return os.RemoveAll(file)
+}
+
+// removeLowerContents removes the contents of the directory at the entry
+// of the layer, if it exists, keeping any entries the layer wrote under it.
+func (p dockerLayerPaths) removeLowerContents(dir, entry string) error {
+	entries, err := os.ReadDir(dir)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	for _, e := range entries {
+		if err = p.removeLower(filepath.Join(dir, e.Name()), path.Join(entry, e.Name())); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func writeDockerLayerFile(r io.Reader, target string, mode os.FileMode) error {
+	// files remain readable, so that they can be scanned by any user
+	f, err := os.OpenFile(filepath.Clean(target), os.O_CREATE|os.O_WRONLY|os.O_TRUNC, mode|0o644)
+	if err != nil {
+		return err
+	}
+	if _, err = io.Copy(f, r); err != nil {
+		_ = f.Close()
+		return err
+	}
+	return f.Close()
+}
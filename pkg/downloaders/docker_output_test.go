@@ -0,0 +1,272 @@
+// Copyright (C) 2025-2026 Crash Override, Inc.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the FSF, either version 3 of the License, or (at your option) any later version.
+// See the LICENSE file in the root of this repository for full license text or
+// visit: <https://www.gnu.org/licenses/gpl-3.0.html>.
+
+package downloaders
+
+import (
+	"archive/tar"
+	"bytes"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+// testLayerEntry is an entry of a layer tarball built by [buildTestLayer].
+type testLayerEntry struct {
+	name     string
+	typeflag byte
+	content  string
+	linkname string
+}
+
+func buildTestLayer(t *testing.T, entries []testLayerEntry) *bytes.Buffer {
+	t.Helper()
+	var buf bytes.Buffer
+	tw := tar.NewWriter(&buf)
+	for _, e := range entries {
+		hdr := &tar.Header{
+			Name:     e.name,
+			Typeflag: e.typeflag,
+			Linkname: e.linkname,
+			Mode:     0o644,
+			Size:     int64(len(e.content)),
+		}
+		if e.typeflag == tar.TypeDir {
+			hdr.Mode = 0o755
+		}
+		if e.typeflag != tar.TypeReg {
+			hdr.Size = 0
+		}
+		if err := tw.WriteHeader(hdr); err != nil {
+			t.Fatalf("writing header of %q: %v", e.name, err)
+		}
+		if hdr.Size > 0 {
+			if _, err := tw.Write([]byte(e.content)); err != nil {
+				t.Fatalf("writing content of %q: %v", e.name, err)
+			}
+		}
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatalf("closing layer: %v", err)
+	}
+	return &buf
+}
+
+// listTestTree returns the sorted paths of the files in the directory, relative to it.
+func listTestTree(t *testing.T, dir string) []string {
+	t.Helper()
+	var files []string
+	err := filepath.WalkDir(dir, func(file string, _ fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if file != dir {
+			rel, _ := filepath.Rel(dir, file)
+			files = append(files, filepath.ToSlash(rel))
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("listing %s: %v", dir, err)
+	}
+	slices.Sort(files)
+	return files
+}
+
+func TestApplyDockerLayer(t *testing.T) {
+	reg := func(name, content string) testLayerEntry {
+		return testLayerEntry{name: name, typeflag: tar.TypeReg, content: content}
+	}
+	dir := func(name string) testLayerEntry {
+		return testLayerEntry{name: name, typeflag: tar.TypeDir}
+	}
+	symlink := func(name, target string) testLayerEntry {
+		return testLayerEntry{name: name, typeflag: tar.TypeSymlink, linkname: target}
+	}
+
+	tests := []struct {
+		name   string
+		layers [][]testLayerEntry
+		// want are the files of the root filesystem, or of the
+		// directory containing it if outside is set
+		want    []string
+		outside bool
+		wantErr bool
+	}{
+		{
+			name: "layers are merged",
+			layers: [][]testLayerEntry{
+				{dir("etc"), reg("etc/a", "a"), reg("etc/b", "b")},
+				{reg("etc/b", "b2"), reg("etc/c", "c")},
+			},
+			want: []string{"etc", "etc/a", "etc/b", "etc/c"},
+		},
+		{
+			name: "whiteout removes lower path",
+			layers: [][]testLayerEntry{
+				{dir("etc"), reg("etc/a", "a"), dir("etc/d"), reg("etc/d/e", "e")},
+				{reg("etc/.wh.a", ""), reg("etc/.wh.d", "")},
+			},
+			want: []string{"etc"},
+		},
+		{
+			name: "whiteout after entry of same layer keeps it",
+			layers: [][]testLayerEntry{
+				{reg("a", "a")},
+				{reg("a", "a2"), reg(".wh.a", "")},
+			},
+			want: []string{"a"},
+		},
+		{
+			name: "whiteout of directory keeps entries of same layer under it",
+			layers: [][]testLayerEntry{
+				{dir("d"), reg("d/lower", "")},
+				{reg("d/upper", ""), reg(".wh.d", "")},
+			},
+			want: []string{"d", "d/upper"},
+		},
+		{
+			name: "opaque whiteout removes lower contents only",
+			layers: [][]testLayerEntry{
+				{dir("d"), reg("d/lower", ""), dir("d/sub"), reg("d/sub/lower", "")},
+				{reg("d/upper", ""), reg("d/sub/upper", ""), reg("d/"+dockerOpaqueWhiteout, "")},
+			},
+			want: []string{"d", "d/sub", "d/sub/upper", "d/upper"},
+		},
+		{
+			name: "whiteout of symbolic link removes the link only",
+			layers: [][]testLayerEntry{
+				{dir("data"), reg("data/f", ""), symlink("link", "/data")},
+				{reg(".wh.link", "")},
+			},
+			want: []string{"data", "data/f"},
+		},
+		{
+			name: "whiteout of parent directory is rejected",
+			layers: [][]testLayerEntry{
+				{dir("d"), reg("d/f", "")},
+				{reg("d/.wh...", "")},
+			},
+			wantErr: true,
+		},
+		{
+			name: "whiteout of parent of root is rejected",
+			layers: [][]testLayerEntry{
+				{reg("f", "")},
+				{reg(".wh...", "")},
+			},
+			wantErr: true,
+		},
+		{
+			name: "whiteout of current directory is rejected",
+			layers: [][]testLayerEntry{
+				{dir("d"), reg("d/f", "")},
+				{reg("d/.wh..", "")},
+			},
+			wantErr: true,
+		},
+		{
+			name: "empty whiteout is rejected",
+			layers: [][]testLayerEntry{
+				{reg("f", "")},
+				{reg(".wh.", "")},
+			},
+			wantErr: true,
+		},
+		{
+			name: "entries with parent references stay in root",
+			layers: [][]testLayerEntry{
+				{reg("../../escape", "")},
+			},
+			want:    []string{"rootfs", "rootfs/escape", "sibling"},
+			outside: true,
+		},
+		{
+			name: "entries through absolute symbolic links stay in root",
+			layers: [][]testLayerEntry{
+				{symlink("link", "/.."), reg("link/escape", "")},
+			},
+			want:    []string{"rootfs", "rootfs/escape", "rootfs/link", "sibling"},
+			outside: true,
+		},
+		{
+			name: "whiteouts through symbolic links stay in root",
+			layers: [][]testLayerEntry{
+				{symlink("link", "../")},
+				{reg("link/.wh.sibling", "")},
+			},
+			want:    []string{"rootfs", "rootfs/link", "sibling"},
+			outside: true,
+		},
+		{
+			name: "opaque whiteouts through symbolic links stay in root",
+			layers: [][]testLayerEntry{
+				{symlink("link", "../")},
+				{reg("link/"+dockerOpaqueWhiteout, "")},
+			},
+			want:    []string{"rootfs", "sibling"},
+			outside: true,
+		},
+		{
+			name: "hard links stay in root",
+			layers: [][]testLayerEntry{
+				{reg("f", "f"), {name: "l", typeflag: tar.TypeLink, linkname: "../../f"}},
+			},
+			want: []string{"f", "l"},
+		},
+		{
+			name: "device files are skipped",
+			layers: [][]testLayerEntry{
+				{{name: "dev/null", typeflag: tar.TypeChar}, reg("f", "")},
+			},
+			want: []string{"dev", "f"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			parent := t.TempDir()
+			if err := os.WriteFile(filepath.Join(parent, "sibling"), nil, 0o600); err != nil {
+				t.Fatal(err)
+			}
+			root := filepath.Join(parent, "rootfs")
+			if err := os.Mkdir(root, 0o755); err != nil {
+				t.Fatal(err)
+			}
+
+			var err error
+			for _, layer := range tt.layers {
+				if _, err = applyDockerLayer(buildTestLayer(t, layer), root); err != nil {
+					break
+				}
+			}
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("expected an error")
+				}
+				if got := listTestTree(t, parent); !slices.Contains(got, "sibling") {
+					t.Errorf("files outside the root filesystem were removed: %v", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			got := listTestTree(t, root)
+			if tt.outside {
+				got = listTestTree(t, parent)
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("got files %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,143 @@
+// Copyright (C) 2025-2026 Crash Override, Inc.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the FSF, either version 3 of the License, or (at your option) any later version.
+// See the LICENSE file in the root of this repository for full license text or
+// visit: <https://www.gnu.org/licenses/gpl-3.0.html>.
+
+package downloaders
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/crashappsec/ocular/api/v1beta1"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+	"k8s.io/utils/ptr"
+)
+
+const (
+	DockerPlatformParamName = "PLATFORM"
+)
+
+const (
+	// DockerDefaultPlatform is the platform pulled from multi-platform images if none is set.
+	DockerDefaultPlatform = "linux/amd64"
+	// dockerAllPlatforms is the value of [DockerPlatformParamName] to pull every platform.
+	dockerAllPlatforms = "all"
+)
+
+var dockerPlatformParameters = []v1beta1.ParameterDefinition{
+	{
+		Name: DockerPlatformParamName,
+		Description: "Platform to pull from multi-platform images, e.g. 'linux/arm64' or 'linux/arm/v7', " +
+			"or 'all' to pull every platform. With 'all', each platform is written to its own directory " +
+			"named after it (e.g. 'linux-arm64/" + dockerDefaultOutputs[DockerOutputFormatArchive] + "'). " +
+			"Attestation manifests are always skipped. Images that are not multi-platform are pulled as is.",
+		Default: ptr.To(DockerDefaultPlatform),
+	},
+}
+
+// dockerPlatformImage is an image to write for a platform,
+// along with the directory of its output relative to the target directory.
+type dockerPlatformImage struct {
+	img      v1.Image
+	platform string
+	dir      string
+}
+
+// DockerPlatformMetadata records an image written for one of
+// the platforms of a multi-platform image pulled with 'all'.
+type DockerPlatformMetadata struct {
+	Platform string `json:"platform"`
+	// SHA is the digest of the manifest of the platform image.
+	SHA string `json:"sha"`
+	// Output is the path of the output relative to the target directory.
+	Output string `json:"output"`
+}
+
+// selectDockerPlatforms returns the images of the descriptor for the platform
+// parameter. The descriptor of an image is returned as is, while the images of an
+// index are selected by platform, each written to its own directory if all are selected.
+func selectDockerPlatforms(desc *remote.Descriptor, platformParam string) ([]dockerPlatformImage, error) {
+	platformParam = strings.TrimSpace(platformParam)
+	if platformParam == "" {
+		platformParam = DockerDefaultPlatform
+	}
+	all := strings.EqualFold(platformParam, dockerAllPlatforms)
+	var requested *v1.Platform
+	if !all {
+		p, err := v1.ParsePlatform(platformParam)
+		if err != nil {
+			return nil, fmt.Errorf("invalid value for %s: %w", DockerPlatformParamName, err)
+		}
+		requested = p
+	}
+
+	if !desc.MediaType.IsIndex() {
+		img, err := desc.Image()
+		if err != nil {
+			return nil, err
+		}
+		var platform string
+		if cf, err := img.ConfigFile(); err == nil && cf.Platform() != nil {
+			platform = cf.Platform().String()
+		}
+		return []dockerPlatformImage{{img: img, platform: platform}}, nil
+	}
+
+	idx, err := desc.ImageIndex()
+	if err != nil {
+		return nil, err
+	}
+	manifest, err := idx.IndexManifest()
+	if err != nil {
+		return nil, fmt.Errorf("reading index manifest: %w", err)
+	}
+
+	var (
+		images    []dockerPlatformImage
+		available []string
+	)
+	for _, m := range manifest.Manifests {
+		if !isDockerPlatformImage(m) {
+			continue
+		}
+		available = append(available, m.Platform.String())
+		if !all && !m.Platform.Satisfies(*requested) {
+			continue
+		}
+		img, err := idx.Image(m.Digest)
+		if err != nil {
+			return nil, fmt.Errorf("reading image of platform %s: %w", m.Platform, err)
+		}
+		image := dockerPlatformImage{img: img, platform: m.Platform.String()}
+		if !all {
+			return []dockerPlatformImage{image}, nil
+		}
+		image.dir = strings.NewReplacer("/", "-", ":", "-").Replace(image.platform)
+		images = append(images, image)
+	}
+	if len(images) == 0 {
+		if len(available) == 0 {
+			available = []string{"none"}
+		}
+		return nil, fmt.Errorf("no image of platform %q in index (available: %s)",
+			platformParam, strings.Join(available, ", "))
+	}
+	return images, nil
+}
+
+// isDockerPlatformImage reports whether the manifest of an index is the image of a platform.
+// Attestation manifests, which buildkit stores with the platform 'unknown/unknown', are not.
+func isDockerPlatformImage(m v1.Descriptor) bool {
+	if m.Platform == nil || m.Platform.OS == "unknown" || m.Platform.Architecture == "unknown" {
+		return false
+	}
+	if m.Annotations["vnd.docker.reference.type"] == "attestation-manifest" {
+		return false
+	}
+	return m.MediaType.IsImage()
+}
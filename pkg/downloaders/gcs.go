@@ -17,13 +17,13 @@ import (
 	"path/filepath"
 
 	"cloud.google.com/go/storage"
-	"github.com/crashappsec/ocular-default-integrations/internal/definitions"
+	"github.com/crashappsec/ocular-default-integrations/pkg/definitions"
 	"google.golang.org/api/iterator"
 	"sigs.k8s.io/controller-runtime/pkg/log"
 )
 
 func init() {
-	All.registerDownloader(GCS)
+	All.Register(GCS)
 }
 
 var GCS = Downloader{
@@ -37,8 +37,10 @@ var GCS = Downloader{
 	Download: downloadGCS,
 }
 
-func downloadGCS(ctx context.Context, _ map[string]string, bucketName, _, targetDir string) error {
+func downloadGCS(ctx context.Context, _ map[string]string, identifier, _, targetDir string) error {
 	l := log.FromContext(ctx)
+	bucketName, prefix := splitBucketIdentifier(identifier)
+
 	client, err := storage.NewClient(ctx)
 	if err != nil {
 		return fmt.Errorf("failed to create GCS client: %w", err)
@@ -50,9 +52,10 @@ func downloadGCS(ctx context.Context, _ map[string]string, bucketName, _, target
 	}()
 
 	bucket := client.Bucket(bucketName)
-	query := &storage.Query{}
+	query := &storage.Query{Prefix: prefix}
 	it := bucket.Objects(ctx, query)
 
+	var names []string
 	for {
 		objAttrs, err := it.Next()
 		if errors.Is(err, iterator.Done) {
@@ -61,15 +64,18 @@ func downloadGCS(ctx context.Context, _ map[string]string, bucketName, _, target
 		if err != nil {
 			return fmt.Errorf("error listing objects: %w", err)
 		}
+		names = append(names, objAttrs.Name)
+	}
 
-		localPath := filepath.Join(targetDir, objAttrs.Name)
+	for _, name := range selectBucketObjects(prefix, names) {
+		localPath := filepath.Join(targetDir, name)
 		if err = os.MkdirAll(filepath.Dir(localPath), 0o750); err != nil {
 			return fmt.Errorf("failed to create local directory: %w", err)
 		}
 
-		l.Info("downloading file to local", "file", objAttrs.Name, "localPath", localPath)
-		if err = downloadGCSObject(ctx, bucket, objAttrs.Name, localPath); err != nil {
-			return fmt.Errorf("failed to download object %s: %w", objAttrs.Name, err)
+		l.Info("downloading file to local", "file", name, "localPath", localPath)
+		if err = downloadGCSObject(ctx, bucket, name, localPath); err != nil {
+			return fmt.Errorf("failed to download object %s: %w", name, err)
 		}
 	}
 
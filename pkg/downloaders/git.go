@@ -16,11 +16,13 @@ import (
 	"net/url"
 	"os"
 	"path/filepath"
+	"slices"
 	"strconv"
 	"strings"
 
-	"github.com/crashappsec/ocular-default-integrations/internal/definitions"
 	"github.com/crashappsec/ocular-default-integrations/internal/utils"
+	"github.com/crashappsec/ocular-default-integrations/pkg/definitions"
+	"github.com/crashappsec/ocular-default-integrations/pkg/input"
 	"github.com/crashappsec/ocular/api/v1beta1"
 	gogit "github.com/go-git/go-git/v6"
 	"github.com/go-git/go-git/v6/config"
@@ -34,7 +36,7 @@ import (
 )
 
 func init() {
-	All.registerDownloader(Git)
+	All.Register(Git)
 }
 
 var Git = Downloader{
@@ -52,13 +54,24 @@ var Git = Downloader{
 			SecretKey:  "github-token",
 			EnvVarName: GitHubToken,
 		},
+		{
+			SecretKey:  "git-ssh-key-passphrase",
+			EnvVarName: GitSSHKeyPassphraseEnvVar,
+		},
 	},
-	FileSecrets: []definitions.FileSecret{
+	FileSecrets: append([]definitions.FileSecret{
 		{
 			SecretKey: "gitconfig",
 			MountPath: CustomScope,
 		},
-	},
+	}, gitAuthFileSecrets...),
+	Parameters: slices.Concat(
+		gitFetchParameters,
+		gitSubmoduleParameters,
+		gitLFSParameters,
+		gitSparseParameters,
+		gitAuthParameters,
+	),
 	MetadataFiles: []string{GitMetadataPath},
 	Download:      downloadGit,
 }
@@ -67,16 +80,61 @@ type GitMetadata struct {
 	Ref      string `json:"ref,omitempty"`
 	Hash     string `json:"hash,omitempty"`
 	CloneURL string `json:"clone_url,omitempty"`
-	Public   bool   `json:"public,omitempty"`
+	// Subpath is the subdirectory given in the target identifier, if any.
+	Subpath string `json:"subpath,omitempty"`
+	// SparsePaths are the paths checked out, if not the whole repository.
+	SparsePaths []string           `json:"sparse_paths,omitempty"`
+	Commit      *GitCommitMetadata `json:"commit,omitempty"`
+	// Branches and Tags are the remote branches and tags pointing to the commit.
+	Branches      []string `json:"branches,omitempty"`
+	Tags          []string `json:"tags,omitempty"`
+	DefaultBranch string   `json:"default_branch,omitempty"`
+	// Visibility is the visibility reported by the API of GitHub or GitLab, e.g. 'public' or 'internal'.
+	Visibility string `json:"visibility,omitempty"`
+	// Public is unset if the visibility of the repository cannot be determined.
+	Public *bool             `json:"public,omitempty"`
+	Stats  *GitStatsMetadata `json:"stats,omitempty"`
+	Fetch  GitFetchMetadata  `json:"fetch"`
+	// Submodules are the submodules checked out, including nested ones.
+	Submodules []GitSubmoduleMetadata `json:"submodules,omitempty"`
+	LFS        *GitLFSMetadata        `json:"lfs,omitempty"`
 }
 
 const (
 	CustomScope = "/etc/ocular/gitconfig"
 )
 
-func downloadGit(ctx context.Context, params map[string]string, cloneURL, version, targetDir string) error {
+func downloadGit(ctx context.Context, params map[string]string, identifier, version, targetDir string) error {
+	cloneURL, subpath := splitGitSubpath(identifier)
 	l := log.FromContext(ctx).WithValues("cloneURL", cloneURL, "targetDir", targetDir)
 
+	fetchOpts, err := parseGitFetchOptions(params)
+	if err != nil {
+		return err
+	}
+	sparse, err := parseGitSparsePaths(params, subpath)
+	if err != nil {
+		return err
+	}
+	submodules, err := input.BoolParam(params, GitSubmodulesParamName, false)
+	if err != nil {
+		return err
+	}
+	switch {
+	case submodules && fetchOpts.filter != "":
+		return fmt.Errorf("%s is not supported along with %s", GitSubmodulesParamName, GitFilterParamName)
+	case submodules && len(sparse) > 0:
+		return fmt.Errorf("%s is not supported along with sparse paths", GitSubmodulesParamName)
+	}
+	lfsOpts, err := parseGitLFSOptions(params)
+	if err != nil {
+		return err
+	}
+	creds, err := loadGitCredentials(ctx, params)
+	if err != nil {
+		return err
+	}
+
 	// Initialize empty local repo
 	repo, err := gogit.PlainInit(targetDir, false)
 	if err != nil {
@@ -105,9 +163,9 @@ func downloadGit(ctx context.Context, params map[string]string, cloneURL, versio
 		}
 	}
 
-	auth, err := handleAuthentication(ctx, cloneURL)
+	auth, err := creds.authenticate(ctx, cloneURL)
 	if err != nil {
-		l.Error(err, "failed to authenticate")
+		return err
 	}
 
 	err = repo.SetConfig(cfg)
@@ -128,15 +186,14 @@ func downloadGit(ctx context.Context, params map[string]string, cloneURL, versio
 		return err
 	}
 
-	err = repo.FetchContext(ctx, &gogit.FetchOptions{
-		Progress: utils.NewLogWriter(l),
-		ClientOptions: []client.Option{
-			client.WithHTTPAuth(auth),
-		},
-	})
+	metadata := GitMetadata{
+		CloneURL:    cloneURL,
+		Subpath:     subpath,
+		SparsePaths: sparse,
+	}
+
+	localRef, err := fetchGit(ctx, repo, auth, fetchOpts, version, &metadata.Fetch)
 	switch {
-	case errors.Is(err, gogit.NoErrAlreadyUpToDate):
-		l.Info("repository already up to date")
 	case errors.Is(err, transport.ErrEmptyRemoteRepository):
 		l.Info("repository is empty, nothing to fetch")
 		return nil
@@ -144,29 +201,85 @@ func downloadGit(ctx context.Context, params map[string]string, cloneURL, versio
 		return fmt.Errorf("failed to fetch repository: %w", err)
 	}
 
-	l.Info("cloned Git repository")
+	l.Info("cloned Git repository", "strategy", metadata.Fetch.Strategy)
 
-	metadata := GitMetadata{
-		CloneURL: cloneURL,
+	resolveVersion := version
+	if localRef != "" {
+		// only the reference of the version was fetched
+		resolveVersion = localRef.String()
 	}
-
-	checkoutOptions, err := getGitCheckoutOption(ctx, repo, version)
+	revision, err := resolveGitVersion(ctx, repo, resolveVersion)
 	if err != nil {
 		return err
 	}
+	if localRef == gitSingleCommitRef {
+		revision.ref = ""
+	}
 
-	metadata.Ref = checkoutOptions.Branch.String()
-	metadata.Hash = checkoutOptions.Hash.String()
+	metadata.Ref = revision.ref.String()
+	metadata.Hash = revision.hash.String()
 
-	l.Info("checking out revision", "ref", checkoutOptions.Branch, "hash", checkoutOptions.Hash)
+	l.Info("checking out revision", "ref", revision.ref, "hash", revision.hash)
 
-	worktree, err := repo.Worktree()
-	if err != nil {
-		return err
+	if fetchOpts.filter != "" || len(sparse) > 0 {
+		written, omitted, err := checkoutGitTree(repo, revision, targetDir, sparse)
+		if err != nil {
+			return err
+		}
+		if written == 0 && omitted == 0 && len(sparse) > 0 {
+			return fmt.Errorf("no files of commit %s match the sparse paths %v", revision.hash, sparse)
+		}
+		metadata.Fetch.OmittedFiles = omitted
+		l.Info("checked out files", "sparse_paths", sparse, "files", written, "omitted_files", omitted)
+	} else {
+		worktree, err := repo.Worktree()
+		if err != nil {
+			return err
+		}
+
+		if err = worktree.Checkout(revision.checkoutOptions()); err != nil {
+			return err
+		}
 	}
 
-	if err = worktree.Checkout(checkoutOptions); err != nil {
-		return err
+	checkouts := []gitCheckout{{repo: repo, url: cloneURL}}
+	if submodules {
+		subCheckouts, subMetadata, err := updateGitSubmodules(ctx, creds, checkouts[0], fetchOpts.depth, 0)
+		if err != nil {
+			return err
+		}
+		checkouts = append(checkouts, subCheckouts...)
+		metadata.Submodules = subMetadata
+		l.Info("checked out submodules", "count", len(subMetadata))
+	}
+
+	if lfsOpts.enabled {
+		metadata.LFS = &GitLFSMetadata{}
+		for _, checkout := range checkouts {
+			dir := filepath.Join(targetDir, checkout.path)
+			if err = smudgeGitLFS(ctx, creds, checkout, dir, lfsOpts, metadata.LFS); err != nil {
+				return fmt.Errorf("failed to download LFS objects of %q: %w", checkout.url, err)
+			}
+		}
+		l.Info("downloaded LFS objects", "downloaded", metadata.LFS.Downloaded,
+			"skipped", metadata.LFS.Skipped, "failed", metadata.LFS.Failed)
+	}
+
+	if metadata.Commit, err = readGitCommitMetadata(repo, revision.hash); err != nil {
+		l.Error(err, "failed to read commit metadata")
+	}
+	metadata.DefaultBranch, metadata.Branches, metadata.Tags, err = gitRemoteRefs(ctx, repo, auth, revision.hash)
+	if err != nil {
+		l.Error(err, "failed to read branches and tags of commit")
+	}
+	if stats, err := readGitStats(targetDir); err != nil {
+		l.Error(err, "failed to read repository size")
+	} else {
+		metadata.Stats = &stats
+	}
+	metadata.Visibility, metadata.Public, err = readGitVisibility(ctx, creds, cloneURL)
+	if err != nil {
+		l.Error(err, "failed to read repository visibility")
 	}
 
 	if err = writeJSONStruct(GitMetadataPath, metadata); err != nil {
@@ -180,6 +293,12 @@ func downloadGit(ctx context.Context, params map[string]string, cloneURL, versio
 	if err != nil {
 		l.Error(err, "failed to set permissions on .git directory")
 	}
+	if len(metadata.Submodules) > 0 {
+		// the objects of submodules are stored in their own repositories
+		if err = filepath.WalkDir(".git/modules", chmodRecursive); err != nil {
+			l.Error(err, "failed to set permissions on submodule repositories")
+		}
+	}
 	return nil
 }
 
@@ -189,7 +308,9 @@ const (
 	GitHubToken               = "GITHUB_TOKEN"
 )
 
-func handleAuthentication(ctx context.Context, rawCloneURL string) (client.HTTPAuth, error) {
+// handleGitHubAuthentication returns the credentials of the GitHub App or token
+// for a github.com URL, or nil if none are set or the URL is not for GitHub.
+func handleGitHubAuthentication(ctx context.Context, rawCloneURL string) (client.HTTPAuth, error) {
 	l := log.FromContext(ctx)
 
 	cloneURL, err := url.Parse(rawCloneURL)
@@ -253,47 +374,86 @@ func chmodRecursive(path string, e fs.DirEntry, err error) error {
 	return os.Chmod(path, 0o644)
 }
 
-func getGitCheckoutOption(ctx context.Context, repo *gogit.Repository, version string) (*gogit.CheckoutOptions, error) {
+// gitMaxCandidateRefs is the maximum number of references
+// listed in the error when a version cannot be resolved.
+const gitMaxCandidateRefs = 20
+
+// gitRevision is a target version resolved to a commit.
+type gitRevision struct {
+	// ref is the full name of the reference the version names, if any.
+	ref  plumbing.ReferenceName
+	hash plumbing.Hash
+}
+
+// checkoutOptions checks out branches by name, so that
+// HEAD is attached to them, and any other revision by hash.
+func (r gitRevision) checkoutOptions() *gogit.CheckoutOptions {
+	if r.ref.IsBranch() {
+		return &gogit.CheckoutOptions{Branch: r.ref}
+	}
+	return &gogit.CheckoutOptions{Hash: r.hash}
+}
+
+// resolveGitVersion resolves the version to a commit following the rules of git rev-parse:
+// full or short hashes, branches, tags (peeled to the commit they point to), full reference
+// names and ancestry expressions such as 'HEAD~2'. An empty version is the default branch.
+func resolveGitVersion(ctx context.Context, repo *gogit.Repository, version string) (gitRevision, error) {
 	l := log.FromContext(ctx)
-	var (
-		checkoutOptions *gogit.CheckoutOptions
-		ref             *plumbing.Reference
-		err             error
-	)
 
-	switch {
-	case version == "":
-		ref, err = repo.Reference(plumbing.NewRemoteHEADReferenceName("origin"), true)
+	rev := version
+	if rev == "" {
+		rev = plumbing.HEAD.String()
+	}
+	// the repository has not been checked out yet, so HEAD
+	// refers to the default branch of the remote instead
+	rest, isHEAD := strings.CutPrefix(rev, plumbing.HEAD.String())
+	if isHEAD && (rest == "" || strings.ContainsAny(rest[:1], "~^@")) {
+		rev = plumbing.NewRemoteHEADReferenceName("origin").String() + rest
+	}
+
+	hash, err := repo.ResolveRevision(plumbing.Revision(rev))
+	if err != nil {
+		return gitRevision{}, fmt.Errorf("unable to resolve version %q: %w (candidates: %s)",
+			version, err, strings.Join(gitCandidateRefs(repo), ", "))
+	}
+
+	revision := gitRevision{hash: *hash}
+	for _, rule := range plumbing.RefRevParseRules {
+		ref, err := repo.Reference(plumbing.ReferenceName(fmt.Sprintf(rule, rev)), false)
 		if err != nil {
-			l.Info("failed to find HEAD ref, using default branch")
-			return &gogit.CheckoutOptions{
-				Branch: plumbing.NewRemoteReferenceName("origin", "main"),
-			}, nil
+			continue
 		}
-	case plumbing.IsHash(version):
-		ref = plumbing.NewHashReference("", plumbing.NewHash(version))
-	default:
-		ref, err = repo.Reference(plumbing.NewRemoteReferenceName("origin", version), false)
-		if err != nil {
-			return nil, err
+		revision.ref = ref.Name()
+		if ref.Type() == plumbing.SymbolicReference {
+			revision.ref = ref.Target()
 		}
+		break
 	}
-	l.Info("resolved reference", "type", ref.Type(), "name", ref.Name(), "hash", ref.Hash(), "target", ref.Target())
+	l.Info("resolved version", "version", version, "ref", revision.ref, "hash", revision.hash)
+	return revision, nil
+}
 
-	switch ref.Type() {
-	case plumbing.SymbolicReference:
-		checkoutOptions = &gogit.CheckoutOptions{
-			Branch: ref.Target(),
-		}
-	case plumbing.HashReference:
-		checkoutOptions = &gogit.CheckoutOptions{
-			Hash: ref.Hash(),
+// gitCandidateRefs returns the sorted names of the branches and tags of the repository.
+func gitCandidateRefs(repo *gogit.Repository) []string {
+	refs, err := repo.References()
+	if err != nil {
+		return nil
+	}
+	var names []string
+	_ = refs.ForEach(func(ref *plumbing.Reference) error {
+		if ref.Name() != plumbing.HEAD && ref.Name() != gitSingleCommitRef {
+			names = append(names, ref.Name().String())
 		}
-	default:
-		return nil, fmt.Errorf("unsupported reference type: %s", ref.Type())
+		return nil
+	})
+	slices.Sort(names)
+	if len(names) > gitMaxCandidateRefs {
+		names = append(names[:gitMaxCandidateRefs], fmt.Sprintf("and %d more", len(names)-gitMaxCandidateRefs))
 	}
-
-	return checkoutOptions, nil
+	if len(names) == 0 {
+		return []string{"none"}
+	}
+	return names
 }
 
 const GitMetadataPath = v1beta1.PipelineMetadataDirectory + "/git.json"
@@ -0,0 +1,102 @@
+// Copyright (C) 2025-2026 Crash Override, Inc.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the FSF, either version 3 of the License, or (at your option) any later version.
+// See the LICENSE file in the root of this repository for full license text or
+// visit: <https://www.gnu.org/licenses/gpl-3.0.html>.
+
+package downloaders
+
+import (
+	"net/url"
+	"testing"
+)
+
+func TestGitCredentialEntryMatches(t *testing.T) {
+	tests := []struct {
+		name  string
+		entry gitCredentialEntry
+		url   string
+		want  bool
+	}{
+		{
+			name:  "host without path matches all repositories",
+			entry: gitCredentialEntry{scheme: "https", host: "github.com"},
+			url:   "https://github.com/org/repo.git",
+			want:  true,
+		},
+		{
+			name:  "host is case insensitive",
+			entry: gitCredentialEntry{scheme: "https", host: "github.com"},
+			url:   "https://GitHub.com/org/repo.git",
+			want:  true,
+		},
+		{
+			name:  "other scheme",
+			entry: gitCredentialEntry{scheme: "https", host: "github.com"},
+			url:   "http://github.com/org/repo.git",
+		},
+		{
+			name:  "other host",
+			entry: gitCredentialEntry{scheme: "https", host: "github.com"},
+			url:   "https://gitlab.com/org/repo.git",
+		},
+		{
+			name:  "port is part of host",
+			entry: gitCredentialEntry{scheme: "https", host: "git.example.com"},
+			url:   "https://git.example.com:8443/org/repo.git",
+		},
+		{
+			name:  "wildcard host",
+			entry: gitCredentialEntry{scheme: "https", host: "*.example.com"},
+			url:   "https://git.example.com/org/repo.git",
+			want:  true,
+		},
+		{
+			name:  "wildcard host matches a single label",
+			entry: gitCredentialEntry{scheme: "https", host: "*.example.com"},
+			url:   "https://example.com/org/repo.git",
+		},
+		{
+			name:  "exact path",
+			entry: gitCredentialEntry{scheme: "https", host: "github.com", path: "org/repo"},
+			url:   "https://github.com/org/repo",
+			want:  true,
+		},
+		{
+			name:  "path without .git suffix",
+			entry: gitCredentialEntry{scheme: "https", host: "github.com", path: "org/repo"},
+			url:   "https://github.com/org/repo.git",
+			want:  true,
+		},
+		{
+			name:  "path prefix",
+			entry: gitCredentialEntry{scheme: "https", host: "github.com", path: "org"},
+			url:   "https://github.com/org/repo.git",
+			want:  true,
+		},
+		{
+			name:  "path with common prefix",
+			entry: gitCredentialEntry{scheme: "https", host: "github.com", path: "org"},
+			url:   "https://github.com/organization/repo.git",
+		},
+		{
+			name:  "other path",
+			entry: gitCredentialEntry{scheme: "https", host: "github.com", path: "org/repo"},
+			url:   "https://github.com/org/other.git",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			u, err := url.Parse(tt.url)
+			if err != nil {
+				t.Fatalf("parsing %q: %v", tt.url, err)
+			}
+			if got := tt.entry.matches(u); got != tt.want {
+				t.Errorf("matches(%q) = %t, want %t", tt.url, got, tt.want)
+			}
+		})
+	}
+}
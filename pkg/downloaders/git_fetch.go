@@ -0,0 +1,424 @@
+// Copyright (C) 2025-2026 Crash Override, Inc.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the FSF, either version 3 of the License, or (at your option) any later version.
+// See the LICENSE file in the root of this repository for full license text or
+// visit: <https://www.gnu.org/licenses/gpl-3.0.html>.
+
+package downloaders
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/crashappsec/ocular-default-integrations/internal/utils"
+	"github.com/crashappsec/ocular-default-integrations/pkg/input"
+	"github.com/crashappsec/ocular/api/v1beta1"
+	gogit "github.com/go-git/go-git/v6"
+	"github.com/go-git/go-git/v6/config"
+	"github.com/go-git/go-git/v6/plumbing"
+	"github.com/go-git/go-git/v6/plumbing/filemode"
+	"github.com/go-git/go-git/v6/plumbing/format/index"
+	"github.com/go-git/go-git/v6/plumbing/object"
+	"github.com/go-git/go-git/v6/plumbing/protocol/packp"
+	"github.com/go-git/go-git/v6/plumbing/transport"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+const (
+	GitDepthParamName     = "GIT_DEPTH"
+	GitSingleRefParamName = "GIT_SINGLE_REF"
+	GitFilterParamName    = "GIT_FILTER"
+)
+
+const (
+	// GitFetchStrategyFull fetches all references of the repository.
+	GitFetchStrategyFull = "full"
+	// GitFetchStrategySingleRef fetches only the reference matching the target version.
+	GitFetchStrategySingleRef = "single-ref"
+	// GitFetchStrategySingleCommit fetches only the commit of a target version that is a full hash.
+	GitFetchStrategySingleCommit = "single-commit"
+)
+
+// gitSingleCommitRef is the local reference a commit fetched by hash is stored as.
+const gitSingleCommitRef = plumbing.ReferenceName("refs/ocular/target")
+
+var gitFilterRegex = regexp.MustCompile(`^(blob:none|blob:limit=[0-9]+[kmg]?|tree:[0-9]+)$`)
+
+// gitFetchParameters are the parameters of the git downloader controlling what is fetched.
+var gitFetchParameters = []v1beta1.ParameterDefinition{
+	{
+		Name: GitDepthParamName,
+		Description: "Number of commits to fetch from the tip of each fetched reference. " +
+			"Set to 0 to fetch the full history.",
+		Default: ptr.To("0"),
+	},
+	{
+		Name: GitSingleRefParamName,
+		Description: "If true, only the branch, tag or reference matching the target version is fetched, " +
+			"or its commit if the version is a full commit hash. If the server does not allow fetching a " +
+			"commit by hash, or no reference matches the version, all references are fetched instead.",
+		Default: ptr.To("false"),
+	},
+	{
+		Name: GitFilterParamName,
+		Description: "Partial clone filter requested from the server, one of 'blob:none', " +
+			"'blob:limit=<size>[k|m|g]' or 'tree:<depth>'. Files whose contents were omitted by the " +
+			"server are not written to the target directory, and are counted in the git metadata.",
+		Default: ptr.To(""),
+	},
+}
+
+// gitFetchOptions is the parsed configuration of what is fetched from the remote.
+type gitFetchOptions struct {
+	depth     int
+	singleRef bool
+	filter    packp.Filter
+}
+
+func parseGitFetchOptions(params map[string]string) (gitFetchOptions, error) {
+	var opts gitFetchOptions
+	if depth := strings.TrimSpace(params[GitDepthParamName]); depth != "" {
+		d, err := strconv.Atoi(depth)
+		if err != nil || d < 0 {
+			return opts, fmt.Errorf("invalid value for %s: %q is not a non-negative number", GitDepthParamName, depth)
+		}
+		opts.depth = d
+	}
+	var err error
+	if opts.singleRef, err = input.BoolParam(params, GitSingleRefParamName, false); err != nil {
+		return opts, err
+	}
+	if filter := strings.TrimSpace(params[GitFilterParamName]); filter != "" {
+		if !gitFilterRegex.MatchString(filter) {
+			return opts, fmt.Errorf("invalid value for %s: unsupported filter %q", GitFilterParamName, filter)
+		}
+		opts.filter = packp.Filter(filter)
+	}
+	return opts, nil
+}
+
+// GitFetchMetadata records how the repository was fetched.
+type GitFetchMetadata struct {
+	Strategy string   `json:"strategy,omitempty"`
+	RefSpecs []string `json:"refspecs,omitempty"`
+	Depth    int      `json:"depth,omitempty"`
+	Filter   string   `json:"filter,omitempty"`
+	// Fallback is the reason the full fetch strategy was
+	// used instead of the requested single reference.
+	Fallback string `json:"fallback,omitempty"`
+	// OmittedFiles is the number of files not written because
+	// their contents were omitted by the partial clone filter.
+	OmittedFiles int `json:"omitted_files,omitempty"`
+}
+
+// fetchGit fetches the repository from the origin remote. If a single reference is
+// requested, the name of the local reference to check out is returned, otherwise
+// the version is resolved from all fetched references.
+func fetchGit(
+	ctx context.Context,
+	repo *gogit.Repository,
+	auth gitAuth,
+	opts gitFetchOptions,
+	version string,
+	metadata *GitFetchMetadata,
+) (plumbing.ReferenceName, error) {
+	l := log.FromContext(ctx)
+	metadata.Depth = opts.depth
+	metadata.Filter = string(opts.filter)
+
+	fetch := func(refSpecs []config.RefSpec) error {
+		err := repo.FetchContext(ctx, &gogit.FetchOptions{
+			RefSpecs:      refSpecs,
+			Depth:         opts.depth,
+			Filter:        opts.filter,
+			Progress:      utils.NewLogWriter(l),
+			ClientOptions: auth.clientOptions(),
+		})
+		if errors.Is(err, gogit.NoErrAlreadyUpToDate) {
+			l.Info("repository already up to date")
+			return nil
+		}
+		return err
+	}
+
+	if opts.singleRef {
+		refSpec, localRef, strategy, err := gitSingleRefSpec(ctx, repo, auth, version)
+		switch {
+		case err != nil:
+			return "", err
+		case refSpec == "":
+			metadata.Fallback = "no remote reference matches the version"
+		default:
+			l.Info("fetching single reference", "refspec", refSpec)
+			err = fetch([]config.RefSpec{refSpec})
+			if errors.Is(err, gogit.ErrExactSHA1NotSupported) {
+				l.Info("server does not support fetching commits by hash, fetching all references")
+				metadata.Fallback = "server does not support fetching commits by hash"
+				break
+			}
+			if err != nil {
+				return "", err
+			}
+			metadata.Strategy = strategy
+			metadata.RefSpecs = []string{refSpec.String()}
+			return localRef, nil
+		}
+	}
+
+	remote, err := repo.Remote("origin")
+	if err != nil {
+		return "", err
+	}
+	metadata.Strategy = GitFetchStrategyFull
+	for _, refSpec := range remote.Config().Fetch {
+		metadata.RefSpecs = append(metadata.RefSpecs, refSpec.String())
+	}
+	return "", fetch(nil)
+}
+
+// gitSingleRefSpec returns the refspec fetching only the version, along with the
+// local reference it is fetched to and the strategy used. An empty refspec is
+// returned if no remote reference matches the version, such as for abbreviated hashes.
+func gitSingleRefSpec(
+	ctx context.Context,
+	repo *gogit.Repository,
+	auth gitAuth,
+	version string,
+) (config.RefSpec, plumbing.ReferenceName, string, error) {
+	switch {
+	case version == "":
+		localRef := plumbing.NewRemoteHEADReferenceName("origin")
+		return config.RefSpec("+HEAD:" + localRef), localRef, GitFetchStrategySingleRef, nil
+	case plumbing.IsHash(version):
+		return config.RefSpec(version + ":" + gitSingleCommitRef.String()),
+			gitSingleCommitRef, GitFetchStrategySingleCommit, nil
+	}
+
+	remote, err := repo.Remote("origin")
+	if err != nil {
+		return "", "", "", err
+	}
+	remoteRefs, err := remote.ListContext(ctx, &gogit.ListOptions{
+		ClientOptions: auth.clientOptions(),
+	})
+	if errors.Is(err, transport.ErrEmptyRemoteRepository) {
+		return "", "", "", nil
+	}
+	if err != nil {
+		return "", "", "", fmt.Errorf("failed to list remote references: %w", err)
+	}
+	names := make(map[plumbing.ReferenceName]struct{}, len(remoteRefs))
+	for _, ref := range remoteRefs {
+		names[ref.Name()] = struct{}{}
+	}
+
+	// branches are fetched as remote-tracking branches, like a clone would
+	for _, candidate := range []struct {
+		remote, local plumbing.ReferenceName
+	}{
+		{plumbing.ReferenceName(version), plumbing.ReferenceName(version)},
+		{plumbing.NewBranchReferenceName(version), plumbing.NewRemoteReferenceName("origin", version)},
+		{plumbing.NewTagReferenceName(version), plumbing.NewTagReferenceName(version)},
+	} {
+		if _, ok := names[candidate.remote]; ok {
+			// the version may also be the full name of a branch
+			if candidate.remote.IsBranch() {
+				candidate.local = plumbing.NewRemoteReferenceName("origin", candidate.remote.Short())
+			}
+			refSpec := config.RefSpec("+" + candidate.remote.String() + ":" + candidate.local.String())
+			return refSpec, candidate.local, GitFetchStrategySingleRef, nil
+		}
+	}
+	return "", "", "", nil
+}
+
+// checkoutGitTree writes the files of the revision matching the sparse paths to the worktree,
+// skipping the files and directories whose objects were omitted by a partial clone filter,
+// since go-git cannot fetch them on demand. The index lists all the files of the revision,
+// with those not written marked skip-worktree, so that git reports the worktree as clean.
+// It returns the number of files written, and the number of files that were omitted.
+func checkoutGitTree(
+	repo *gogit.Repository,
+	revision gitRevision,
+	targetDir string,
+	sparse gitSparsePaths,
+) (int, int, error) {
+	commit, err := repo.CommitObject(revision.hash)
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to read commit %s: %w", revision.hash, err)
+	}
+	tree, err := repo.TreeObject(commit.TreeHash)
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to read tree of commit %s: %w", revision.hash, err)
+	}
+
+	w := &gitTreeWriter{repo: repo, sparse: sparse, index: &index.Index{Version: 2}}
+	if err = w.writeTree(tree, targetDir, "", false); err != nil {
+		return w.written, w.omitted, err
+	}
+	if err = repo.Storer.SetIndex(w.index); err != nil {
+		return w.written, w.omitted, fmt.Errorf("failed to write index: %w", err)
+	}
+	if len(sparse) > 0 {
+		if err = writeGitSparseCheckout(repo, targetDir, sparse); err != nil {
+			return w.written, w.omitted, err
+		}
+	}
+
+	head := plumbing.NewHashReference(plumbing.HEAD, revision.hash)
+	if revision.ref.IsBranch() {
+		head = plumbing.NewSymbolicReference(plumbing.HEAD, revision.ref)
+	}
+	return w.written, w.omitted, repo.Storer.SetReference(head)
+}
+
+// gitTreeWriter writes the files of a tree matching the sparse paths, and adds them to the index.
+type gitTreeWriter struct {
+	repo    *gogit.Repository
+	sparse  gitSparsePaths
+	index   *index.Index
+	written int
+	omitted int
+}
+
+// writeTree writes the tree to dir, which is at the path rel of the repository. If skip is
+// set, or for the entries not matching the sparse paths, the files are only added to the index.
+func (w *gitTreeWriter) writeTree(tree *object.Tree, dir, rel string, skip bool) error {
+	for _, entry := range tree.Entries {
+		if entry.Name == "" || entry.Name == "." || entry.Name == ".." || entry.Name == gogit.GitDirName ||
+			strings.ContainsAny(entry.Name, `/\`) {
+			return fmt.Errorf("invalid path %q in tree %s", entry.Name, tree.Hash)
+		}
+		entryPath := path.Join(rel, entry.Name)
+		entrySkip, included := skip, false
+		if !skip {
+			var partial bool
+			included, partial = w.sparse.match(entryPath)
+			entrySkip = !included && !(partial && entry.Mode == filemode.Dir)
+		}
+		target := filepath.Join(dir, entry.Name)
+
+		switch entry.Mode {
+		case filemode.Dir:
+			subtree, err := w.repo.TreeObject(entry.Hash)
+			if errors.Is(err, plumbing.ErrObjectNotFound) {
+				if !entrySkip {
+					w.omitted++
+				}
+				continue
+			}
+			if err != nil {
+				return err
+			}
+			sparse := w.sparse
+			if included {
+				// all the contents of an included directory are written
+				w.sparse = nil
+			}
+			err = w.writeTree(subtree, target, entryPath, entrySkip)
+			w.sparse = sparse
+			if err != nil {
+				return err
+			}
+		case filemode.Regular, filemode.Deprecated, filemode.Executable, filemode.Symlink:
+			if err := w.writeFile(entry, dir, entryPath, entrySkip); err != nil {
+				return err
+			}
+		default:
+			// submodules are not part of the repository objects, and are not checked out
+			w.addIndexEntry(entry, entryPath, nil)
+		}
+	}
+	return nil
+}
+
+// writeFile writes the file of the entry to dir unless skip is set or its blob was omitted,
+// and adds it to the index.
+func (w *gitTreeWriter) writeFile(entry object.TreeEntry, dir, entryPath string, skip bool) error {
+	if skip {
+		w.addIndexEntry(entry, entryPath, nil)
+		return nil
+	}
+	blob, err := w.repo.BlobObject(entry.Hash)
+	if errors.Is(err, plumbing.ErrObjectNotFound) {
+		w.omitted++
+		w.addIndexEntry(entry, entryPath, nil)
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	// directories are created as their files are written, so
+	// that none are left empty by the sparse paths
+	if err = os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+	target := filepath.Join(dir, entry.Name)
+	if err = writeGitBlob(blob, entry.Mode, target); err != nil {
+		return fmt.Errorf("failed to write %s: %w", target, err)
+	}
+	fi, err := os.Lstat(target)
+	if err != nil {
+		return err
+	}
+	w.written++
+	w.addIndexEntry(entry, entryPath, fi)
+	return nil
+}
+
+// addIndexEntry adds the entry to the index, with the size and modification time of the file
+// written for it, or marked skip-worktree if it was not written.
+func (w *gitTreeWriter) addIndexEntry(entry object.TreeEntry, entryPath string, fi os.FileInfo) {
+	e := &index.Entry{Hash: entry.Hash, Name: entryPath, Mode: entry.Mode}
+	if fi == nil {
+		e.SkipWorktree = true
+		// the skip-worktree flag is an extended flag of the version 3 index format
+		w.index.Version = 3
+	} else {
+		e.CreatedAt = fi.ModTime()
+		e.ModifiedAt = fi.ModTime()
+		e.Size = uint32(fi.Size())
+	}
+	w.index.Entries = append(w.index.Entries, e)
+}
+
+func writeGitBlob(blob *object.Blob, mode filemode.FileMode, path string) error {
+	r, err := blob.Reader()
+	if err != nil {
+		return err
+	}
+	defer func() { _ = r.Close() }()
+
+	if mode == filemode.Symlink {
+		target, err := io.ReadAll(r)
+		if err != nil {
+			return err
+		}
+		return os.Symlink(string(target), path)
+	}
+
+	perm := os.FileMode(0o644)
+	if mode == filemode.Executable {
+		perm = 0o755
+	}
+	f, err := os.OpenFile(filepath.Clean(path), os.O_CREATE|os.O_WRONLY|os.O_TRUNC, perm)
+	if err != nil {
+		return err
+	}
+	if _, err = io.Copy(f, r); err != nil {
+		_ = f.Close()
+		return err
+	}
+	return f.Close()
+}
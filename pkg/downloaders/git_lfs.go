@@ -0,0 +1,493 @@
+// Copyright (C) 2025-2026 Crash Override, Inc.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the FSF, either version 3 of the License, or (at your option) any later version.
+// See the LICENSE file in the root of this repository for full license text or
+// visit: <https://www.gnu.org/licenses/gpl-3.0.html>.
+
+package downloaders
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/crashappsec/ocular-default-integrations/pkg/input"
+	"github.com/crashappsec/ocular/api/v1beta1"
+	gogit "github.com/go-git/go-git/v6"
+	format "github.com/go-git/go-git/v6/plumbing/format/config"
+	"github.com/go-git/go-git/v6/plumbing/transport"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+const (
+	GitLFSParamName              = "GIT_LFS"
+	GitLFSMaxObjectSizeParamName = "GIT_LFS_MAX_OBJECT_SIZE"
+	GitLFSMaxTotalSizeParamName  = "GIT_LFS_MAX_TOTAL_SIZE"
+)
+
+const (
+	// gitLFSPointerMaxSize is the maximum size of a pointer file, as used by git-lfs.
+	gitLFSPointerMaxSize = 1024
+	// gitLFSBatchSize is the maximum number of objects requested in a single batch.
+	gitLFSBatchSize = 100
+	gitLFSMediaType = "application/vnd.git-lfs+json"
+	gitLFSVersion   = "https://git-lfs.github.com/spec/v1"
+)
+
+var gitLFSOIDRegex = regexp.MustCompile(`^[a-f0-9]{64}$`)
+
+var gitLFSParameters = []v1beta1.ParameterDefinition{
+	{
+		Name: GitLFSParamName,
+		Description: "If true, Git LFS pointer files of the repository and its submodules are replaced " +
+			"with the objects they point to, downloaded with the LFS batch API of the clone URL " +
+			"(or of the 'lfs.url' set in the '.lfsconfig' file) and the same authentication as the repository.",
+		Default: ptr.To("false"),
+	},
+	{
+		Name: GitLFSMaxObjectSizeParamName,
+		Description: "Maximum size of a single LFS object to download (e.g. '100Mi'). " +
+			"Larger objects are left as pointer files and counted in the git metadata. Set to 0 for no limit.",
+		Default: ptr.To("100Mi"),
+	},
+	{
+		Name: GitLFSMaxTotalSizeParamName,
+		Description: "Maximum total size of the LFS objects to download (e.g. '1Gi'). Once reached, " +
+			"the remaining objects are left as pointer files and counted in the git metadata. " +
+			"Set to 0 for no limit.",
+		Default: ptr.To("1Gi"),
+	},
+}
+
+// gitLFSOptions is the parsed configuration of which LFS objects are downloaded.
+type gitLFSOptions struct {
+	enabled                     bool
+	maxObjectSize, maxTotalSize int64
+}
+
+func parseGitLFSOptions(params map[string]string) (gitLFSOptions, error) {
+	var (
+		opts gitLFSOptions
+		err  error
+	)
+	if opts.enabled, err = input.BoolParam(params, GitLFSParamName, false); err != nil {
+		return opts, err
+	}
+	if opts.maxObjectSize, err = parseGitLFSSize(params, GitLFSMaxObjectSizeParamName); err != nil {
+		return opts, err
+	}
+	if opts.maxTotalSize, err = parseGitLFSSize(params, GitLFSMaxTotalSizeParamName); err != nil {
+		return opts, err
+	}
+	return opts, nil
+}
+
+func parseGitLFSSize(params map[string]string, name string) (int64, error) {
+	value := strings.TrimSpace(params[name])
+	if value == "" {
+		return 0, nil
+	}
+	q, err := resource.ParseQuantity(value)
+	if err != nil || q.Sign() < 0 {
+		return 0, fmt.Errorf("invalid value for %s: %q is not a positive size", name, value)
+	}
+	return q.Value(), nil
+}
+
+// GitLFSMetadata records the LFS objects found in the checked out repositories.
+type GitLFSMetadata struct {
+	// Objects is the number of distinct objects referenced by pointer files.
+	Objects         int   `json:"objects"`
+	Downloaded      int   `json:"downloaded"`
+	DownloadedBytes int64 `json:"downloaded_bytes"`
+	// Skipped is the number of objects not downloaded because of the size limits.
+	Skipped int `json:"skipped,omitempty"`
+	// Failed is the number of objects the server returned an error for or that failed to download.
+	Failed int `json:"failed,omitempty"`
+}
+
+// gitLFSPointer is an LFS pointer file found in a worktree.
+type gitLFSPointer struct {
+	oid  string
+	size int64
+	// paths of the pointer files for the object, relative to the worktree.
+	paths []string
+}
+
+// smudgeGitLFS replaces the LFS pointer files of the checked out repository with their
+// objects. Objects the server cannot provide are left as pointer files and recorded in
+// the metadata, while errors with the batch API itself fail the download.
+func smudgeGitLFS(
+	ctx context.Context,
+	creds *gitCredentials,
+	checkout gitCheckout,
+	dir string,
+	opts gitLFSOptions,
+	metadata *GitLFSMetadata,
+) error {
+	l := log.FromContext(ctx).WithValues("path", checkout.path)
+
+	pointers, err := findGitLFSPointers(dir)
+	if err != nil {
+		return fmt.Errorf("failed to find LFS pointer files: %w", err)
+	}
+	if len(pointers) == 0 {
+		return nil
+	}
+	metadata.Objects += len(pointers)
+
+	endpoint, err := gitLFSEndpoint(checkout.url, dir)
+	if err != nil {
+		return err
+	}
+	// credentials are looked up for the repository when the server is the same, since
+	// the GitHub App credentials are for the repository in the path of its URL
+	authURL := endpoint
+	if repoURL, err := transport.ParseURL(checkout.url); err == nil {
+		if u, err := url.Parse(endpoint); err == nil && u.Hostname() == repoURL.Hostname() {
+			authURL = (&url.URL{Scheme: u.Scheme, Host: u.Host, Path: path.Join("/", repoURL.Path)}).String()
+		}
+	}
+	auth, err := creds.authenticate(ctx, authURL)
+	if err != nil {
+		return err
+	}
+	l.Info("downloading LFS objects", "endpoint", endpoint, "objects", len(pointers))
+
+	var wanted []*gitLFSPointer
+	for _, p := range pointers {
+		switch {
+		case opts.maxObjectSize > 0 && p.size > opts.maxObjectSize:
+			l.Info("skipping LFS object larger than the maximum size", "oid", p.oid, "size", p.size)
+			metadata.Skipped++
+		case opts.maxTotalSize > 0 && metadata.DownloadedBytes+p.size > opts.maxTotalSize:
+			l.Info("skipping LFS object exceeding the maximum total size", "oid", p.oid, "size", p.size)
+			metadata.Skipped++
+		default:
+			// reserve the size, so the total limit applies to the objects of all batches
+			metadata.DownloadedBytes += p.size
+			wanted = append(wanted, p)
+		}
+	}
+
+	for start := 0; start < len(wanted); start += gitLFSBatchSize {
+		batch := wanted[start:min(start+gitLFSBatchSize, len(wanted))]
+		objects, err := requestGitLFSBatch(ctx, endpoint, auth, batch)
+		if err != nil {
+			return err
+		}
+		for _, p := range batch {
+			object, ok := objects[p.oid]
+			if !ok || object.Error != nil || object.Actions.Download == nil {
+				l.Info("LFS object is not available", "oid", p.oid, "error", object.Error)
+				metadata.Failed++
+				metadata.DownloadedBytes -= p.size
+				continue
+			}
+			if err = downloadGitLFSObject(ctx, auth.client(), object.Actions.Download, p, dir); err != nil {
+				l.Error(err, "failed to download LFS object", "oid", p.oid)
+				metadata.Failed++
+				metadata.DownloadedBytes -= p.size
+				continue
+			}
+			metadata.Downloaded++
+		}
+	}
+	return nil
+}
+
+// findGitLFSPointers returns the LFS pointer files of the worktree, grouped by object.
+// The worktrees of submodules are skipped, since their objects are stored on their own server.
+func findGitLFSPointers(dir string) ([]*gitLFSPointer, error) {
+	var (
+		pointers []*gitLFSPointer
+		byOID    = make(map[string]*gitLFSPointer)
+	)
+	err := filepath.WalkDir(dir, func(file string, e fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if e.IsDir() {
+			if e.Name() == gogit.GitDirName {
+				return filepath.SkipDir
+			}
+			if file != dir {
+				if _, err := os.Lstat(filepath.Join(file, gogit.GitDirName)); err == nil {
+					return filepath.SkipDir
+				}
+			}
+			return nil
+		}
+		if !e.Type().IsRegular() {
+			return nil
+		}
+		info, err := e.Info()
+		if err != nil || info.Size() > gitLFSPointerMaxSize {
+			return err
+		}
+		content, err := os.ReadFile(filepath.Clean(file))
+		if err != nil {
+			return err
+		}
+		oid, size, ok := parseGitLFSPointer(content)
+		if !ok {
+			return nil
+		}
+		rel, err := filepath.Rel(dir, file)
+		if err != nil {
+			return err
+		}
+		p, ok := byOID[oid]
+		if !ok {
+			p = &gitLFSPointer{oid: oid, size: size}
+			byOID[oid] = p
+			pointers = append(pointers, p)
+		}
+		p.paths = append(p.paths, rel)
+		return nil
+	})
+	return pointers, err
+}
+
+// parseGitLFSPointer parses the contents of a file as an LFS pointer,
+// returning the sha256 object ID and size of the object it points to.
+func parseGitLFSPointer(content []byte) (string, int64, bool) {
+	var (
+		oid     string
+		size    int64 = -1
+		version bool
+	)
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	for scanner.Scan() {
+		key, value, ok := strings.Cut(scanner.Text(), " ")
+		if !ok {
+			return "", 0, false
+		}
+		switch key {
+		case "version":
+			version = value == gitLFSVersion
+		case "oid":
+			digest, ok := strings.CutPrefix(value, "sha256:")
+			if !ok || !gitLFSOIDRegex.MatchString(digest) {
+				return "", 0, false
+			}
+			oid = digest
+		case "size":
+			n, err := strconv.ParseInt(value, 10, 64)
+			if err != nil || n < 0 {
+				return "", 0, false
+			}
+			size = n
+		}
+	}
+	if !version || oid == "" || size < 0 {
+		return "", 0, false
+	}
+	return oid, size, true
+}
+
+// gitLFSEndpoint returns the URL of the LFS server of the repository, which is the
+// 'lfs.url' of the '.lfsconfig' file if set, otherwise derived from the clone URL.
+// The server of SSH clone URLs is assumed to serve the LFS API over HTTPS.
+func gitLFSEndpoint(cloneURL, dir string) (string, error) {
+	endpoint := ""
+	if f, err := os.Open(filepath.Join(dir, ".lfsconfig")); err == nil {
+		cfg := format.New()
+		err = format.NewDecoder(f).Decode(cfg)
+		_ = f.Close()
+		if err != nil {
+			return "", fmt.Errorf("failed to read .lfsconfig: %w", err)
+		}
+		endpoint = cfg.Section("lfs").Option("url")
+	}
+	if endpoint == "" {
+		endpoint = strings.TrimSuffix(cloneURL, "/")
+		if u, err := transport.ParseURL(endpoint); err == nil && u.Scheme == "ssh" {
+			endpoint = "https://" + u.Hostname() + "/" + strings.TrimPrefix(u.Path, "/")
+		}
+		if !strings.HasSuffix(endpoint, ".git") {
+			endpoint += ".git"
+		}
+		endpoint += "/info/lfs"
+	}
+
+	u, err := url.Parse(endpoint)
+	if err != nil {
+		return "", fmt.Errorf("invalid LFS endpoint %q: %w", endpoint, err)
+	}
+	if u.Scheme != "https" && u.Scheme != "http" {
+		return "", fmt.Errorf("unsupported LFS endpoint %q, only HTTP(S) is supported", endpoint)
+	}
+	return endpoint, nil
+}
+
+type gitLFSBatchObject struct {
+	OID     string `json:"oid"`
+	Size    int64  `json:"size"`
+	Actions struct {
+		Download *gitLFSAction `json:"download,omitempty"`
+	} `json:"actions,omitzero"`
+	Error *struct {
+		Code    int    `json:"code"`
+		Message string `json:"message"`
+	} `json:"error,omitempty"`
+}
+
+type gitLFSAction struct {
+	Href   string            `json:"href"`
+	Header map[string]string `json:"header,omitempty"`
+}
+
+// requestGitLFSBatch requests the download actions of the objects, returned by object ID.
+func requestGitLFSBatch(
+	ctx context.Context,
+	endpoint string,
+	auth gitAuth,
+	pointers []*gitLFSPointer,
+) (map[string]gitLFSBatchObject, error) {
+	request := struct {
+		Operation string              `json:"operation"`
+		Transfers []string            `json:"transfers"`
+		Objects   []gitLFSBatchObject `json:"objects"`
+	}{
+		Operation: "download",
+		Transfers: []string{"basic"},
+	}
+	for _, p := range pointers {
+		request.Objects = append(request.Objects, gitLFSBatchObject{OID: p.oid, Size: p.size})
+	}
+	body, err := json.Marshal(request)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint+"/objects/batch", bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", gitLFSMediaType)
+	req.Header.Set("Content-Type", gitLFSMediaType)
+	if auth.http != nil {
+		if err = auth.http.Authorizer(req); err != nil {
+			return nil, err
+		}
+	}
+
+	resp, err := auth.client().Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("LFS batch request failed: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+	if resp.StatusCode != http.StatusOK {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return nil, fmt.Errorf("LFS batch request failed with status %s: %s", resp.Status, bytes.TrimSpace(msg))
+	}
+
+	var response struct {
+		Objects []gitLFSBatchObject `json:"objects"`
+	}
+	if err = json.NewDecoder(resp.Body).Decode(&response); err != nil {
+		return nil, fmt.Errorf("invalid LFS batch response: %w", err)
+	}
+	objects := make(map[string]gitLFSBatchObject, len(response.Objects))
+	for _, object := range response.Objects {
+		objects[object.OID] = object
+	}
+	return objects, nil
+}
+
+// downloadGitLFSObject downloads the object and replaces its pointer files with it,
+// once its contents are verified to match the object ID and size of the pointer.
+func downloadGitLFSObject(
+	ctx context.Context,
+	httpClient *http.Client,
+	action *gitLFSAction,
+	p *gitLFSPointer,
+	dir string,
+) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, action.Href, nil)
+	if err != nil {
+		return err
+	}
+	for key, value := range action.Header {
+		req.Header.Set(key, value)
+	}
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = resp.Body.Close() }()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("download failed with status %s", resp.Status)
+	}
+
+	first := filepath.Join(dir, p.paths[0])
+	tmp, err := os.CreateTemp(filepath.Dir(first), ".lfs-*")
+	if err != nil {
+		return err
+	}
+	defer func() { _ = os.Remove(tmp.Name()) }()
+
+	hash := sha256.New()
+	n, err := io.Copy(io.MultiWriter(tmp, hash), io.LimitReader(resp.Body, p.size+1))
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return err
+	}
+	if n != p.size {
+		return fmt.Errorf("expected %d bytes, got %d", p.size, n)
+	}
+	if digest := hex.EncodeToString(hash.Sum(nil)); digest != p.oid {
+		return fmt.Errorf("checksum mismatch, got sha256:%s", digest)
+	}
+
+	for _, rel := range p.paths {
+		if err = replaceGitLFSPointer(tmp.Name(), filepath.Join(dir, rel)); err != nil {
+			return fmt.Errorf("failed to replace %s: %w", rel, err)
+		}
+	}
+	return nil
+}
+
+// replaceGitLFSPointer copies the object over the pointer file, keeping its mode.
+func replaceGitLFSPointer(object, pointer string) error {
+	info, err := os.Stat(pointer)
+	if err != nil {
+		return err
+	}
+	src, err := os.Open(filepath.Clean(object))
+	if err != nil {
+		return err
+	}
+	defer func() { _ = src.Close() }()
+
+	dst, err := os.OpenFile(filepath.Clean(pointer), os.O_WRONLY|os.O_TRUNC, info.Mode().Perm())
+	if err != nil {
+		return err
+	}
+	if _, err = io.Copy(dst, src); err != nil {
+		_ = dst.Close()
+		return err
+	}
+	return dst.Close()
+}
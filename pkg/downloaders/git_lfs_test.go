@@ -0,0 +1,107 @@
+// Copyright (C) 2025-2026 Crash Override, Inc.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the FSF, either version 3 of the License, or (at your option) any later version.
+// See the LICENSE file in the root of this repository for full license text or
+// visit: <https://www.gnu.org/licenses/gpl-3.0.html>.
+
+package downloaders
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseGitLFSPointer(t *testing.T) {
+	const oid = "4d7a214614ab2935c943f9e0ff69d22eadbb8f32b1258daaa5e2ca24d17e2393"
+	pointer := func(lines ...string) string {
+		return strings.Join(lines, "\n") + "\n"
+	}
+
+	tests := []struct {
+		name     string
+		content  string
+		wantOID  string
+		wantSize int64
+		wantOK   bool
+	}{
+		{
+			name:     "valid pointer",
+			content:  pointer("version "+gitLFSVersion, "oid sha256:"+oid, "size 12345"),
+			wantOID:  oid,
+			wantSize: 12345,
+			wantOK:   true,
+		},
+		{
+			name:     "empty object",
+			content:  pointer("version "+gitLFSVersion, "oid sha256:"+oid, "size 0"),
+			wantOID:  oid,
+			wantSize: 0,
+			wantOK:   true,
+		},
+		{
+			name:     "unknown keys are ignored",
+			content:  pointer("version "+gitLFSVersion, "ext-0-foo sha256:"+oid, "oid sha256:"+oid, "size 1"),
+			wantOID:  oid,
+			wantSize: 1,
+			wantOK:   true,
+		},
+		{
+			name:    "missing version",
+			content: pointer("oid sha256:"+oid, "size 1"),
+		},
+		{
+			name:    "unknown version",
+			content: pointer("version https://example.com/spec/v2", "oid sha256:"+oid, "size 1"),
+		},
+		{
+			name:    "missing oid",
+			content: pointer("version "+gitLFSVersion, "size 1"),
+		},
+		{
+			name:    "unsupported hash",
+			content: pointer("version "+gitLFSVersion, "oid sha1:"+oid[:40], "size 1"),
+		},
+		{
+			name:    "invalid oid",
+			content: pointer("version "+gitLFSVersion, "oid sha256:"+strings.ToUpper(oid), "size 1"),
+		},
+		{
+			name:    "missing size",
+			content: pointer("version "+gitLFSVersion, "oid sha256:"+oid),
+		},
+		{
+			name:    "negative size",
+			content: pointer("version "+gitLFSVersion, "oid sha256:"+oid, "size -1"),
+		},
+		{
+			name:    "invalid size",
+			content: pointer("version "+gitLFSVersion, "oid sha256:"+oid, "size large"),
+		},
+		{
+			name:    "line without value",
+			content: pointer("version "+gitLFSVersion, "oid sha256:"+oid, "size 1", "trailer"),
+		},
+		{
+			name:    "regular file",
+			content: "package main\n",
+		},
+		{
+			name:    "empty file",
+			content: "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gotOID, gotSize, ok := parseGitLFSPointer([]byte(tt.content))
+			if ok != tt.wantOK {
+				t.Fatalf("got ok %t, want %t", ok, tt.wantOK)
+			}
+			if gotOID != tt.wantOID || gotSize != tt.wantSize {
+				t.Errorf("got (%q, %d), want (%q, %d)", gotOID, gotSize, tt.wantOID, tt.wantSize)
+			}
+		})
+	}
+}
@@ -0,0 +1,238 @@
+// Copyright (C) 2025-2026 Crash Override, Inc.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the FSF, either version 3 of the License, or (at your option) any later version.
+// See the LICENSE file in the root of this repository for full license text or
+// visit: <https://www.gnu.org/licenses/gpl-3.0.html>.
+
+package downloaders
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"net/http"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	gogit "github.com/go-git/go-git/v6"
+	"github.com/go-git/go-git/v6/plumbing"
+	"github.com/go-git/go-git/v6/plumbing/transport"
+	githttp "github.com/go-git/go-git/v6/plumbing/transport/http"
+	"github.com/google/go-github/v71/github"
+	gitlab "gitlab.com/gitlab-org/api/client-go"
+	"k8s.io/utils/ptr"
+)
+
+// gitVisibilityTimeout is the maximum time spent querying
+// the API of the git host for the visibility of the repository.
+const gitVisibilityTimeout = 30 * time.Second
+
+// GitSignature is the author or committer of a commit.
+type GitSignature struct {
+	Name  string    `json:"name"`
+	Email string    `json:"email"`
+	When  time.Time `json:"when"`
+}
+
+// GitCommitMetadata records the commit checked out.
+type GitCommitMetadata struct {
+	Author    GitSignature `json:"author"`
+	Committer GitSignature `json:"committer"`
+	// Timestamp is the time the commit was committed.
+	Timestamp time.Time `json:"timestamp"`
+	// Subject is the first line of the commit message.
+	Subject string `json:"subject"`
+}
+
+// GitStatsMetadata records the size of the checked out repository.
+type GitStatsMetadata struct {
+	// Files is the number of files checked out, excluding the .git directory.
+	Files int `json:"files"`
+	// Size is the total size in bytes of the files checked out.
+	Size int64 `json:"size"`
+	// GitSize is the size in bytes of the .git directory.
+	GitSize int64 `json:"git_size"`
+}
+
+// readGitCommitMetadata returns the author, committer and subject of the commit.
+func readGitCommitMetadata(repo *gogit.Repository, hash plumbing.Hash) (*GitCommitMetadata, error) {
+	commit, err := repo.CommitObject(hash)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read commit %s: %w", hash, err)
+	}
+	subject, _, _ := strings.Cut(strings.TrimSpace(commit.Message), "\n")
+	return &GitCommitMetadata{
+		Author: GitSignature{
+			Name:  commit.Author.Name,
+			Email: commit.Author.Email,
+			When:  commit.Author.When,
+		},
+		Committer: GitSignature{
+			Name:  commit.Committer.Name,
+			Email: commit.Committer.Email,
+			When:  commit.Committer.When,
+		},
+		Timestamp: commit.Committer.When,
+		Subject:   strings.TrimSpace(subject),
+	}, nil
+}
+
+// gitRemoteRefs lists the references of the origin remote, and returns the default branch
+// along with the sorted names of the branches and tags pointing to the commit. The remote
+// is listed rather than the local references, since only some may have been fetched.
+func gitRemoteRefs(
+	ctx context.Context,
+	repo *gogit.Repository,
+	auth gitAuth,
+	hash plumbing.Hash,
+) (defaultBranch string, branches, tags []string, err error) {
+	remote, err := repo.Remote("origin")
+	if err != nil {
+		return "", nil, nil, err
+	}
+	refs, err := remote.ListContext(ctx, &gogit.ListOptions{
+		ClientOptions: auth.clientOptions(),
+		// annotated tags are listed along with the commit they point to
+		PeelingOption: gogit.AppendPeeled,
+	})
+	if err != nil {
+		return "", nil, nil, fmt.Errorf("failed to list remote references: %w", err)
+	}
+
+	for _, ref := range refs {
+		name := ref.Name()
+		if name == plumbing.HEAD && ref.Type() == plumbing.SymbolicReference {
+			defaultBranch = ref.Target().Short()
+			continue
+		}
+		if ref.Type() != plumbing.HashReference || ref.Hash() != hash {
+			continue
+		}
+		switch {
+		case name.IsBranch():
+			branches = append(branches, name.Short())
+		case name.IsTag():
+			tags = append(tags, strings.TrimSuffix(name.Short(), "^{}"))
+		}
+	}
+	slices.Sort(branches)
+	slices.Sort(tags)
+	return defaultBranch, branches, slices.Compact(tags), nil
+}
+
+// readGitStats counts the files checked out in the target directory
+// and their size, along with the size of the .git directory.
+func readGitStats(targetDir string) (GitStatsMetadata, error) {
+	var stats GitStatsMetadata
+	gitDir := filepath.Join(targetDir, gogit.GitDirName)
+	err := filepath.WalkDir(targetDir, func(file string, e fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !e.Type().IsRegular() {
+			return nil
+		}
+		info, err := e.Info()
+		if err != nil {
+			return err
+		}
+		if strings.HasPrefix(file, gitDir+string(filepath.Separator)) {
+			stats.GitSize += info.Size()
+			return nil
+		}
+		stats.Files++
+		stats.Size += info.Size()
+		return nil
+	})
+	return stats, err
+}
+
+// readGitVisibility returns the visibility of a GitHub or GitLab repository from
+// the API of its host, and whether it is public. Repositories not found are reported
+// as not public, since the API does not distinguish them from private repositories
+// the credentials cannot access. An empty visibility is returned for other hosts.
+func readGitVisibility(ctx context.Context, creds *gitCredentials, cloneURL string) (string, *bool, error) {
+	u, err := transport.ParseURL(cloneURL)
+	if err != nil {
+		return "", nil, err
+	}
+	host := u.Hostname()
+	isGitLab := host == "gitlab.com" || strings.HasPrefix(host, "gitlab.")
+	if host != "github.com" && !isGitLab {
+		return "", nil, nil
+	}
+	scheme := u.Scheme
+	if scheme != "http" {
+		// the API of hosts cloned over SSH is served over HTTPS
+		scheme = "https"
+	}
+	repoPath := strings.TrimSuffix(strings.Trim(u.Path, "/"), ".git")
+
+	// the API is authenticated with the credentials of the repository over HTTPS, if any
+	auth, err := creds.authenticate(ctx, fmt.Sprintf("%s://%s/%s", scheme, u.Host, repoPath))
+	if err != nil {
+		return "", nil, err
+	}
+	var token string
+	if basic, ok := auth.http.(*githttp.BasicAuth); ok {
+		token = basic.Password
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, gitVisibilityTimeout)
+	defer cancel()
+
+	if isGitLab {
+		return readGitLabVisibility(ctx, auth.client(), token, scheme+"://"+u.Host+"/api/v4", repoPath)
+	}
+	return readGitHubVisibility(ctx, auth.client(), token, repoPath)
+}
+
+func readGitHubVisibility(ctx context.Context, httpClient *http.Client, token, repoPath string) (string, *bool, error) {
+	owner, name, ok := strings.Cut(repoPath, "/")
+	if !ok || strings.Contains(name, "/") {
+		return "", nil, fmt.Errorf("invalid GitHub repository path %q", repoPath)
+	}
+	gh := github.NewClient(httpClient)
+	if token != "" {
+		gh = gh.WithAuthToken(token)
+	}
+	repo, resp, err := gh.Repositories.Get(ctx, owner, name)
+	if resp != nil && resp.StatusCode == http.StatusNotFound {
+		return "", ptr.To(false), nil
+	}
+	if err != nil {
+		return "", nil, err
+	}
+	visibility := repo.GetVisibility()
+	if visibility == "" && repo.Private != nil {
+		visibility = "public"
+		if repo.GetPrivate() {
+			visibility = "private"
+		}
+	}
+	return visibility, ptr.To(!repo.GetPrivate() && visibility == "public"), nil
+}
+
+func readGitLabVisibility(
+	ctx context.Context,
+	httpClient *http.Client,
+	token, baseURL, repoPath string,
+) (string, *bool, error) {
+	gl, err := gitlab.NewClient(token, gitlab.WithBaseURL(baseURL), gitlab.WithHTTPClient(httpClient))
+	if err != nil {
+		return "", nil, err
+	}
+	project, resp, err := gl.Projects.GetProject(repoPath, nil, gitlab.WithContext(ctx))
+	if resp != nil && resp.StatusCode == http.StatusNotFound {
+		return "", ptr.To(false), nil
+	}
+	if err != nil {
+		return "", nil, err
+	}
+	visibility := string(project.Visibility)
+	return visibility, ptr.To(project.Visibility == gitlab.PublicVisibility), nil
+}
@@ -0,0 +1,146 @@
+// Copyright (C) 2025-2026 Crash Override, Inc.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the FSF, either version 3 of the License, or (at your option) any later version.
+// See the LICENSE file in the root of this repository for full license text or
+// visit: <https://www.gnu.org/licenses/gpl-3.0.html>.
+
+package downloaders
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/crashappsec/ocular/api/v1beta1"
+	gogit "github.com/go-git/go-git/v6"
+	"k8s.io/utils/ptr"
+)
+
+const (
+	GitSparsePathsParamName = "GIT_SPARSE_PATHS"
+)
+
+var gitSparseParameters = []v1beta1.ParameterDefinition{
+	{
+		Name: GitSparsePathsParamName,
+		Description: "Comma or newline separated list of paths to check out, relative to the root of " +
+			"the repository. Each path segment may be a glob pattern (e.g. 'services/*/src'), and a " +
+			"matching directory is checked out with all its contents. A subdirectory can also be given in " +
+			"the target identifier after a double slash (e.g. 'https://github.com/org/repo.git//services/api'), " +
+			"which is added to the paths. If empty, the whole repository is checked out. The other files " +
+			"are marked skip-worktree in the index, and the paths are written to the sparse-checkout file of " +
+			"the repository, so that git reports the worktree as clean. Sparse paths only limit the files " +
+			"written: all the objects of the fetched commits are still downloaded, since go-git cannot " +
+			"fetch missing objects on demand. " + GitFilterParamName + " can omit file contents from the " +
+			"fetch, in which case files whose contents were omitted are not written.",
+		Default: ptr.To(""),
+	},
+}
+
+// splitGitSubpath splits the subdirectory from a target identifier, which is separated
+// from the clone URL by a double slash (e.g. 'https://github.com/org/repo.git//services/api').
+func splitGitSubpath(identifier string) (string, string) {
+	start := 0
+	if i := strings.Index(identifier, "://"); i >= 0 {
+		start = i + len("://")
+	}
+	i := strings.Index(identifier[start:], "//")
+	if i < 0 {
+		return identifier, ""
+	}
+	return identifier[:start+i], strings.Trim(identifier[start+i+len("//"):], "/")
+}
+
+// gitSparsePaths are the path patterns of a sparse checkout. If empty, all paths are checked out.
+type gitSparsePaths []string
+
+// parseGitSparsePaths parses the sparse path patterns of the parameter, along with the
+// subdirectory of the target identifier if any. Patterns must be relative to the
+// root of the repository, and cannot refer to a parent directory.
+func parseGitSparsePaths(params map[string]string, subpath string) (gitSparsePaths, error) {
+	values := strings.FieldsFunc(params[GitSparsePathsParamName], func(r rune) bool {
+		return r == ',' || r == '\n'
+	})
+	if subpath != "" {
+		values = append(values, subpath)
+	}
+
+	var paths gitSparsePaths
+	for _, value := range values {
+		value = strings.TrimSpace(value)
+		if value == "" {
+			continue
+		}
+		p := path.Clean(strings.TrimSuffix(value, "/"))
+		if p == "." || path.IsAbs(p) || p == ".." || strings.HasPrefix(p, "../") {
+			return nil, fmt.Errorf("invalid sparse path %q, must be relative to the root of the repository", value)
+		}
+		if _, err := path.Match(p, ""); err != nil {
+			return nil, fmt.Errorf("invalid sparse path %q: %w", value, err)
+		}
+		if !slices.Contains(paths, p) {
+			paths = append(paths, p)
+		}
+	}
+	return paths, nil
+}
+
+// match reports whether the path, relative to the root of the repository, is checked
+// out. If it is not, partial reports whether paths under it may be checked out.
+func (s gitSparsePaths) match(p string) (included, partial bool) {
+	if len(s) == 0 {
+		return true, false
+	}
+	parts := strings.Split(p, "/")
+	for _, pattern := range s {
+		segments := strings.Split(pattern, "/")
+		matched := true
+		for i := range min(len(parts), len(segments)) {
+			if ok, _ := path.Match(segments[i], parts[i]); !ok {
+				matched = false
+				break
+			}
+		}
+		if !matched {
+			continue
+		}
+		if len(parts) >= len(segments) {
+			return true, false
+		}
+		partial = true
+	}
+	return false, partial
+}
+
+// writeGitSparseCheckout enables sparse checkout in the config of the repository, and writes
+// the sparse paths to its sparse-checkout file, so that git only checks out the same paths.
+// Each path is anchored to the root of the repository, and its glob patterns match a single
+// path segment, as they do in the sparse paths.
+func writeGitSparseCheckout(repo *gogit.Repository, targetDir string, sparse gitSparsePaths) error {
+	cfg, err := repo.Config()
+	if err != nil {
+		return err
+	}
+	cfg.Raw.SetOption("core", "", "sparseCheckout", "true")
+	if err = repo.SetConfig(cfg); err != nil {
+		return fmt.Errorf("failed to enable sparse checkout: %w", err)
+	}
+
+	var patterns strings.Builder
+	for _, p := range sparse {
+		patterns.WriteString("/" + p + "\n")
+	}
+	infoDir := filepath.Join(targetDir, gogit.GitDirName, "info")
+	if err = os.MkdirAll(infoDir, 0o755); err != nil {
+		return err
+	}
+	if err = os.WriteFile(filepath.Join(infoDir, "sparse-checkout"), []byte(patterns.String()), 0o644); err != nil {
+		return fmt.Errorf("failed to write sparse-checkout file: %w", err)
+	}
+	return nil
+}
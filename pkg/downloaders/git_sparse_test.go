@@ -0,0 +1,100 @@
+// Copyright (C) 2025-2026 Crash Override, Inc.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the FSF, either version 3 of the License, or (at your option) any later version.
+// See the LICENSE file in the root of this repository for full license text or
+// visit: <https://www.gnu.org/licenses/gpl-3.0.html>.
+
+package downloaders
+
+import "testing"
+
+func TestGitSparsePathsMatch(t *testing.T) {
+	tests := []struct {
+		name         string
+		sparse       gitSparsePaths
+		path         string
+		wantIncluded bool
+		wantPartial  bool
+	}{
+		{
+			name:         "no paths include everything",
+			sparse:       nil,
+			path:         "services/a",
+			wantIncluded: true,
+		},
+		{
+			name:         "exact path",
+			sparse:       gitSparsePaths{"services/a"},
+			path:         "services/a",
+			wantIncluded: true,
+		},
+		{
+			name:         "path under directory",
+			sparse:       gitSparsePaths{"services/a"},
+			path:         "services/a/src/x",
+			wantIncluded: true,
+		},
+		{
+			name:        "parent of directory",
+			sparse:      gitSparsePaths{"services/a"},
+			path:        "services",
+			wantPartial: true,
+		},
+		{
+			name:   "sibling of directory",
+			sparse: gitSparsePaths{"services/a"},
+			path:   "services/b",
+		},
+		{
+			name:   "path with common prefix",
+			sparse: gitSparsePaths{"services/a"},
+			path:   "services/ab",
+		},
+		{
+			name:   "unrelated path",
+			sparse: gitSparsePaths{"services/a"},
+			path:   "docs/readme",
+		},
+		{
+			name:         "glob matches a single segment",
+			sparse:       gitSparsePaths{"services/*/src"},
+			path:         "services/b/src/y",
+			wantIncluded: true,
+		},
+		{
+			name:        "glob parent",
+			sparse:      gitSparsePaths{"services/*/src"},
+			path:        "services/b",
+			wantPartial: true,
+		},
+		{
+			name:   "glob does not match other segment",
+			sparse: gitSparsePaths{"services/*/src"},
+			path:   "services/a/other",
+		},
+		{
+			name:         "any pattern may include",
+			sparse:       gitSparsePaths{"docs", "services/a"},
+			path:         "services/a/src",
+			wantIncluded: true,
+		},
+		{
+			name:         "including pattern wins over partial one",
+			sparse:       gitSparsePaths{"services/a/src", "services"},
+			path:         "services/a",
+			wantIncluded: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			included, partial := tt.sparse.match(tt.path)
+			if included != tt.wantIncluded || partial != tt.wantPartial {
+				t.Errorf("match(%q) = (%t, %t), want (%t, %t)",
+					tt.path, included, partial, tt.wantIncluded, tt.wantPartial)
+			}
+		})
+	}
+}
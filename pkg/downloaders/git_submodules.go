@@ -0,0 +1,152 @@
+// Copyright (C) 2025-2026 Crash Override, Inc.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the FSF, either version 3 of the License, or (at your option) any later version.
+// See the LICENSE file in the root of this repository for full license text or
+// visit: <https://www.gnu.org/licenses/gpl-3.0.html>.
+
+package downloaders
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"strings"
+
+	"github.com/crashappsec/ocular/api/v1beta1"
+	gogit "github.com/go-git/go-git/v6"
+	"github.com/go-git/go-git/v6/plumbing/transport"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+const (
+	GitSubmodulesParamName = "GIT_SUBMODULES"
+)
+
+// gitMaxSubmoduleDepth is the maximum nesting level of submodules that are checked out.
+const gitMaxSubmoduleDepth = int(gogit.DefaultSubmoduleRecursionDepth)
+
+var gitSubmoduleParameters = []v1beta1.ParameterDefinition{
+	{
+		Name: GitSubmodulesParamName,
+		Description: "If true, submodules are recursively checked out at the commits recorded in the repository, " +
+			"with the same authentication as the repository for their host and the same " + GitDepthParamName + ". " +
+			"Relative submodule URLs are resolved against the clone URL. " +
+			"Not supported along with " + GitFilterParamName + " or " + GitSparsePathsParamName + ".",
+		Default: ptr.To("false"),
+	},
+}
+
+// GitSubmoduleMetadata records a submodule checked out in the target directory.
+type GitSubmoduleMetadata struct {
+	// Path is the path of the submodule relative to the target directory.
+	Path string `json:"path"`
+	URL  string `json:"url"`
+	Hash string `json:"hash"`
+}
+
+// gitCheckout is a repository checked out in the target
+// directory, either the target itself or one of its submodules.
+type gitCheckout struct {
+	repo *gogit.Repository
+	url  string
+	// path of the worktree relative to the target directory, empty for the target.
+	path string
+}
+
+// updateGitSubmodules checks out the submodules of the repository, and recursively their own
+// submodules. Submodules are updated one at a time rather than with the recursive option of
+// go-git, so that each is fetched with the authentication for its host.
+func updateGitSubmodules(
+	ctx context.Context,
+	creds *gitCredentials,
+	parent gitCheckout,
+	depth, level int,
+) ([]gitCheckout, []GitSubmoduleMetadata, error) {
+	l := log.FromContext(ctx)
+
+	worktree, err := parent.repo.Worktree()
+	if err != nil {
+		return nil, nil, err
+	}
+	submodules, err := worktree.Submodules()
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to read submodules of %q: %w", parent.url, err)
+	}
+
+	var (
+		checkouts []gitCheckout
+		metadata  []GitSubmoduleMetadata
+	)
+	for _, sub := range submodules {
+		cfg := sub.Config()
+		subPath := path.Join(parent.path, cfg.Path)
+		subURL, err := resolveGitSubmoduleURL(parent.url, cfg.URL)
+		if err != nil {
+			return nil, nil, fmt.Errorf("submodule %q: %w", subPath, err)
+		}
+		// set before the submodule is initialized, so its remote is created with the resolved URL
+		cfg.URL = subURL
+
+		auth, err := creds.authenticate(ctx, subURL)
+		if err != nil {
+			return nil, nil, fmt.Errorf("submodule %q: %w", subPath, err)
+		}
+
+		l.Info("updating submodule", "path", subPath, "url", subURL)
+		err = sub.UpdateContext(ctx, &gogit.SubmoduleUpdateOptions{
+			Init:              true,
+			RecurseSubmodules: gogit.NoRecurseSubmodules,
+			Depth:             depth,
+			ClientOptions:     auth.clientOptions(),
+		})
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to update submodule %q: %w", subPath, err)
+		}
+
+		subRepo, err := sub.Repository()
+		if err != nil {
+			return nil, nil, err
+		}
+		head, err := subRepo.Head()
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to read HEAD of submodule %q: %w", subPath, err)
+		}
+
+		checkout := gitCheckout{repo: subRepo, url: subURL, path: subPath}
+		checkouts = append(checkouts, checkout)
+		metadata = append(metadata, GitSubmoduleMetadata{
+			Path: subPath,
+			URL:  subURL,
+			Hash: head.Hash().String(),
+		})
+
+		if level+1 >= gitMaxSubmoduleDepth {
+			l.Info("maximum submodule depth reached, skipping nested submodules", "path", subPath)
+			continue
+		}
+		nestedCheckouts, nestedMetadata, err := updateGitSubmodules(ctx, creds, checkout, depth, level+1)
+		if err != nil {
+			return nil, nil, err
+		}
+		checkouts = append(checkouts, nestedCheckouts...)
+		metadata = append(metadata, nestedMetadata...)
+	}
+	return checkouts, metadata, nil
+}
+
+// resolveGitSubmoduleURL resolves a submodule URL relative to the URL of its
+// parent repository (e.g. '../lib.git'), in the same way as git does.
+func resolveGitSubmoduleURL(parentURL, submoduleURL string) (string, error) {
+	if !strings.HasPrefix(submoduleURL, "./") && !strings.HasPrefix(submoduleURL, "../") {
+		return submoduleURL, nil
+	}
+	u, err := transport.ParseURL(parentURL)
+	if err != nil {
+		return "", fmt.Errorf("unable to resolve relative URL %q against %q: %w", submoduleURL, parentURL, err)
+	}
+	u.Path = path.Join(strings.TrimSuffix(u.Path, "/"), submoduleURL)
+	return u.String(), nil
+}
@@ -0,0 +1,82 @@
+// Copyright (C) 2025-2026 Crash Override, Inc.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the FSF, either version 3 of the License, or (at your option) any later version.
+// See the LICENSE file in the root of this repository for full license text or
+// visit: <https://www.gnu.org/licenses/gpl-3.0.html>.
+
+package downloaders
+
+import "testing"
+
+func TestResolveGitSubmoduleURL(t *testing.T) {
+	tests := []struct {
+		name         string
+		parentURL    string
+		submoduleURL string
+		want         string
+		wantErr      bool
+	}{
+		{
+			name:         "absolute URL is unchanged",
+			parentURL:    "https://github.com/org/repo.git",
+			submoduleURL: "https://gitlab.com/other/lib.git",
+			want:         "https://gitlab.com/other/lib.git",
+		},
+		{
+			name:         "SCP-like URL is unchanged",
+			parentURL:    "https://github.com/org/repo.git",
+			submoduleURL: "git@github.com:org/lib.git",
+			want:         "git@github.com:org/lib.git",
+		},
+		{
+			name:         "sibling repository",
+			parentURL:    "https://github.com/org/repo.git",
+			submoduleURL: "../lib.git",
+			want:         "https://github.com/org/lib.git",
+		},
+		{
+			name:         "repository of other organization",
+			parentURL:    "https://github.com/org/repo",
+			submoduleURL: "../../other/lib",
+			want:         "https://github.com/other/lib",
+		},
+		{
+			name:         "repository under parent",
+			parentURL:    "https://git.example.com/group/repo/",
+			submoduleURL: "./lib",
+			want:         "https://git.example.com/group/repo/lib",
+		},
+		{
+			name:         "SSH parent keeps its user",
+			parentURL:    "ssh://git@github.com/org/repo.git",
+			submoduleURL: "../lib.git",
+			want:         "ssh://git@github.com/org/lib.git",
+		},
+		{
+			name:         "invalid parent URL",
+			parentURL:    "https://github.com/%zz",
+			submoduleURL: "../lib.git",
+			wantErr:      true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := resolveGitSubmoduleURL(tt.parentURL, tt.submoduleURL)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("expected an error, got %q", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
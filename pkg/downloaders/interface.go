@@ -10,27 +10,40 @@ package downloaders
 
 import (
 	"context"
+	"fmt"
+	"slices"
 
-	"github.com/crashappsec/ocular-default-integrations/internal/definitions"
+	"github.com/crashappsec/ocular-default-integrations/pkg/definitions"
 	"github.com/crashappsec/ocular/api/v1beta1"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
-var All = make(all)
+// All is the registry of the default downloaders, populated when the package is initialized.
+// Other modules can register their own downloaders to it and pass it to [Run],
+// to build a single image containing both the defaults and their integrations.
+var All = make(Registry)
 
-type all map[string]Downloader
+// Registry holds downloaders by name.
+type Registry map[string]Downloader
 
-func (a all) registerDownloader(d Downloader) {
-	if a == nil {
+// Register adds the downloader to the registry. It panics if the downloader has no name
+// or Download function, or if a downloader with the same name is already registered.
+// To replace a registered downloader, delete it from the registry first.
+func (r Registry) Register(d Downloader) {
+	if r == nil {
 		return
 	}
-
+	if d.Name == "" {
+		panic("downloader name must be set")
+	}
 	if d.Download == nil {
 		panic("download function must be defined")
 	}
-
-	a[d.Name] = d
+	if _, exists := r[d.Name]; exists {
+		panic(fmt.Sprintf("downloader %q is already registered", d.Name))
+	}
+	r[d.Name] = d
 }
 
 type Downloader struct {
@@ -43,9 +56,16 @@ type Downloader struct {
 	MetadataFiles        []string
 }
 
+// GenerateObjects returns the ClusterDownloader definitions of the default downloaders.
 func GenerateObjects(image, secretName string) []*v1beta1.ClusterDownloader {
-	downloaderObjs := make([]*v1beta1.ClusterDownloader, 0, len(All))
-	for _, d := range All {
+	return All.GenerateObjects(image, secretName)
+}
+
+// GenerateObjects returns a ClusterDownloader definition for each downloader in the registry,
+// running the given image and reading secrets from the secret secretName.
+func (r Registry) GenerateObjects(image, secretName string) []*v1beta1.ClusterDownloader {
+	downloaderObjs := make([]*v1beta1.ClusterDownloader, 0, len(r))
+	for _, d := range r {
 		downlaoderObj := &v1beta1.ClusterDownloader{
 			TypeMeta: metav1.TypeMeta{
 				APIVersion: v1beta1.SchemeGroupVersion.String(),
@@ -58,14 +78,15 @@ func GenerateObjects(image, secretName string) []*v1beta1.ClusterDownloader {
 				Container: corev1.Container{
 					Name:  d.Name,
 					Image: image,
-					Env:   d.EnvironmentVariables,
+					Env:   slices.Clone(d.EnvironmentVariables),
 				},
 				MetadataFiles: d.MetadataFiles,
 				Parameters:    d.Parameters,
 			},
 		}
 		if d.EnvironmentSecrets != nil {
-			downlaoderObj.Spec.Container.Env = definitions.EnvironmentSecretsToEnvVars(secretName, d.EnvironmentSecrets)
+			downlaoderObj.Spec.Container.Env = append(downlaoderObj.Spec.Container.Env,
+				definitions.EnvironmentSecretsToEnvVars(secretName, d.EnvironmentSecrets)...)
 		}
 
 		if d.FileSecrets != nil {
@@ -22,7 +22,7 @@ import (
 )
 
 func init() {
-	All.registerDownloader(NPM)
+	All.Register(NPM)
 }
 
 var NPM = Downloader{
@@ -38,7 +38,7 @@ func downloadNPM(ctx context.Context, _ map[string]string, packageName, version,
 	if err != nil {
 		return fmt.Errorf("failed to fetch package metadata: %w", err)
 	}
-	func() {
+	defer func() {
 		if err := resp.Body.Close(); err != nil {
 			l.Error(err, "failed to close response body")
 		}
@@ -20,7 +20,7 @@ import (
 )
 
 func init() {
-	All.registerDownloader(PyPi)
+	All.Register(PyPi)
 }
 
 var PyPi = Downloader{
@@ -0,0 +1,72 @@
+// Copyright (C) 2025-2026 Crash Override, Inc.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the FSF, either version 3 of the License, or (at your option) any later version.
+// See the LICENSE file in the root of this repository for full license text or
+// visit: <https://www.gnu.org/licenses/gpl-3.0.html>.
+
+package downloaders
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/crashappsec/ocular-default-integrations/pkg/input"
+	"github.com/crashappsec/ocular/api/v1beta1"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// Run runs the downloader of the registry selected by the environment
+// of the pipeline container, downloading the target to the target directory.
+func Run(ctx context.Context, registry Registry) error {
+	logger := log.FromContext(ctx)
+
+	targetDir := os.Getenv(v1beta1.EnvVarTargetDir)
+	targetIdentifier := os.Getenv(v1beta1.EnvVarTargetIdentifier)
+	targetVersion := os.Getenv(v1beta1.EnvVarTargetVersion)
+
+	downloaderName := strings.TrimPrefix(os.Getenv(v1beta1.EnvVarDownloaderName), "ocular-defaults-")
+	if downloaderName == "" {
+		return fmt.Errorf("%s environment variable not set", v1beta1.EnvVarDownloaderName)
+	}
+
+	if downloaderOverride := os.Getenv("OCULAR_DOWNLOADER_NAME_OVERRIDE"); downloaderOverride != "" {
+		downloaderName = downloaderOverride
+	}
+
+	l := logger.WithValues(
+		"target_dir", targetDir,
+		"downloader", downloaderName,
+		"target_identifier", targetIdentifier,
+		"target_version", targetVersion,
+	)
+
+	logger.Info("starting downloader")
+
+	logger.Info("creating target directory")
+	if err := os.MkdirAll(targetDir, 0o750); err != nil {
+		logger.Error(err, "error creating target directory")
+	}
+
+	downloader, found := registry[downloaderName]
+	if !found {
+		return fmt.Errorf("unknown downloader %s", downloaderName)
+	}
+
+	params, err := input.ParseParamsFromEnv(downloader.Parameters)
+	if err != nil {
+		logger.Error(err, "unable to parse parameters from environment")
+	}
+
+	l.Info("downloading target")
+
+	if err = downloader.Download(ctx, params, targetIdentifier, targetVersion, targetDir); err != nil {
+		return fmt.Errorf("error downloading target: %w", err)
+	}
+
+	l.Info("downloaded target successfully")
+	return nil
+}
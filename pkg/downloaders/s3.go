@@ -18,7 +18,7 @@ import (
 	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/config"
 	s3Service "github.com/aws/aws-sdk-go-v2/service/s3"
-	"github.com/crashappsec/ocular-default-integrations/internal/definitions"
+	"github.com/crashappsec/ocular-default-integrations/pkg/definitions"
 	"github.com/hashicorp/go-multierror"
 	corev1 "k8s.io/api/core/v1"
 	"sigs.k8s.io/controller-runtime/pkg/log"
@@ -29,7 +29,7 @@ const (
 )
 
 func init() {
-	All.registerDownloader(S3)
+	All.Register(S3)
 }
 
 var S3 = Downloader{
@@ -49,8 +49,9 @@ var S3 = Downloader{
 	Download: downloadS3,
 }
 
-func downloadS3(ctx context.Context, _ map[string]string, bucketName, version, targetDir string) error {
+func downloadS3(ctx context.Context, _ map[string]string, identifier, version, targetDir string) error {
 	l := log.FromContext(ctx)
+	bucketName, prefix := splitBucketIdentifier(identifier)
 	cfg, err := config.LoadDefaultConfig(ctx)
 	if err != nil {
 		return fmt.Errorf("unable to load SDK config: %w", err)
@@ -60,9 +61,10 @@ func downloadS3(ctx context.Context, _ map[string]string, bucketName, version, t
 
 	paginator := s3Service.NewListObjectsV2Paginator(client, &s3Service.ListObjectsV2Input{
 		Bucket: aws.String(bucketName),
+		Prefix: aws.String(prefix),
 	})
 
-	var merr *multierror.Error
+	var keys []string
 	for paginator.HasMorePages() {
 		page, err := paginator.NextPage(ctx)
 		if err != nil {
@@ -70,16 +72,21 @@ func downloadS3(ctx context.Context, _ map[string]string, bucketName, version, t
 		}
 
 		for _, obj := range page.Contents {
-			err := downloadS3Object(ctx, client, bucketName, *obj.Key, version, targetDir)
-			if err != nil {
-				l.Error(err, "failed to download object",
-					"bucket", bucketName,
-					"key", *obj.Key)
-				merr = multierror.Append(merr, err)
-			}
+			keys = append(keys, *obj.Key)
+		}
+	}
+
+	var merr *multierror.Error
+	for _, key := range selectBucketObjects(prefix, keys) {
+		err := downloadS3Object(ctx, client, bucketName, key, version, targetDir)
+		if err != nil {
+			l.Error(err, "failed to download object",
+				"bucket", bucketName,
+				"key", key)
+			merr = multierror.Append(merr, err)
 		}
 	}
-	return nil
+	return merr.ErrorOrNil()
 }
 
 func downloadS3Object(
@@ -13,12 +13,15 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"math"
 	"net/http"
 	"os"
 	"path/filepath"
+	"slices"
+	"strings"
 
 	"sigs.k8s.io/controller-runtime/pkg/log"
 )
@@ -129,3 +132,37 @@ func writeJSONStruct[T any](path string, jsonStruct T) error {
 	}
 	return nil
 }
+
+// splitBucketIdentifier splits a target identifier of the form 'bucket[/prefix]'.
+// Bucket names cannot contain '/', so everything after the first '/' is the prefix.
+func splitBucketIdentifier(identifier string) (bucket, prefix string) {
+	bucket, prefix, _ = strings.Cut(identifier, "/")
+	return bucket, prefix
+}
+
+// selectBucketObjects returns the keys of the objects listed for the prefix of a bucket
+// identifier that are downloaded. If the prefix does not end with '/' and is the key of
+// an object, only that object is downloaded, so that a target for a single object does
+// not also download the objects whose keys start with its name (e.g. 'a.txt' for 'a').
+func selectBucketObjects(prefix string, keys []string) []string {
+	if prefix != "" && !strings.HasSuffix(prefix, "/") && slices.Contains(keys, prefix) {
+		return []string{prefix}
+	}
+	return keys
+}
+
+// readSecretFile returns the contents of a mounted secret, and whether it is set.
+func readSecretFile(name string) ([]byte, bool, error) {
+	f, err := os.Stat(name)
+	if errors.Is(err, os.ErrNotExist) || (err == nil && f.IsDir()) {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, err
+	}
+	content, err := os.ReadFile(filepath.Clean(name))
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to read %s: %w", name, err)
+	}
+	return content, len(bytes.TrimSpace(content)) > 0, nil
+}
@@ -0,0 +1,64 @@
+// Copyright (C) 2025-2026 Crash Override, Inc.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the FSF, either version 3 of the License, or (at your option) any later version.
+// See the LICENSE file in the root of this repository for full license text or
+// visit: <https://www.gnu.org/licenses/gpl-3.0.html>.
+
+package downloaders
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestSelectBucketObjects(t *testing.T) {
+	tests := []struct {
+		name       string
+		identifier string
+		// keys are the keys listed for the prefix of the identifier
+		keys []string
+		want []string
+	}{
+		{
+			name:       "whole bucket",
+			identifier: "bucket",
+			keys:       []string{"a", "a.txt", "a/b"},
+			want:       []string{"a", "a.txt", "a/b"},
+		},
+		{
+			name:       "object target with overlapping names",
+			identifier: "bucket/a",
+			keys:       []string{"a", "a.txt", "a/b", "abc/d"},
+			want:       []string{"a"},
+		},
+		{
+			name:       "prefix target",
+			identifier: "bucket/a/",
+			keys:       []string{"a/", "a/b", "a/c/d"},
+			want:       []string{"a/", "a/b", "a/c/d"},
+		},
+		{
+			name:       "prefix without object",
+			identifier: "bucket/logs",
+			keys:       []string{"logs/1", "logs-old/1"},
+			want:       []string{"logs/1", "logs-old/1"},
+		},
+		{
+			name:       "nested object target",
+			identifier: "bucket/logs/1",
+			keys:       []string{"logs/1", "logs/10"},
+			want:       []string{"logs/1"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, prefix := splitBucketIdentifier(tt.identifier)
+			if got := selectBucketObjects(prefix, tt.keys); !slices.Equal(got, tt.want) {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -9,10 +9,15 @@
 package input
 
 import (
+	"encoding/json"
 	"fmt"
+	"io"
 	"os"
+	"path/filepath"
+	"strings"
 
 	"github.com/crashappsec/ocular/api/v1beta1"
+	"github.com/hashicorp/go-multierror"
 )
 
 type PipelineMetadata struct {
@@ -20,6 +25,10 @@ type PipelineMetadata struct {
 	TargetIdentifier string `json:"targetIdentifier"`
 	TargetVersion    string `json:"targetVersion,omitempty"`
 	DownloaderName   string `json:"downloaderName"`
+	// Metadata are the JSON metadata files written by the downloader, by file name
+	// without extension, e.g. '{{ .Metadata.git.subpath }}' for the git downloader.
+	// Names that are not identifiers are read with index, e.g. '{{ index .Metadata "docker-sboms" }}'.
+	Metadata map[string]any `json:"metadata,omitempty"`
 }
 
 func ParseMetadataFromEnv() (PipelineMetadata, error) {
@@ -52,3 +61,50 @@ func ParseMetadataFromEnv() (PipelineMetadata, error) {
 
 	return metadata, nil
 }
+
+// MaxMetadataFileSize is the maximum size of a metadata file read by [ReadDownloaderMetadata].
+const MaxMetadataFileSize = 1 << 20
+
+// ReadDownloaderMetadata reads the JSON metadata files in the metadata directory
+// of the pipeline, by file name without extension. Files that cannot be read or
+// parsed, or are larger than [MaxMetadataFileSize], are skipped, and reported in
+// the returned error.
+func ReadDownloaderMetadata(dir string) (map[string]any, error) {
+	files, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	if err != nil {
+		return nil, err
+	}
+	var merr *multierror.Error
+	metadata := make(map[string]any, len(files))
+	for _, file := range files {
+		content, err := readMetadataFile(file)
+		if err != nil {
+			merr = multierror.Append(merr, err)
+			continue
+		}
+		var value any
+		if err = json.Unmarshal(content, &value); err != nil {
+			merr = multierror.Append(merr, fmt.Errorf("invalid metadata file %s: %w", file, err))
+			continue
+		}
+		metadata[strings.TrimSuffix(filepath.Base(file), ".json")] = value
+	}
+	return metadata, merr.ErrorOrNil()
+}
+
+// readMetadataFile reads the file, returning an error if it is larger than [MaxMetadataFileSize].
+func readMetadataFile(file string) ([]byte, error) {
+	f, err := os.Open(filepath.Clean(file))
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = f.Close() }()
+	content, err := io.ReadAll(io.LimitReader(f, MaxMetadataFileSize+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(content) > MaxMetadataFileSize {
+		return nil, fmt.Errorf("metadata file %s is larger than %d bytes", file, MaxMetadataFileSize)
+	}
+	return content, nil
+}
@@ -11,6 +11,8 @@ package input
 import (
 	"fmt"
 	"os"
+	"strconv"
+	"strings"
 
 	"github.com/crashappsec/ocular/api/v1beta1"
 	ocularRuntime "github.com/crashappsec/ocular/pkg/runtime"
@@ -56,3 +58,18 @@ func CombineParameterDefinitions(
 	}
 	return combined
 }
+
+// BoolParam parses the boolean parameter name as accepted by [strconv.ParseBool].
+// If the parameter is empty def is returned, and other values are an error, so
+// that a misspelled value does not silently change what is crawled or downloaded.
+func BoolParam(params map[string]string, name string, def bool) (bool, error) {
+	value := strings.TrimSpace(params[name])
+	if value == "" {
+		return def, nil
+	}
+	parsed, err := strconv.ParseBool(value)
+	if err != nil {
+		return def, fmt.Errorf("invalid value for %s: %q is not a boolean", name, value)
+	}
+	return parsed, nil
+}
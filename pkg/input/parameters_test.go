@@ -0,0 +1,43 @@
+// Copyright (C) 2025-2026 Crash Override, Inc.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the FSF, either version 3 of the License, or (at your option) any later version.
+// See the LICENSE file in the root of this repository for full license text or
+// visit: <https://www.gnu.org/licenses/gpl-3.0.html>.
+
+package input
+
+import "testing"
+
+func TestBoolParam(t *testing.T) {
+	tests := []struct {
+		name    string
+		value   string
+		def     bool
+		want    bool
+		wantErr bool
+	}{
+		{name: "empty uses default", value: "", def: true, want: true},
+		{name: "whitespace uses default", value: "  ", def: false, want: false},
+		{name: "true", value: "true", want: true},
+		{name: "false", value: "false", def: true, want: false},
+		{name: "upper case", value: "TRUE", want: true},
+		{name: "number", value: "1", want: true},
+		{name: "surrounding whitespace", value: " false\n", def: true, want: false},
+		{name: "yes is rejected", value: "yes", def: true, want: true, wantErr: true},
+		{name: "misspelling is rejected", value: "ture", want: false, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := BoolParam(map[string]string{"PARAM": tt.value}, "PARAM", tt.def)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("got error %v, want error %t", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("got %t, want %t", got, tt.want)
+			}
+		})
+	}
+}
@@ -27,23 +27,55 @@ func NewUserTemplater(name string) *UserTemplater {
 		"trimsuffix": strings.TrimSuffix,
 		"tolower":    strings.ToLower,
 		"toupper":    strings.ToUpper,
+		"default":    defaultValue,
 	})
 	return &UserTemplater{
 		template: t,
 	}
 }
 
-func (ut *UserTemplater) Execute(userTemplate string, data any) (string, error) {
-	var buf strings.Builder
+// WithErrorOnMissingKey makes executing a template fail if it refers to a key missing
+// from a map, rather than rendering '<no value>', for data such as decoded JSON objects.
+// Optional keys can be given a value with 'index' and 'default', e.g. '{{ index . "key" | default "" }}'.
+func (ut *UserTemplater) WithErrorOnMissingKey() *UserTemplater {
+	ut.template.Option("missingkey=error")
+	return ut
+}
+
+// Parse parses the user template, which can then be executed any number of times.
+func (ut *UserTemplater) Parse(userTemplate string) (*UserTemplate, error) {
 	tmpl, err := ut.template.Parse(userTemplate)
 	if err != nil {
-		return "", err
+		return nil, err
 	}
+	return &UserTemplate{template: tmpl}, nil
+}
 
-	err = tmpl.Execute(&buf, data)
+func (ut *UserTemplater) Execute(userTemplate string, data any) (string, error) {
+	tmpl, err := ut.Parse(userTemplate)
 	if err != nil {
 		return "", err
 	}
+	return tmpl.Execute(data)
+}
 
+// UserTemplate is a user-defined template parsed by a [UserTemplater].
+type UserTemplate struct {
+	template *template.Template
+}
+
+func (t *UserTemplate) Execute(data any) (string, error) {
+	var buf strings.Builder
+	if err := t.template.Execute(&buf, data); err != nil {
+		return "", err
+	}
 	return buf.String(), nil
 }
+
+// defaultValue returns def if value is nil or an empty string, otherwise value.
+func defaultValue(def, value any) any {
+	if value == nil || value == "" {
+		return def
+	}
+	return value
+}
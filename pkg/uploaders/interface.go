@@ -10,26 +10,41 @@ package uploaders
 
 import (
 	"context"
+	"fmt"
+	"slices"
 
-	"github.com/crashappsec/ocular-default-integrations/internal/definitions"
+	"github.com/crashappsec/ocular-default-integrations/pkg/definitions"
 	"github.com/crashappsec/ocular-default-integrations/pkg/input"
 	"github.com/crashappsec/ocular/api/v1beta1"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
-var All = make(all)
+// All is the registry of the default uploaders, populated when the package is initialized.
+// Other modules can register their own uploaders to it and pass it to [Run],
+// to build a single image containing both the defaults and their integrations.
+var All = make(Registry)
 
-type all map[string]Uploader
+// Registry holds uploaders by name.
+type Registry map[string]Uploader
 
-func (a all) registerUploader(u Uploader) {
-	if a == nil {
+// Register adds the uploader to the registry. It panics if the uploader has no name
+// or Upload function, or if a uploader with the same name is already registered.
+// To replace a registered uploader, delete it from the registry first.
+func (r Registry) Register(u Uploader) {
+	if r == nil {
 		return
 	}
+	if u.Name == "" {
+		panic("uploader name must be set")
+	}
 	if u.Upload == nil {
 		panic("upload function must be set")
 	}
-	a[u.Name] = u
+	if _, exists := r[u.Name]; exists {
+		panic(fmt.Sprintf("uploader %q is already registered", u.Name))
+	}
+	r[u.Name] = u
 }
 
 type Uploader struct {
@@ -46,9 +61,16 @@ type Uploader struct {
 	EnvironmentVariables []corev1.EnvVar
 }
 
+// GenerateObjects returns the ClusterUploader definitions of the default uploaders.
 func GenerateObjects(image, secretName string) []*v1beta1.ClusterUploader {
-	uploaderObjs := make([]*v1beta1.ClusterUploader, 0, len(All))
-	for _, u := range All {
+	return All.GenerateObjects(image, secretName)
+}
+
+// GenerateObjects returns a ClusterUploader definition for each uploader in the registry,
+// running the given image and reading secrets from the secret secretName.
+func (r Registry) GenerateObjects(image, secretName string) []*v1beta1.ClusterUploader {
+	uploaderObjs := make([]*v1beta1.ClusterUploader, 0, len(r))
+	for _, u := range r {
 		params := u.Parameters
 		uploaderObj := &v1beta1.ClusterUploader{
 			TypeMeta: metav1.TypeMeta{
@@ -62,14 +84,15 @@ func GenerateObjects(image, secretName string) []*v1beta1.ClusterUploader {
 				Container: corev1.Container{
 					Name:  u.Name,
 					Image: image,
-					Env:   u.EnvironmentVariables,
+					Env:   slices.Clone(u.EnvironmentVariables),
 				},
 				Parameters: params,
 			},
 		}
 
 		if u.EnvironmentSecrets != nil {
-			uploaderObj.Spec.Container.Env = definitions.EnvironmentSecretsToEnvVars(secretName, u.EnvironmentSecrets)
+			uploaderObj.Spec.Container.Env = append(uploaderObj.Spec.Container.Env,
+				definitions.EnvironmentSecretsToEnvVars(secretName, u.EnvironmentSecrets)...)
 		}
 
 		if u.FileSecrets != nil {
@@ -0,0 +1,99 @@
+// Copyright (C) 2025-2026 Crash Override, Inc.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the FSF, either version 3 of the License, or (at your option) any later version.
+// See the LICENSE file in the root of this repository for full license text or
+// visit: <https://www.gnu.org/licenses/gpl-3.0.html>.
+
+package uploaders
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/crashappsec/ocular-default-integrations/pkg/input"
+	"github.com/crashappsec/ocular/api/v1beta1"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// Run runs the uploader of the registry selected by the environment of the
+// pipeline container, uploading the given files. Files that do not exist are skipped.
+func Run(ctx context.Context, registry Registry, files []string) error {
+	logger := log.FromContext(ctx)
+
+	logger.Info("starting uploader")
+
+	resultsDir := os.Getenv(v1beta1.EnvVarResultsDir)
+
+	uploaderName := strings.TrimPrefix(os.Getenv(v1beta1.EnvVarUploaderName), "ocular-defaults-")
+	if uploaderName == "" {
+		return fmt.Errorf("%s environment variable not set", v1beta1.EnvVarUploaderName)
+	}
+
+	if uploaderOverride := os.Getenv("OCULAR_UPLOADER_NAME_OVERRIDE"); uploaderOverride != "" {
+		uploaderName = uploaderOverride
+	}
+
+	l := logger.WithValues(
+		"results_dir", resultsDir,
+		"uploader", uploaderName,
+		"file-args", files,
+	)
+
+	metadata, err := input.ParseMetadataFromEnv()
+	if err != nil {
+		return fmt.Errorf("failed to parse metadata from environment: %w", err)
+	}
+	if metadataDir := os.Getenv(v1beta1.EnvVarMetadataDir); metadataDir != "" {
+		metadata.Metadata, err = input.ReadDownloaderMetadata(metadataDir)
+		if err != nil {
+			l.Error(err, "unable to read downloader metadata", "dir", metadataDir)
+		}
+	}
+
+	uploader, found := registry[uploaderName]
+	if !found {
+		return fmt.Errorf("unknown uploader %s", uploaderName)
+	}
+
+	l.WithValues("uploader", uploaderName).Info("begin upload process")
+
+	params, err := input.ParseParamsFromEnv(uploader.Parameters)
+	if err != nil {
+		logger.Error(err, "unable to parse parameters from environment")
+	}
+
+	var validatedFiles []string
+	for _, file := range files {
+		l.Info("validating file exists", "file", file)
+		if _, err := os.Stat(file); os.IsNotExist(err) {
+			l.Info("file does not exist, skipping", "file", file)
+		} else if err != nil {
+			l.Error(err, "unable to stat file, skipping", "file", file)
+		} else {
+			validatedFiles = append(validatedFiles, file)
+		}
+	}
+
+	l.Info("uploading files", "files", validatedFiles)
+	if err = uploader.Upload(ctx, metadata, params, validatedFiles); err != nil {
+		return fmt.Errorf("failed to upload files %v: %w", validatedFiles, err)
+	}
+
+	l.Info("uploaded artifacts successfully")
+	return nil
+}
+
+// FilesFromArgs returns the files to upload, which are
+// passed as positional arguments after '--'.
+func FilesFromArgs(args []string) []string {
+	for i, arg := range args {
+		if arg == "--" {
+			return args[i+1:]
+		}
+	}
+	return nil
+}
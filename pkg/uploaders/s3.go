@@ -29,7 +29,7 @@ const (
 )
 
 func init() {
-	All.registerUploader(S3)
+	All.Register(S3)
 }
 
 var S3 = Uploader{
@@ -43,6 +43,9 @@ var S3 = Uploader{
 			Name: S3FolderTemplateParamName,
 			Description: "Template for the folder structure in the S3 bucket. " +
 				"Supports placeholders like .PipelineName, .TargetID, .TargetVersion . " +
+				"The metadata files of the downloader are available by file name, " +
+				"e.g. '.Metadata.git.subpath' for the subdirectory of a git target, or with index for " +
+				"names containing a hyphen, e.g. 'index .Metadata \"docker-sboms\"'. Files larger than 1 MiB are skipped. " +
 				"Using '/' in the template will create nested folders. " +
 				"Defaults to '.PipelineName' .",
 			Default: ptr.To(""), // default handled in code, templating gets messed up with helm rendering
@@ -24,7 +24,7 @@ import (
 )
 
 func init() {
-	All.registerUploader(Webhooks)
+	All.Register(Webhooks)
 }
 
 var Webhooks = Uploader{